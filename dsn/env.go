@@ -60,6 +60,7 @@ func FromEnv(prefix string, input interface{}) error {
 
 		field, ok := ttf[key]
 		if !ok {
+			setPasswordRef(input, key, value)
 			continue
 		}
 
@@ -12,5 +12,5 @@ func ExampleDoMain() {
 	}
 	// Output:
 	//
-	// b=true database="" host="host" i=5 password="pass" port="1234" s="extra string" username="user"
+	// b=true database="" host="host" i=5 password="xxxxx" port="1234" s="extra string" username="user"
 }
@@ -12,5 +12,5 @@ func ExampleDoMain() {
 	}
 	// Output:
 	//
-	// b=false database="dbname" host="host" i=5 password="pass" port="2222" s="a string" username="user"
+	// b=false database="dbname" host="host" i=5 password="xxxxx" port="2222" s="a string" username="user"
 }
@@ -5,9 +5,12 @@
 package dsn
 
 import (
+	"encoding"
 	"flag"
 	"fmt"
 	"reflect"
+	"strings"
+	"time"
 )
 
 // FlagSet creates a flag.FlagSet to be used with the stdlib flag
@@ -18,6 +21,14 @@ import (
 // If the member has a doc metadata tag its value will be used as the
 // usage argument for the flag.
 //
+// Flags of time.Duration members also accept integers as seconds,
+// flags of string slices accept comma-separated values as in DSNs.
+// The first occurrence replaces the default, further occurrences
+// append to the slice.
+//
+// If info embeds Info a flag "password-<name>" is created for each
+// registered SecretResolver.
+//
 // The resulting FlagSet can be used with e.g. github.com/spf13/pflag to
 // merge multiple FlagSets.
 func FlagSet(name string, errorHandling flag.ErrorHandling, info interface{}) (*flag.FlagSet, error) {
@@ -32,20 +43,139 @@ func FlagSet(name string, errorHandling flag.ErrorHandling, info interface{}) (*
 			usage = docField.String()
 		}
 
-		switch field.Kind() {
-		case reflect.String:
-			flagset.StringVar(field.Addr().Interface().(*string),
-				key, field.String(), usage)
-		case reflect.Bool:
-			flagset.BoolVar(field.Addr().Interface().(*bool),
-				key, field.Interface().(bool), usage)
-		case reflect.Int:
-			flagset.IntVar(field.Addr().Interface().(*int),
-				key, field.Interface().(int), usage)
+		if isTextValue(field) {
+			flagset.TextVar(field.Addr().Interface().(encoding.TextUnmarshaler),
+				key, field.Addr().Interface().(encoding.TextMarshaler), usage)
+			continue
+		}
+
+		switch ptr := field.Addr().Interface().(type) {
+		case *string:
+			flagset.StringVar(ptr, key, *ptr, usage)
+		case *bool:
+			flagset.BoolVar(ptr, key, *ptr, usage)
+		case *int:
+			flagset.IntVar(ptr, key, *ptr, usage)
+		case *time.Duration:
+			flagset.Var(&durationFlag{ptr}, key, usage)
+		case *uint:
+			flagset.UintVar(ptr, key, *ptr, usage)
+		case *uint64:
+			flagset.Uint64Var(ptr, key, *ptr, usage)
+		case *float64:
+			flagset.Float64Var(ptr, key, *ptr, usage)
+		case *[]string:
+			flagset.Var(&stringSliceFlag{slice: ptr}, key, usage)
 		default:
-			return nil, fmt.Errorf("dsn: unhandled reflect kind %q", field.Kind())
+			switch field.Kind() {
+			case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+				reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Float32:
+				flagset.Var(&fieldFlag{field}, key, usage)
+			default:
+				return nil, fmt.Errorf("dsn: unhandled reflect kind %q", field.Kind())
+			}
+		}
+	}
+
+	// Flags referencing the password are only created for targets
+	// embedding Info, as only Info stores the reference.
+	if info, ok := embeddedInfo(reflect.ValueOf(info)); ok && info.CanSet() {
+		for _, name := range secretResolverNames() {
+			key := passwordKeyPrefix + name
+			if _, ok := ttf[key]; ok {
+				continue
+			}
+
+			flagset.Var(&passwordRefFlag{info: info, source: name}, key,
+				fmt.Sprintf("Password resolved through the %q secret resolver", name))
 		}
 	}
 
 	return flagset, nil
 }
+
+// passwordRefFlag implements the flag.Value interface for references
+// to the password of an Info.
+type passwordRefFlag struct {
+	info   reflect.Value
+	source string
+}
+
+func (f *passwordRefFlag) String() string {
+	if !f.info.IsValid() || f.info.FieldByName("PasswordSource").String() != f.source {
+		return ""
+	}
+	return f.info.FieldByName("PasswordRef").String()
+}
+
+func (f *passwordRefFlag) Set(value string) error {
+	f.info.FieldByName("PasswordSource").SetString(f.source)
+	f.info.FieldByName("PasswordRef").SetString(value)
+	return nil
+}
+
+// durationFlag implements the flag.Value interface for time.Duration
+// members, which are parsed like in DSNs.
+type durationFlag struct {
+	d *time.Duration
+}
+
+func (f *durationFlag) String() string {
+	if f.d == nil {
+		return ""
+	}
+	return f.d.String()
+}
+
+func (f *durationFlag) Set(value string) error {
+	return setValue(reflect.ValueOf(f.d).Elem(), value)
+}
+
+// stringSliceFlag implements the flag.Value interface for string
+// slices. As in DSNs the values are separated by commas and replace the
+// default, values of repeated flags are appended.
+type stringSliceFlag struct {
+	slice *[]string
+	set   bool
+}
+
+func (f *stringSliceFlag) String() string {
+	if f.slice == nil {
+		return ""
+	}
+	return strings.Join(*f.slice, ",")
+}
+
+func (f *stringSliceFlag) Set(value string) error {
+	values := []string{}
+	if value != "" {
+		values = strings.Split(value, ",")
+	}
+
+	if !f.set {
+		*f.slice = values
+		f.set = true
+		return nil
+	}
+
+	*f.slice = append(*f.slice, values...)
+	return nil
+}
+
+// fieldFlag implements the flag.Value interface for members without
+// a matching flag type in the flag package.
+type fieldFlag struct {
+	field reflect.Value
+}
+
+func (f *fieldFlag) String() string {
+	if !f.field.IsValid() {
+		return ""
+	}
+	s, _ := formatValue(f.field)
+	return s
+}
+
+func (f *fieldFlag) Set(value string) error {
+	return setValue(f.field, value)
+}
@@ -9,15 +9,57 @@ import (
 	"net/url"
 	"reflect"
 	"sort"
-	"strconv"
 	"strings"
 )
 
+// FormatOption changes the output of FormatSimple and FormatURI.
+type FormatOption string
+
+const (
+	// FormatSecrets formats the values of members with the metadata tag
+	// secret:"true" instead of masking them.
+	FormatSecrets FormatOption = "secrets"
+)
+
+// SecretMask replaces the values of members with the metadata tag
+// secret:"true" in formatted DSNs.
+const SecretMask = "xxxxx"
+
+// formatSecret returns SecretMask if the member key is tagged as secret
+// and its value v must be masked.
+func formatSecret(secrets map[string]reflect.Value, key, v string, opts []FormatOption) string {
+	if secrets[key].String() != "true" {
+		return v
+	}
+
+	return maskSecret(v, opts)
+}
+
+// maskSecret returns SecretMask for a non-empty secret v unless
+// FormatSecrets is passed.
+func maskSecret(v string, opts []FormatOption) string {
+	if v == "" {
+		return v
+	}
+
+	for _, opt := range opts {
+		if opt == FormatSecrets {
+			return v
+		}
+	}
+
+	return SecretMask
+}
+
 // FormatSimple formats the passed value as a simple DSN.
 //
 // Only members with a json metadata tag are added with the json
 // metadata value used as the key.
 //
+// Values of members with the metadata tag secret:"true" and references
+// to the password of Info are replaced with SecretMask unless
+// FormatSecrets is passed.
+//
 // Example:
 //   type Example struct {
 //       StringA string `json:"a"`
@@ -32,19 +74,30 @@ import (
 //
 // Will print:
 //   a="a string" b=5
-func FormatSimple(input interface{}) string {
+func FormatSimple(input interface{}, opts ...FormatOption) string {
 	ret := []string{}
+	secrets := TagToField(input, Secret)
 
 	for key, field := range TagToField(input, OnlyJSON) {
-		var v interface{}
+		v, err := formatValue(field)
+		if err != nil {
+			v = fmt.Sprintf("%v", field)
+		}
+		v = formatSecret(secrets, key, v, opts)
+
 		switch field.Kind() {
-		case reflect.String:
-			v = fmt.Sprintf("%q", field.String())
+		case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
 		default:
-			v = field
+			v = fmt.Sprintf("%q", v)
 		}
 
-		ret = append(ret, fmt.Sprintf("%s=%v", key, v))
+		ret = append(ret, fmt.Sprintf("%s=%s", key, v))
+	}
+
+	if key, ref, ok := passwordRef(input); ok {
+		ret = append(ret, fmt.Sprintf("%s=%q", key, maskSecret(ref, opts)))
 	}
 
 	// Sort for deterministic output
@@ -71,6 +124,10 @@ func FormatSimple(input interface{}) string {
 // connection property with their default as value.
 // To avoid possible issues, use string-members with an empty string.
 //
+// Values of members with the metadata tag secret:"true" and references
+// to the password of Info are replaced with SecretMask unless
+// FormatSecrets is passed.
+//
 // Example:
 //   type Example struct {
 //       Scheme       string `json:"scheme"`
@@ -104,7 +161,7 @@ func FormatSimple(input interface{}) string {
 //   db://username:password@hostname:12345/?database=db1&connectProp1=false&connectProp2=connectionProperty
 //
 // For more examples see format_test.go
-func FormatURI(input interface{}) (string, error) {
+func FormatURI(input interface{}, opts ...FormatOption) (string, error) {
 	// Initialize URL-struct to store connection-values
 	urlValues := new(url.URL)
 	// Initialize user, password, host, and port variables since they
@@ -113,23 +170,18 @@ func FormatURI(input interface{}) (string, error) {
 	var user, passwd, host, port string
 	// Initialize url.Values to store additional connection-properties
 	connectProp := url.Values{}
+	secrets := TagToField(input, Secret)
 
 	// Loop over passed input and store values to respective connection
 	// value
 	for key, field := range TagToField(input, OnlyJSON) {
 
 		// Store all values as string
-		var v string
-		switch field.Kind() {
-		case reflect.String:
-			v = field.String()
-		case reflect.Int:
-			v = strconv.Itoa(int(field.Int()))
-		case reflect.Bool:
-			v = strconv.FormatBool(field.Bool())
-		default:
-			return "", fmt.Errorf("dsn: failed to transform the value of <%s=%v> to string", key, field.String())
+		v, err := formatValue(field)
+		if err != nil {
+			return "", fmt.Errorf("dsn: failed to transform the value of <%s=%v> to string: %w", key, field, err)
 		}
+		v = formatSecret(secrets, key, v, opts)
 
 		// continue if value is not set
 		// e.g. if the passed input contains a member/key without value
@@ -162,6 +214,10 @@ func FormatURI(input interface{}) (string, error) {
 
 	}
 
+	if key, ref, ok := passwordRef(input); ok {
+		connectProp.Add(key, maskSecret(ref, opts))
+	}
+
 	// If a userstorekey was given, encode the connection properties
 	// (that contain the key) and return the resulting string
 	if strings.Contains(connectProp.Encode(), "KEY") {
@@ -172,6 +228,11 @@ func FormatURI(input interface{}) (string, error) {
 	// set after the passed input was processed.
 	urlValues.User = url.UserPassword(user, passwd)
 	urlValues.Host = fmt.Sprintf("%s:%s", host, port)
+	if info, ok := embeddedInfo(reflect.ValueOf(input)); ok {
+		if hosts := info.FieldByName("Hosts").Interface().([]string); len(hosts) > 1 {
+			urlValues.Host = strings.Join(hosts, ",")
+		}
+	}
 
 	// Return URI-string by using url.URL.String() and encoding the
 	// connection properties.
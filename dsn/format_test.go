@@ -22,11 +22,12 @@ func TestFormatURI(t *testing.T) {
 		ConnectProp2 string `json:"connectProp2"`
 	}
 	type infoError struct {
-		Scheme byte `json:"scheme"`
+		Scheme complex64 `json:"scheme"`
 	}
 
 	cases := map[string]struct {
 		info         interface{}
+		opts         []FormatOption
 		expectString string
 		expectErr    error
 	}{
@@ -38,6 +39,18 @@ func TestFormatURI(t *testing.T) {
 				Port:     "12345",
 				Database: "db",
 			},
+			expectString: "//user:xxxxx@host:12345/?database=db",
+			expectErr:    nil,
+		},
+		"infoSimpleSecrets": {
+			info: &Info{
+				Username: "user",
+				Password: "pass",
+				Host:     "host",
+				Port:     "12345",
+				Database: "db",
+			},
+			opts:         []FormatOption{FormatSecrets},
 			expectString: "//user:pass@host:12345/?database=db",
 			expectErr:    nil,
 		},
@@ -99,16 +112,16 @@ func TestFormatURI(t *testing.T) {
 		},
 		"infoError": {
 			info: &infoError{
-				Scheme: uint8(1),
+				Scheme: complex(1, 0),
 			},
 			expectString: "",
-			expectErr:    errors.New("FormatURI: failed to transform the value of <scheme=<uint8 Value>> to string"),
+			expectErr:    errors.New("FormatURI: failed to transform the value of <scheme=(1+0i)> to string"),
 		},
 	}
 
 	for title, cas := range cases {
 		t.Run(title, func(t *testing.T) {
-			connStr, err := FormatURI(cas.info)
+			connStr, err := FormatURI(cas.info, cas.opts...)
 			if err != nil {
 				if cas.expectErr == nil {
 					t.Errorf("Expected no error, but got error: %s", err)
@@ -0,0 +1,69 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package dsn
+
+import (
+	"reflect"
+	"strings"
+)
+
+// splitHosts returns dsn with only the first of multiple comma-separated
+// hosts, which url.Parse does not support, and the addresses of all
+// hosts.
+//
+// If dsn contains a single host it is returned unchanged and the
+// returned hosts are nil.
+func splitHosts(dsn string) (string, []string) {
+	start := strings.Index(dsn, "://")
+	if start < 0 {
+		return dsn, nil
+	}
+	start += len("://")
+
+	end := len(dsn)
+	if i := strings.IndexAny(dsn[start:], "/?#"); i >= 0 {
+		end = start + i
+	}
+
+	// Skip the user info, whose password may contain commas.
+	if i := strings.LastIndex(dsn[start:end], "@"); i >= 0 {
+		start += i + 1
+	}
+
+	if !strings.Contains(dsn[start:end], ",") {
+		return dsn, nil
+	}
+
+	hosts := strings.Split(dsn[start:end], ",")
+	return dsn[:start] + hosts[0] + dsn[end:], hosts
+}
+
+// embeddedInfo returns the Info embedded in input, or input itself if
+// it is an Info.
+func embeddedInfo(input reflect.Value) (reflect.Value, bool) {
+	if input.Kind() == reflect.Ptr || input.Kind() == reflect.Interface {
+		input = input.Elem()
+	}
+
+	if input.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+
+	if input.Type() == reflect.TypeOf(Info{}) {
+		return input, true
+	}
+
+	for i := 0; i < input.NumField(); i++ {
+		if !input.Type().Field(i).Anonymous {
+			continue
+		}
+
+		if info, ok := embeddedInfo(input.Field(i)); ok {
+			return info, true
+		}
+	}
+
+	return reflect.Value{}, false
+}
@@ -7,9 +7,22 @@ package dsn
 // Info serves as both an example and an embeddable default to use in
 // DSN structs.
 type Info struct {
-	Host     string `json:"host" multiref:"hostname" doc:"Hostname to connect to"`
+	Host     string `json:"host" multiref:"hostname" validate:"required" doc:"Hostname to connect to"`
 	Port     string `json:"port" doc:"Port (Example: '443' or 'tls') to connect to"`
 	Username string `json:"username" multiref:"user" doc:"Username"`
-	Password string `json:"password" multiref:"passwd,pass" doc:"Password"`
+	Password string `json:"password" multiref:"passwd,pass" secret:"true" doc:"Password"`
 	Database string `json:"database" multiref:"db" doc:"Database"`
+
+	// Hosts are the addresses of all servers in the form host[:port]
+	// if the DSN lists multiple servers, in which case Host and Port
+	// are set to the first server.
+	Hosts []string
+
+	// PasswordSource and PasswordRef are set if the password is
+	// referenced as "password-<source>=<ref>", e.g.
+	// "password-file=/run/secrets/ase". The reference is resolved by
+	// ResolvePassword through the SecretResolver registered as
+	// PasswordSource.
+	PasswordSource string
+	PasswordRef    string
 }
@@ -0,0 +1,226 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package dsn
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// SecretResolver resolves references to secrets, e.g. the path of
+// a file containing a password.
+type SecretResolver interface {
+	ResolveSecret(ctx context.Context, ref string) (string, error)
+}
+
+// SecretResolverFunc is a function implementing SecretResolver.
+type SecretResolverFunc func(ctx context.Context, ref string) (string, error)
+
+func (fn SecretResolverFunc) ResolveSecret(ctx context.Context, ref string) (string, error) {
+	return fn(ctx, ref)
+}
+
+// passwordKeyPrefix is the prefix of keys referencing the password,
+// followed by the name of a SecretResolver.
+const passwordKeyPrefix = "password-"
+
+var (
+	secretResolvers = map[string]SecretResolver{
+		"file": SecretResolverFunc(resolveFile),
+		"env":  SecretResolverFunc(resolveEnv),
+	}
+	secretResolversLock = &sync.RWMutex{}
+)
+
+// RegisterSecretResolver registers resolver under name, allowing to
+// reference the password of Info as "password-<name>=<ref>" in DSNs,
+// environment variables and flags.
+//
+// The resolvers "file" and "env" are registered by default and read
+// the password from a file or an environment variable.
+//
+// Commands are not executed by default, as anyone able to set a DSN,
+// environment variable or flag could execute arbitrary commands.
+// Applications trusting their configuration can register
+// NewCmdSecretResolver explicitly:
+//
+//	err := dsn.RegisterSecretResolver("cmd", dsn.NewCmdSecretResolver())
+//
+// Resolvers must be registered before creating a FlagSet to be
+// available as flags.
+func RegisterSecretResolver(name string, resolver SecretResolver) error {
+	if name == "" || strings.ContainsAny(name, " =_") {
+		return fmt.Errorf("dsn: invalid secret resolver name %q", name)
+	}
+
+	if resolver == nil {
+		return fmt.Errorf("dsn: secret resolver %q is nil", name)
+	}
+
+	secretResolversLock.Lock()
+	defer secretResolversLock.Unlock()
+
+	if _, ok := secretResolvers[name]; ok {
+		return fmt.Errorf("dsn: secret resolver %q is already registered", name)
+	}
+
+	secretResolvers[name] = resolver
+	return nil
+}
+
+// lookupSecretResolver returns the SecretResolver registered under
+// name.
+func lookupSecretResolver(name string) (SecretResolver, bool) {
+	secretResolversLock.RLock()
+	defer secretResolversLock.RUnlock()
+
+	resolver, ok := secretResolvers[name]
+	return resolver, ok
+}
+
+// secretResolverNames returns the names of all registered
+// SecretResolvers.
+func secretResolverNames() []string {
+	secretResolversLock.RLock()
+	defer secretResolversLock.RUnlock()
+
+	names := make([]string, 0, len(secretResolvers))
+	for name := range secretResolvers {
+		names = append(names, name)
+	}
+	return names
+}
+
+// setPasswordRef sets the reference to the password on the Info
+// embedded in target if key is "password-<name>" of a registered
+// SecretResolver.
+//
+// false is returned if key does not reference the password.
+func setPasswordRef(target interface{}, key, ref string) bool {
+	name := strings.TrimPrefix(key, passwordKeyPrefix)
+	if name == key {
+		return false
+	}
+
+	if _, ok := lookupSecretResolver(name); !ok {
+		return false
+	}
+
+	info, ok := embeddedInfo(reflect.ValueOf(target))
+	if !ok || !info.CanSet() {
+		return false
+	}
+
+	info.FieldByName("PasswordSource").SetString(name)
+	info.FieldByName("PasswordRef").SetString(ref)
+	return true
+}
+
+// passwordRef returns the key and the reference of the password of the
+// Info embedded in input if the password is referenced.
+func passwordRef(input interface{}) (string, string, bool) {
+	info, ok := embeddedInfo(reflect.ValueOf(input))
+	if !ok {
+		return "", "", false
+	}
+
+	source := info.FieldByName("PasswordSource").String()
+	if source == "" {
+		return "", "", false
+	}
+
+	return passwordKeyPrefix + source, info.FieldByName("PasswordRef").String(), true
+}
+
+// ResolvePassword returns the password. If the password is referenced
+// it is resolved through the SecretResolver registered as
+// PasswordSource.
+//
+// References are only resolved when calling ResolvePassword, hence
+// changes to the referenced secret are picked up by later calls.
+func (info Info) ResolvePassword(ctx context.Context) (string, error) {
+	if info.PasswordSource == "" {
+		return info.Password, nil
+	}
+
+	if info.Password != "" {
+		return "", fmt.Errorf("dsn: password and %s%s are mutually exclusive",
+			passwordKeyPrefix, info.PasswordSource)
+	}
+
+	resolver, ok := lookupSecretResolver(info.PasswordSource)
+	if !ok {
+		return "", fmt.Errorf("dsn: no secret resolver registered as %q", info.PasswordSource)
+	}
+
+	password, err := resolver.ResolveSecret(ctx, info.PasswordRef)
+	if err != nil {
+		return "", fmt.Errorf("dsn: error resolving %s%s: %w",
+			passwordKeyPrefix, info.PasswordSource, err)
+	}
+
+	return password, nil
+}
+
+// resolveFile returns the content of the file at path without trailing
+// line breaks.
+func resolveFile(ctx context.Context, path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("error reading file: %w", err)
+	}
+
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
+
+// resolveEnv returns the value of the environment variable name.
+func resolveEnv(ctx context.Context, name string) (string, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %q is not set", name)
+	}
+
+	return value, nil
+}
+
+// NewCmdSecretResolver returns a SecretResolver executing the reference
+// as command through the shell and returning its output without
+// trailing line breaks, e.g. for "password-cmd=pass show ase".
+//
+// The resolver must only be registered if DSNs, environment variables
+// and flags are trusted, as their references are executed as is.
+func NewCmdSecretResolver() SecretResolver {
+	return SecretResolverFunc(resolveCmd)
+}
+
+// resolveCmd executes command through the shell and returns its output
+// without trailing line breaks.
+func resolveCmd(ctx context.Context, command string) (string, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+
+	stdout, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("error executing command: %w: %s", err, bytes.TrimSpace(exitErr.Stderr))
+		}
+		return "", fmt.Errorf("error executing command: %w", err)
+	}
+
+	return strings.TrimRight(string(stdout), "\r\n"), nil
+}
@@ -0,0 +1,194 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package dsn
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// registerSecretResolver registers resolver as name for the duration of
+// the test.
+func registerSecretResolver(t *testing.T, name string, resolver SecretResolver) {
+	if err := RegisterSecretResolver(name, resolver); err != nil {
+		t.Fatalf("Error registering resolver: %v", err)
+	}
+
+	t.Cleanup(func() {
+		secretResolversLock.Lock()
+		defer secretResolversLock.Unlock()
+		delete(secretResolvers, name)
+	})
+}
+
+func TestResolvePassword(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(path, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatalf("Error writing password file: %v", err)
+	}
+	t.Setenv("SECRET_TEST_PASSWORD", "from-env")
+
+	registerSecretResolver(t, "reverse", SecretResolverFunc(func(ctx context.Context, ref string) (string, error) {
+		r := []rune(ref)
+		for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+			r[i], r[j] = r[j], r[i]
+		}
+		return string(r), nil
+	}))
+	registerSecretResolver(t, "cmd", NewCmdSecretResolver())
+
+	if err := RegisterSecretResolver("file", SecretResolverFunc(resolveEnv)); err == nil {
+		t.Errorf("Expected error registering resolver twice")
+	}
+
+	cases := map[string]struct {
+		parse    func(*Info) error
+		password string
+		err      bool
+	}{
+		"plain": {
+			parse: func(info *Info) error {
+				return ParseSimple("password=plain", info)
+			},
+			password: "plain",
+		},
+		"file": {
+			parse: func(info *Info) error {
+				return ParseSimple("password-file="+path, info)
+			},
+			password: "from-file",
+		},
+		"env": {
+			parse: func(info *Info) error {
+				return ParseURI("ase://user@host:5000/?password-env=SECRET_TEST_PASSWORD", info)
+			},
+			password: "from-env",
+		},
+		"cmd": {
+			parse: func(info *Info) error {
+				return ParseSimple(`password-cmd="echo from-cmd"`, info)
+			},
+			password: "from-cmd",
+		},
+		"registered": {
+			parse: func(info *Info) error {
+				return ParseSimple("password-reverse=deretsiger", info)
+			},
+			password: "registered",
+		},
+		"from environment": {
+			parse: func(info *Info) error {
+				t.Setenv("SECRET_PASSWORD_FILE", path)
+				return FromEnv("secret", info)
+			},
+			password: "from-file",
+		},
+		"flags": {
+			parse: func(info *Info) error {
+				flagset, err := FlagSet("", flag.ContinueOnError, info)
+				if err != nil {
+					return err
+				}
+				return flagset.Parse([]string{"-password-env=SECRET_TEST_PASSWORD"})
+			},
+			password: "from-env",
+		},
+		"formatted": {
+			parse: func(info *Info) error {
+				return ParseSimple(FormatSimple(&Info{PasswordSource: "file", PasswordRef: path}, FormatSecrets), info)
+			},
+			password: "from-file",
+		},
+		"formatted uri": {
+			parse: func(info *Info) error {
+				uri, err := FormatURI(&Info{Host: "host", PasswordSource: "file", PasswordRef: path}, FormatSecrets)
+				if err != nil {
+					return err
+				}
+				return ParseURI(uri, info)
+			},
+			password: "from-file",
+		},
+		"formatted masked": {
+			parse: func(info *Info) error {
+				return ParseSimple(FormatSimple(&Info{PasswordSource: "file", PasswordRef: path}), info)
+			},
+			err: true,
+		},
+		"unregistered": {
+			parse: func(info *Info) error {
+				return ParseSimple("password-vault=secret/ase", info)
+			},
+			err: true,
+		},
+		"failing command": {
+			parse: func(info *Info) error {
+				return ParseSimple(`password-cmd="exit 1"`, info)
+			},
+			err: true,
+		},
+		"password and reference": {
+			parse: func(info *Info) error {
+				return ParseSimple("password=plain password-file="+path, info)
+			},
+			err: true,
+		},
+	}
+
+	for title, cas := range cases {
+		t.Run(title, func(t *testing.T) {
+			info := new(Info)
+			err := cas.parse(info)
+			if err == nil {
+				var password string
+				password, err = info.ResolvePassword(context.Background())
+				if err == nil && password != cas.password {
+					t.Errorf("Expected password %q, received %q", cas.password, password)
+				}
+			}
+
+			if cas.err != (err != nil) {
+				t.Errorf("Expected error: %t, received: %v", cas.err, err)
+			}
+		})
+	}
+}
+
+func TestSecretResolvers_Defaults(t *testing.T) {
+	// Resolvers executing commands must be registered explicitly.
+	info := new(Info)
+	if err := ParseURI("ase://user@host/db?password-cmd=echo+secret", info); err == nil {
+		t.Errorf("Expected password-cmd to be rejected, received %#v", info)
+	}
+
+	for _, name := range []string{"file", "env"} {
+		if _, ok := lookupSecretResolver(name); !ok {
+			t.Errorf("Expected secret resolver %q to be registered by default", name)
+		}
+	}
+}
+
+func TestFormat_PasswordRef(t *testing.T) {
+	info := &Info{Host: "host", PasswordSource: "file", PasswordRef: "/run/secrets/ase"}
+
+	simple := FormatSimple(info)
+	uri, err := FormatURI(info)
+	if err != nil {
+		t.Fatalf("Formatting URI failed: %v", err)
+	}
+
+	for _, formatted := range []string{simple, uri} {
+		if strings.Contains(formatted, info.PasswordRef) {
+			t.Errorf("Expected reference to be masked in %q", formatted)
+		}
+		if !strings.Contains(formatted, "password-file") || !strings.Contains(formatted, SecretMask) {
+			t.Errorf("Expected masked password-file in %q", formatted)
+		}
+	}
+}
@@ -15,6 +15,7 @@ const (
 	OnlyJSON TagType = "json"
 	Multiref TagType = "multiref"
 	Doc      TagType = "doc"
+	Secret   TagType = "secret"
 )
 
 // TagToField returns a mapping from json metadata tags to
@@ -55,6 +56,9 @@ const (
 //          "hostname": reflect.ValueOf("Hostname to connect to"),
 //       }
 //
+// If the TagType Secret is passed the json metadata tag value will be
+// mapped to the value of the secret metadata tag in the same way.
+//
 // If the input is a pointer the reflect.Values will be addressable and
 // settable - allowing to modify the fields of the passed structure.
 //
@@ -86,7 +90,7 @@ func tagToField(input reflect.Value, tagType TagType) map[string]reflect.Value {
 	for i := 0; i < input.NumField(); i++ {
 		field := input.Field(i)
 
-		if field.Kind() == reflect.Struct {
+		if field.Kind() == reflect.Struct && !isTextValue(field) {
 			// Field is embedded, retrieve all of its members and merge them
 			// into tTF.
 			// This can result in overridden fields, depending on the order
@@ -117,7 +121,7 @@ func tagToField(input reflect.Value, tagType TagType) map[string]reflect.Value {
 			for _, name := range names {
 				ttf[name] = field
 			}
-		case Doc:
+		case Doc, Secret:
 			ttf[names[0]] = reflect.ValueOf(inputT.Field(i).Tag.Get(string(tagType)))
 		}
 	}
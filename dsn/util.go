@@ -5,12 +5,54 @@
 package dsn
 
 import (
+	"encoding"
 	"fmt"
 	"reflect"
 	"strconv"
+	"strings"
+	"time"
 )
 
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// isTextValue returns true if the values of field are set and formatted
+// through encoding.TextUnmarshaler and encoding.TextMarshaler.
+func isTextValue(field reflect.Value) bool {
+	return reflect.PtrTo(field.Type()).Implements(textUnmarshalerType) &&
+		(field.Type().Implements(textMarshalerType) || reflect.PtrTo(field.Type()).Implements(textMarshalerType))
+}
+
+// setValue parses value and sets it on field.
+//
+// Durations are parsed with time.ParseDuration, integers are read as
+// seconds for compatibility with members previously holding seconds.
+// String slices are parsed from comma-separated values.
 func setValue(field reflect.Value, value string) error {
+	if isTextValue(field) {
+		if err := field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value)); err != nil {
+			return fmt.Errorf("error parsing %q as %s: %w", value, field.Type(), err)
+		}
+		return nil
+	}
+
+	if field.Type() == durationType {
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			field.SetInt(int64(time.Duration(n) * time.Second))
+			return nil
+		}
+
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("error parsing %q as duration: %w", value, err)
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
 	switch field.Kind() {
 	case reflect.String:
 		field.SetString(value)
@@ -20,15 +62,91 @@ func setValue(field reflect.Value, value string) error {
 			return fmt.Errorf("error parsing %q as bool: %w", value, err)
 		}
 		field.SetBool(b)
-	case reflect.Int:
-		n, err := strconv.ParseInt(value, 10, 64)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
 		if err != nil {
 			return fmt.Errorf("error parsing %q as int: %w", value, err)
 		}
 		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("error parsing %q as uint: %w", value, err)
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("error parsing %q as float: %w", value, err)
+		}
+		field.SetFloat(f)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unhandled slice element kind: %s", field.Type().Elem().Kind())
+		}
+
+		values := []string{}
+		if value != "" {
+			values = strings.Split(value, ",")
+		}
+
+		slice := reflect.MakeSlice(field.Type(), len(values), len(values))
+		for i, v := range values {
+			slice.Index(i).SetString(v)
+		}
+		field.Set(slice)
 	default:
 		return fmt.Errorf("unhandled field kind: %s", field.Kind())
 	}
 
 	return nil
 }
+
+// formatValue returns the value of field in the format parsed by
+// setValue.
+func formatValue(field reflect.Value) (string, error) {
+	if isTextValue(field) {
+		marshaler, ok := field.Interface().(encoding.TextMarshaler)
+		if !ok {
+			if !field.CanAddr() {
+				return "", fmt.Errorf("unaddressable value of type %s", field.Type())
+			}
+			marshaler = field.Addr().Interface().(encoding.TextMarshaler)
+		}
+
+		text, err := marshaler.MarshalText()
+		if err != nil {
+			return "", fmt.Errorf("error formatting %s: %w", field.Type(), err)
+		}
+		return string(text), nil
+	}
+
+	if field.Type() == durationType {
+		return time.Duration(field.Int()).String(), nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		return field.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(field.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(field.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(field.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(field.Float(), 'g', -1, field.Type().Bits()), nil
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return "", fmt.Errorf("unhandled slice element kind: %s", field.Type().Elem().Kind())
+		}
+
+		values := make([]string, field.Len())
+		for i := range values {
+			values[i] = field.Index(i).String()
+		}
+		return strings.Join(values, ","), nil
+	default:
+		return "", fmt.Errorf("unhandled field kind: %s", field.Kind())
+	}
+}
@@ -0,0 +1,136 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package dsn
+
+import (
+	"flag"
+	"net"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type typedInfo struct {
+	Timeout time.Duration `json:"timeout"`
+	Port    uint16        `json:"port"`
+	Size    uint64        `json:"size"`
+	Ratio   float64       `json:"ratio"`
+	Hosts   []string      `json:"hosts"`
+	Addr    net.IP        `json:"addr"`
+}
+
+func TestTypedValues(t *testing.T) {
+	expect := &typedInfo{
+		Timeout: 90 * time.Second,
+		Port:    5000,
+		Size:    1 << 40,
+		Ratio:   0.25,
+		Hosts:   []string{"host1", "host2"},
+		Addr:    net.ParseIP("10.0.0.1"),
+	}
+
+	cases := map[string]struct {
+		parse func(*typedInfo) error
+		err   bool
+	}{
+		"simple": {
+			parse: func(info *typedInfo) error {
+				return ParseSimple("timeout=1m30s port=5000 size=1099511627776 ratio=0.25 hosts=host1,host2 addr=10.0.0.1", info)
+			},
+		},
+		"env": {
+			parse: func(info *typedInfo) error {
+				for key, value := range map[string]string{
+					"TYPED_TIMEOUT": "90",
+					"TYPED_PORT":    "5000",
+					"TYPED_SIZE":    "1099511627776",
+					"TYPED_RATIO":   "0.25",
+					"TYPED_HOSTS":   "host1,host2",
+					"TYPED_ADDR":    "10.0.0.1",
+				} {
+					os.Setenv(key, value)
+					defer os.Unsetenv(key)
+				}
+				return FromEnv("typed", info)
+			},
+		},
+		"flags": {
+			parse: func(info *typedInfo) error {
+				flagset, err := FlagSet("", flag.ContinueOnError, info)
+				if err != nil {
+					return err
+				}
+				return flagset.Parse([]string{"-timeout=1m30s", "-port=5000", "-size=1099511627776",
+					"-ratio=0.25", "-hosts=host1", "-hosts=host2", "-addr=10.0.0.1"})
+			},
+		},
+		"flags with comma-separated values": {
+			parse: func(info *typedInfo) error {
+				flagset, err := FlagSet("", flag.ContinueOnError, info)
+				if err != nil {
+					return err
+				}
+				return flagset.Parse([]string{"-timeout=1m30s", "-port=5000", "-size=1099511627776",
+					"-ratio=0.25", "-hosts=host1,host2", "-addr=10.0.0.1"})
+			},
+		},
+		"flags replacing default": {
+			parse: func(info *typedInfo) error {
+				info.Hosts = []string{"default"}
+				flagset, err := FlagSet("", flag.ContinueOnError, info)
+				if err != nil {
+					return err
+				}
+				return flagset.Parse([]string{"-timeout=1m30s", "-port=5000", "-size=1099511627776",
+					"-ratio=0.25", "-hosts=host1", "-hosts=host2", "-addr=10.0.0.1"})
+			},
+		},
+		"formatted": {
+			parse: func(info *typedInfo) error {
+				return ParseSimple(FormatSimple(expect), info)
+			},
+		},
+		"invalid duration": {
+			parse: func(info *typedInfo) error {
+				return ParseSimple("timeout=1x", info)
+			},
+			err: true,
+		},
+		"uint overflow": {
+			parse: func(info *typedInfo) error {
+				return ParseSimple("port=65536", info)
+			},
+			err: true,
+		},
+		"invalid text value": {
+			parse: func(info *typedInfo) error {
+				return ParseSimple("addr=host", info)
+			},
+			err: true,
+		},
+	}
+
+	for title, cas := range cases {
+		t.Run(title, func(t *testing.T) {
+			info := new(typedInfo)
+			err := cas.parse(info)
+			if cas.err {
+				if err == nil {
+					t.Fatalf("Expected error, received %#v", info)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Parsing failed: %v", err)
+			}
+
+			if !reflect.DeepEqual(info, expect) {
+				t.Errorf("Expected %#v, received %#v", expect, info)
+			}
+		})
+	}
+}
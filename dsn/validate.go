@@ -0,0 +1,230 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package dsn
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+// ValidationError is a violation of a constraint of a member.
+type ValidationError struct {
+	// Key is the json metadata and Aliases the multiref metadata of
+	// the member.
+	Key     string
+	Aliases []string
+	// Msg describes the violation.
+	Msg string
+}
+
+func (err ValidationError) Error() string {
+	if len(err.Aliases) == 0 {
+		return fmt.Sprintf("%s: %s", err.Key, err.Msg)
+	}
+
+	return fmt.Sprintf("%s (%s): %s", err.Key, strings.Join(err.Aliases, ", "), err.Msg)
+}
+
+// Validate checks the members of input against the constraints in
+// their metadata tags:
+//
+//   - validate:"required" - the member must not be the zero value
+//   - validate:"omitempty" - enum and range are not checked if the
+//     member is the zero value, e.g. if it signals a default
+//   - enum:"a,b,c" - the value must be exactly one of the listed
+//     values, as consumers compare values case-sensitively
+//   - range:"min..max" - the value must be within min and max
+//     inclusively, either bound may be omitted. Alternatives are
+//     separated by '|' and may be single values, e.g. "0|1ms.."
+//
+// Only members with json metadata are checked.
+//
+// If constraints are violated the returned error is
+// a *multierror.Error with a ValidationError for each violation.
+//
+// Example:
+//
+//	type Example struct {
+//	    Host     string        `json:"host" validate:"required"`
+//	    Network  string        `json:"network" enum:"tcp,unix"`
+//	    Strategy string        `json:"strategy" validate:"omitempty" enum:"random,round-robin"`
+//	    Size     int           `json:"size" range:"512..65535"`
+//	    Timeout  time.Duration `json:"timeout" range:"0|1ms.."`
+//	}
+func Validate(input interface{}) error {
+	return validate(reflect.ValueOf(input))
+}
+
+func validate(input reflect.Value) error {
+	if input.Kind() == reflect.Ptr || input.Kind() == reflect.Interface {
+		input = input.Elem()
+	}
+
+	inputT := input.Type()
+
+	var me error
+	for i := 0; i < input.NumField(); i++ {
+		field := input.Field(i)
+		fieldT := inputT.Field(i)
+
+		if field.Kind() == reflect.Struct && !isTextValue(field) {
+			if err := validate(field); err != nil {
+				me = multierror.Append(me, err)
+			}
+			continue
+		}
+
+		key := strings.Split(fieldT.Tag.Get(string(OnlyJSON)), ",")[0]
+		if key == "" {
+			continue
+		}
+
+		var aliases []string
+		if multiref := fieldT.Tag.Get(string(Multiref)); multiref != "" {
+			aliases = strings.Split(multiref, ",")
+		}
+
+		for _, msg := range validateField(field, fieldT.Tag) {
+			me = multierror.Append(me, ValidationError{Key: key, Aliases: aliases, Msg: msg})
+		}
+	}
+
+	return me
+}
+
+// validateField returns the violations of the constraints in tag by
+// field.
+func validateField(field reflect.Value, tag reflect.StructTag) []string {
+	msgs := []string{}
+
+	omitEmpty := false
+	if rules, ok := tag.Lookup("validate"); ok {
+		for _, rule := range strings.Split(rules, ",") {
+			switch rule {
+			case "required":
+				if field.IsZero() {
+					msgs = append(msgs, "is required")
+				}
+			case "omitempty":
+				omitEmpty = true
+			default:
+				msgs = append(msgs, fmt.Sprintf("unknown validation rule %q", rule))
+			}
+		}
+	}
+
+	if omitEmpty && field.IsZero() {
+		return msgs
+	}
+
+	if enum, ok := tag.Lookup("enum"); ok {
+		value, err := formatValue(field)
+		if err != nil {
+			return append(msgs, err.Error())
+		}
+
+		valid := false
+		for _, allowed := range strings.Split(enum, ",") {
+			valid = valid || value == allowed
+		}
+
+		if !valid {
+			msgs = append(msgs, fmt.Sprintf("invalid value %q, expected one of %s", value, enum))
+		}
+	}
+
+	if bounds, ok := tag.Lookup("range"); ok {
+		if msg := validateRange(field, bounds); msg != "" {
+			msgs = append(msgs, msg)
+		}
+	}
+
+	return msgs
+}
+
+// validateRange returns a violation if field is not within any of the
+// alternatives of bounds, which are separated by '|' and are either in
+// the form min..max or a single value.
+func validateRange(field reflect.Value, bounds string) string {
+	for _, alternative := range strings.Split(bounds, "|") {
+		lower, upper, ok := strings.Cut(alternative, "..")
+		if !ok {
+			lower, upper = alternative, alternative
+		}
+
+		if lower == "" && upper == "" {
+			return fmt.Sprintf("invalid range %q, expected min..max", bounds)
+		}
+
+		inRange, err := isInRange(field, lower, upper)
+		if err != nil {
+			return fmt.Sprintf("invalid range %q: %v", bounds, err)
+		}
+
+		if inRange {
+			return ""
+		}
+	}
+
+	value, _ := formatValue(field)
+	return fmt.Sprintf("value %s is out of range %s", value, bounds)
+}
+
+// isInRange returns true if field is within lower and upper
+// inclusively. Empty bounds are not checked.
+func isInRange(field reflect.Value, lower, upper string) (bool, error) {
+	for _, bound := range []struct {
+		s   string
+		cmp int
+	}{{lower, -1}, {upper, 1}} {
+		if bound.s == "" {
+			continue
+		}
+
+		limit := reflect.New(field.Type()).Elem()
+		if err := setValue(limit, bound.s); err != nil {
+			return false, err
+		}
+
+		cmp, err := compareValues(field, limit)
+		if err != nil {
+			return false, err
+		}
+
+		if cmp == bound.cmp {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// compareValues compares two numeric values of the same type and
+// returns -1, 0 or 1 if a is less than, equal to or greater than b.
+func compareValues(a, b reflect.Value) (int, error) {
+	var less, greater bool
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		less, greater = a.Int() < b.Int(), a.Int() > b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		less, greater = a.Uint() < b.Uint(), a.Uint() > b.Uint()
+	case reflect.Float32, reflect.Float64:
+		less, greater = a.Float() < b.Float(), a.Float() > b.Float()
+	default:
+		return 0, fmt.Errorf("range is not supported for kind %s", a.Kind())
+	}
+
+	switch {
+	case less:
+		return -1, nil
+	case greater:
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
@@ -0,0 +1,112 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package dsn
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+type validatedInfo struct {
+	Info
+	Network  string        `json:"network" multiref:"net" enum:"tcp,unix"`
+	Strategy string        `json:"strategy" validate:"omitempty" enum:"random,round-robin"`
+	Size     int           `json:"size" validate:"omitempty" range:"512..65535"`
+	Queue    uint          `json:"queue" validate:"omitempty" range:"1.."`
+	Ratio    float64       `json:"ratio" range:"-1.5..1"`
+	Offset   int           `json:"offset" range:"-10..-1|0|1..10"`
+	Timeout  time.Duration `json:"timeout" range:"0|1s..1m"`
+}
+
+func TestValidate(t *testing.T) {
+	cases := map[string]struct {
+		info *validatedInfo
+		errs []string
+	}{
+		"valid": {
+			info: &validatedInfo{
+				Info:     Info{Host: "localhost"},
+				Network:  "tcp",
+				Strategy: "random",
+				Size:     512,
+				Queue:    1,
+				Ratio:    -1.5,
+				Offset:   -10,
+				Timeout:  time.Minute,
+			},
+		},
+		"zero values": {
+			info: &validatedInfo{Info: Info{Host: "localhost"}, Network: "tcp"},
+		},
+		"zero value not in enum": {
+			info: &validatedInfo{Info: Info{Host: "localhost"}},
+			errs: []string{`network (net): invalid value "", expected one of tcp,unix`},
+		},
+		"required": {
+			info: &validatedInfo{Network: "tcp"},
+			errs: []string{"host (hostname): is required"},
+		},
+		"enum is case-sensitive": {
+			info: &validatedInfo{
+				Info:    Info{Host: "localhost"},
+				Network: "TCP",
+			},
+			errs: []string{`network (net): invalid value "TCP", expected one of tcp,unix`},
+		},
+		"all violations": {
+			info: &validatedInfo{
+				Network:  "udp",
+				Strategy: "failover",
+				Size:     65536,
+				Ratio:    -2,
+				Offset:   11,
+				Timeout:  time.Millisecond,
+			},
+			errs: []string{
+				"host (hostname): is required",
+				`network (net): invalid value "udp", expected one of tcp,unix`,
+				`strategy: invalid value "failover", expected one of random,round-robin`,
+				"size: value 65536 is out of range 512..65535",
+				"ratio: value -2 is out of range -1.5..1",
+				"offset: value 11 is out of range -10..-1|0|1..10",
+				"timeout: value 1ms is out of range 0|1s..1m",
+			},
+		},
+	}
+
+	for title, cas := range cases {
+		t.Run(title, func(t *testing.T) {
+			err := Validate(cas.info)
+			if len(cas.errs) == 0 {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+
+			var me *multierror.Error
+			if !errors.As(err, &me) {
+				t.Fatalf("Expected multierror, received %#v", err)
+			}
+
+			recv := []string{}
+			for _, err := range me.Errors {
+				var verr ValidationError
+				if !errors.As(err, &verr) {
+					t.Errorf("Expected ValidationError, received %#v", err)
+				}
+				recv = append(recv, err.Error())
+			}
+
+			if !reflect.DeepEqual(recv, cas.errs) {
+				t.Errorf("Expected errors %q, received %q", cas.errs, recv)
+			}
+		})
+	}
+}
@@ -30,7 +30,7 @@ type ConnectorCreator func(interface{}) (driver.Connector, error)
 // suffix `connector`.
 func RegisterDSN(name string, info interface{}, connectorFn ConnectorCreator) error {
 	sqlDBMap[name] = func() (*sql.DB, error) {
-		db, err := sql.Open("ase", dsn.FormatSimple(info))
+		db, err := sql.Open("ase", dsn.FormatSimple(info, dsn.FormatSecrets))
 		if err != nil {
 			return nil, err
 		}
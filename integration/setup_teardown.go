@@ -104,7 +104,7 @@ func SetupDB(ctx context.Context, info interface{}, dbname string) error {
 	DBCreateLock.Lock()
 	defer DBCreateLock.Unlock()
 
-	db, err := sql.Open("ase", dsn.FormatSimple(info))
+	db, err := sql.Open("ase", dsn.FormatSimple(info, dsn.FormatSecrets))
 	if err != nil {
 		return fmt.Errorf("integration: failed to open database: %w", err)
 	}
@@ -167,7 +167,7 @@ func TeardownDB(ctx context.Context, info interface{}) error {
 	DBCreateLock.Lock()
 	defer DBCreateLock.Unlock()
 
-	db, err := sql.Open("ase", dsn.FormatSimple(info))
+	db, err := sql.Open("ase", dsn.FormatSimple(info, dsn.FormatSecrets))
 	if err != nil {
 		return fmt.Errorf("integration: failed to open database: %w", err)
 	}
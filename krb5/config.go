@@ -0,0 +1,81 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package krb5
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/jcmturner/gokrb5/v8/config"
+)
+
+// DefaultKDCPort is the port KDCs are contacted on if the address of
+// a KDC does not include a port.
+const DefaultKDCPort = "88"
+
+// DefaultConfigPath returns the path of the Kerberos configuration,
+// which is either set in the environment variable KRB5_CONFIG or
+// /etc/krb5.conf.
+func DefaultConfigPath() string {
+	if path := os.Getenv("KRB5_CONFIG"); path != "" {
+		return path
+	}
+
+	return "/etc/krb5.conf"
+}
+
+// DefaultCCachePath returns the path of the credential cache of the
+// current user, which is either set in the environment variable
+// KRB5CCNAME or the default of MIT Kerberos.
+//
+// Only credential caches of the type FILE are supported.
+func DefaultCCachePath() string {
+	if name := os.Getenv("KRB5CCNAME"); name != "" {
+		return strings.TrimPrefix(name, "FILE:")
+	}
+
+	return fmt.Sprintf("/tmp/krb5cc_%d", os.Getuid())
+}
+
+// NewConfig returns a configuration with realm as default realm.
+//
+// If kdcs are passed they are contacted over TCP for realm. Otherwise
+// the configuration is read from DefaultConfigPath, in which case its
+// default realm is only overridden if realm is not empty.
+func NewConfig(realm string, kdcs ...string) (*config.Config, error) {
+	if len(kdcs) == 0 {
+		cfg, err := config.Load(DefaultConfigPath())
+		if err != nil {
+			return nil, fmt.Errorf("krb5: error loading configuration: %w", err)
+		}
+
+		if realm != "" {
+			cfg.LibDefaults.DefaultRealm = realm
+		}
+		return cfg, nil
+	}
+
+	addrs := make([]string, len(kdcs))
+	for i, kdc := range kdcs {
+		if _, _, err := net.SplitHostPort(kdc); err != nil {
+			kdc = net.JoinHostPort(kdc, DefaultKDCPort)
+		}
+		addrs[i] = kdc
+	}
+
+	cfg := config.New()
+	cfg.LibDefaults.DefaultRealm = realm
+	// Messages of all sizes are sent over TCP.
+	cfg.LibDefaults.UDPPreferenceLimit = 1
+	cfg.Realms = []config.Realm{{
+		Realm:         realm,
+		DefaultDomain: realm,
+		KDC:           addrs,
+	}}
+
+	return cfg, nil
+}
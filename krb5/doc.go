@@ -0,0 +1,13 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package krb5 establishes Kerberos V5 GSS-API security contexts
+// (RFC 4121) with services.
+//
+// The Kerberos protocol, i.e. acquiring tickets from KDCs with keytabs
+// or credential caches, is implemented by
+// github.com/jcmturner/gokrb5. This package adds the verification of
+// the AP-REP token returned by services for mutual authentication,
+// which gokrb5 does not support.
+package krb5
@@ -0,0 +1,102 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package krb5
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jcmturner/gokrb5/v8/client"
+	"github.com/jcmturner/gokrb5/v8/crypto"
+	"github.com/jcmturner/gokrb5/v8/gssapi"
+	"github.com/jcmturner/gokrb5/v8/iana/flags"
+	"github.com/jcmturner/gokrb5/v8/iana/keyusage"
+	"github.com/jcmturner/gokrb5/v8/messages"
+	"github.com/jcmturner/gokrb5/v8/spnego"
+	"github.com/jcmturner/gokrb5/v8/types"
+)
+
+// ErrMutualAuthentication is returned by SecContext.Verify if the
+// service did not prove the knowledge of the session key.
+var ErrMutualAuthentication = errors.New("krb5: service failed mutual authentication")
+
+// SecContext is the client side of a security context established with
+// InitSecContext.
+type SecContext struct {
+	key   types.EncryptionKey
+	ctime time.Time
+	cusec int
+}
+
+// InitSecContext returns the initial GSS-API token to authenticate
+// client to the service principal spn, e.g. "sybase/host.example.com",
+// with mutual authentication.
+//
+// The token returned by the service is verified with
+// SecContext.Verify.
+func InitSecContext(client *client.Client, spn string) ([]byte, *SecContext, error) {
+	ticket, key, err := client.GetServiceTicket(spn)
+	if err != nil {
+		return nil, nil, fmt.Errorf("krb5: error acquiring ticket for %s: %w", spn, err)
+	}
+
+	token, err := spnego.NewKRB5TokenAPREQ(client, ticket, key,
+		[]int{gssapi.ContextFlagMutual}, []int{flags.APOptionMutualRequired})
+	if err != nil {
+		return nil, nil, fmt.Errorf("krb5: error creating AP-REQ: %w", err)
+	}
+
+	// The authenticator is only stored encrypted, its timestamp is
+	// required to verify the AP-REP.
+	if err := token.APReq.DecryptAuthenticator(key); err != nil {
+		return nil, nil, fmt.Errorf("krb5: error reading authenticator: %w", err)
+	}
+
+	bs, err := token.Marshal()
+	if err != nil {
+		return nil, nil, fmt.Errorf("krb5: error marshaling AP-REQ token: %w", err)
+	}
+
+	return bs, &SecContext{
+		key:   key,
+		ctime: token.APReq.Authenticator.CTime,
+		cusec: token.APReq.Authenticator.Cusec,
+	}, nil
+}
+
+// Verify verifies the AP-REP token returned by the service, as defined
+// in RFC 4120, section 3.2.5: the encrypted part must be decryptable
+// with the session key and contain the timestamp of the authenticator.
+func (secCtx *SecContext) Verify(bs []byte) error {
+	token := &spnego.KRB5Token{}
+	if err := token.Unmarshal(bs); err != nil {
+		return fmt.Errorf("krb5: error unmarshaling token: %w", err)
+	}
+
+	if token.IsKRBError() {
+		return fmt.Errorf("%w: %v", ErrMutualAuthentication, token.KRBError)
+	}
+
+	if !token.IsAPRep() {
+		return fmt.Errorf("%w: token is not an AP-REP", ErrMutualAuthentication)
+	}
+
+	decrypted, err := crypto.DecryptEncPart(token.APRep.EncPart, secCtx.key, keyusage.AP_REP_ENCPART)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrMutualAuthentication, err)
+	}
+
+	part := &messages.EncAPRepPart{}
+	if err := part.Unmarshal(decrypted); err != nil {
+		return fmt.Errorf("krb5: error unmarshaling AP-REP: %w", err)
+	}
+
+	if !part.CTime.Equal(secCtx.ctime) || part.Cusec != secCtx.cusec {
+		return fmt.Errorf("%w: reply does not match authenticator", ErrMutualAuthentication)
+	}
+
+	return nil
+}
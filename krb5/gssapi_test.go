@@ -0,0 +1,101 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package krb5_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/SAP/go-dblib/krb5"
+	"github.com/SAP/go-dblib/krb5/krb5test"
+	"github.com/jcmturner/gokrb5/v8/client"
+)
+
+func TestSecContext_Verify(t *testing.T) {
+	kdc, err := krb5test.NewKDC("EXAMPLE.COM")
+	if err != nil {
+		t.Fatalf("error creating KDC: %v", err)
+	}
+	defer kdc.Close()
+
+	userKeytab, err := kdc.AddPrincipal("user")
+	if err != nil {
+		t.Fatalf("error adding user: %v", err)
+	}
+
+	serviceKeytab, err := kdc.AddPrincipal("ase/db.example.com")
+	if err != nil {
+		t.Fatalf("error adding service: %v", err)
+	}
+
+	cfg, err := krb5.NewConfig(kdc.Realm(), kdc.Addr().String())
+	if err != nil {
+		t.Fatalf("error creating configuration: %v", err)
+	}
+
+	cl := client.NewWithKeytab("user", kdc.Realm(), userKeytab, cfg, client.DisablePAFXFAST(true))
+
+	// otherReply is the AP-REP of another security context with the
+	// same service.
+	otherToken, _, err := krb5.InitSecContext(cl, "ase/db.example.com")
+	if err != nil {
+		t.Fatalf("error initiating security context: %v", err)
+	}
+
+	_, otherReply, err := krb5test.AcceptSecContext(serviceKeytab, otherToken)
+	if err != nil {
+		t.Fatalf("error accepting security context: %v", err)
+	}
+
+	cases := map[string]struct {
+		reply func(token []byte) ([]byte, error)
+		errIs error
+	}{
+		"valid": {
+			reply: func(token []byte) ([]byte, error) {
+				_, reply, err := krb5test.AcceptSecContext(serviceKeytab, token)
+				return reply, err
+			},
+		},
+		"other context": {
+			reply: func(token []byte) ([]byte, error) {
+				return otherReply, nil
+			},
+			errIs: krb5.ErrMutualAuthentication,
+		},
+		"request echoed": {
+			reply: func(token []byte) ([]byte, error) {
+				return token, nil
+			},
+			errIs: krb5.ErrMutualAuthentication,
+		},
+	}
+
+	for title, cas := range cases {
+		t.Run(title, func(t *testing.T) {
+			token, secCtx, err := krb5.InitSecContext(cl, "ase/db.example.com")
+			if err != nil {
+				t.Fatalf("error initiating security context: %v", err)
+			}
+
+			reply, err := cas.reply(token)
+			if err != nil {
+				t.Fatalf("error creating reply: %v", err)
+			}
+
+			err = secCtx.Verify(reply)
+			if cas.errIs == nil {
+				if err != nil {
+					t.Fatalf("error verifying reply: %v", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, cas.errIs) {
+				t.Fatalf("expected error %v, received: %v", cas.errIs, err)
+			}
+		})
+	}
+}
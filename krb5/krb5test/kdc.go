@@ -0,0 +1,466 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package krb5test provides a KDC and a GSS-API acceptor for tests of
+// Kerberos authentication.
+//
+// Messages are encoded, encrypted and verified with
+// github.com/jcmturner/gokrb5.
+package krb5test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/jcmturner/gofork/encoding/asn1"
+	"github.com/jcmturner/gokrb5/v8/asn1tools"
+	"github.com/jcmturner/gokrb5/v8/crypto"
+	"github.com/jcmturner/gokrb5/v8/gssapi"
+	"github.com/jcmturner/gokrb5/v8/iana"
+	"github.com/jcmturner/gokrb5/v8/iana/asnAppTag"
+	"github.com/jcmturner/gokrb5/v8/iana/errorcode"
+	"github.com/jcmturner/gokrb5/v8/iana/etypeID"
+	"github.com/jcmturner/gokrb5/v8/iana/keyusage"
+	"github.com/jcmturner/gokrb5/v8/iana/msgtype"
+	"github.com/jcmturner/gokrb5/v8/iana/nametype"
+	"github.com/jcmturner/gokrb5/v8/iana/patype"
+	"github.com/jcmturner/gokrb5/v8/keytab"
+	"github.com/jcmturner/gokrb5/v8/messages"
+	"github.com/jcmturner/gokrb5/v8/service"
+	"github.com/jcmturner/gokrb5/v8/spnego"
+	"github.com/jcmturner/gokrb5/v8/types"
+)
+
+const (
+	// ticketLifetime is the maximum lifetime of issued tickets.
+	ticketLifetime = time.Hour
+	// kvno is the key version number of all keys.
+	kvno = 1
+	// etype is the encryption type of all keys.
+	etype = etypeID.AES256_CTS_HMAC_SHA1_96
+	// maxMessageSize limits the size of requests read by the KDC.
+	maxMessageSize = 1 << 16
+)
+
+// KDC is a key distribution center listening on the loopback interface
+// which issues tickets for the principals added with AddPrincipal.
+//
+// Pre-authentication is not required. Ticket flags, renewal and
+// cross-realm authentication are not supported.
+type KDC struct {
+	realm    string
+	listener net.Listener
+	wg       *sync.WaitGroup
+
+	lock    *sync.Mutex
+	keytabs map[string]*keytab.Keytab
+}
+
+// NewKDC returns a started KDC for realm.
+func NewKDC(realm string) (*KDC, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("krb5test: error opening listener: %w", err)
+	}
+
+	kdc := &KDC{
+		realm:    realm,
+		listener: listener,
+		wg:       &sync.WaitGroup{},
+		lock:     &sync.Mutex{},
+		keytabs:  map[string]*keytab.Keytab{},
+	}
+
+	if _, err := kdc.AddPrincipal("krbtgt/" + realm); err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	kdc.wg.Add(1)
+	go kdc.serve()
+
+	return kdc, nil
+}
+
+// Addr returns the address the KDC is listening on.
+func (kdc *KDC) Addr() net.Addr {
+	return kdc.listener.Addr()
+}
+
+// Realm returns the realm of the KDC.
+func (kdc *KDC) Realm() string {
+	return kdc.realm
+}
+
+// Close stops the KDC.
+func (kdc *KDC) Close() error {
+	err := kdc.listener.Close()
+	kdc.wg.Wait()
+	return err
+}
+
+// AddPrincipal adds a principal in the realm of the KDC with a random
+// key and returns a keytab with the key.
+func (kdc *KDC) AddPrincipal(name string) (*keytab.Keytab, error) {
+	password := make([]byte, 32)
+	if _, err := rand.Read(password); err != nil {
+		return nil, fmt.Errorf("krb5test: error generating password: %w", err)
+	}
+
+	kt := keytab.New()
+	if err := kt.AddEntry(name, kdc.realm, hex.EncodeToString(password), time.Now(), kvno, etype); err != nil {
+		return nil, fmt.Errorf("krb5test: error adding %s to keytab: %w", name, err)
+	}
+
+	kdc.lock.Lock()
+	defer kdc.lock.Unlock()
+
+	kdc.keytabs[name] = kt
+	return kt, nil
+}
+
+// principalKeytab returns the keytab of the principal name.
+func (kdc *KDC) principalKeytab(name types.PrincipalName) (*keytab.Keytab, bool) {
+	kdc.lock.Lock()
+	defer kdc.lock.Unlock()
+
+	kt, ok := kdc.keytabs[name.PrincipalNameString()]
+	return kt, ok
+}
+
+// key returns the key of the principal name.
+func (kdc *KDC) key(name types.PrincipalName) (types.EncryptionKey, bool) {
+	kt, ok := kdc.principalKeytab(name)
+	if !ok {
+		return types.EncryptionKey{}, false
+	}
+
+	key, _, err := kt.GetEncryptionKey(name, kdc.realm, kvno, etype)
+	return key, err == nil
+}
+
+// CCache returns a credential cache in the format of MIT Kerberos of a
+// principal added with AddPrincipal holding a ticket-granting ticket.
+func (kdc *KDC) CCache(name string) ([]byte, error) {
+	client := types.NewPrincipalName(nametype.KRB_NT_PRINCIPAL, name)
+	if _, ok := kdc.key(client); !ok {
+		return nil, fmt.Errorf("krb5test: unknown principal %s", name)
+	}
+
+	tgs := kdc.tgsPrincipal()
+	ticket, sessionKey, part, err := kdc.issue(client, tgs, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	ticketBs, err := ticket.Marshal()
+	if err != nil {
+		return nil, fmt.Errorf("krb5test: error marshaling ticket: %w", err)
+	}
+
+	buf := &bytes.Buffer{}
+	// version 4 with an empty header
+	buf.Write([]byte{5, 4, 0, 0})
+	kdc.writePrincipal(buf, client)
+
+	kdc.writePrincipal(buf, client)
+	kdc.writePrincipal(buf, tgs)
+	binary.Write(buf, binary.BigEndian, uint16(sessionKey.KeyType))
+	writeData(buf, sessionKey.KeyValue)
+	for _, t := range []time.Time{part.AuthTime, part.StartTime, part.EndTime, part.RenewTill} {
+		binary.Write(buf, binary.BigEndian, uint32(t.Unix()))
+	}
+	// is_skey, flags, addresses and authorization data
+	buf.WriteByte(0)
+	buf.Write(part.Flags.Bytes)
+	binary.Write(buf, binary.BigEndian, uint32(0))
+	binary.Write(buf, binary.BigEndian, uint32(0))
+	writeData(buf, ticketBs)
+	writeData(buf, nil)
+
+	return buf.Bytes(), nil
+}
+
+func (kdc *KDC) writePrincipal(buf *bytes.Buffer, name types.PrincipalName) {
+	binary.Write(buf, binary.BigEndian, uint32(name.NameType))
+	binary.Write(buf, binary.BigEndian, uint32(len(name.NameString)))
+	writeData(buf, []byte(kdc.realm))
+	for _, component := range name.NameString {
+		writeData(buf, []byte(component))
+	}
+}
+
+func writeData(buf *bytes.Buffer, bs []byte) {
+	binary.Write(buf, binary.BigEndian, uint32(len(bs)))
+	buf.Write(bs)
+}
+
+func (kdc *KDC) tgsPrincipal() types.PrincipalName {
+	return types.NewPrincipalName(nametype.KRB_NT_SRV_INST, "krbtgt/"+kdc.realm)
+}
+
+func (kdc *KDC) serve() {
+	defer kdc.wg.Done()
+
+	for {
+		conn, err := kdc.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		kdc.wg.Add(1)
+		go func() {
+			defer kdc.wg.Done()
+			defer conn.Close()
+
+			if err := conn.SetDeadline(time.Now().Add(time.Minute)); err != nil {
+				return
+			}
+
+			// Messages over TCP are prefixed with their length, see
+			// RFC 4120, section 7.2.2.
+			var length uint32
+			if err := binary.Read(conn, binary.BigEndian, &length); err != nil || length > maxMessageSize {
+				return
+			}
+
+			req := make([]byte, length)
+			if _, err := io.ReadFull(conn, req); err != nil {
+				return
+			}
+
+			rep, err := kdc.handle(req)
+			if err != nil {
+				return
+			}
+
+			if err := binary.Write(conn, binary.BigEndian, uint32(len(rep))); err != nil {
+				return
+			}
+			conn.Write(rep)
+		}()
+	}
+}
+
+// handle returns the reply to an AS-REQ or TGS-REQ. Requests which are
+// rejected are answered with a KRB-ERROR.
+func (kdc *KDC) handle(req []byte) ([]byte, error) {
+	var rep interface{ Marshal() ([]byte, error) }
+	var err error
+
+	asReq := messages.ASReq{}
+	if asReq.Unmarshal(req) == nil {
+		rep, err = kdc.handleAS(asReq)
+	} else {
+		tgsReq := messages.TGSReq{}
+		if err := tgsReq.Unmarshal(req); err != nil {
+			return nil, err
+		}
+		rep, err = kdc.handleTGS(tgsReq)
+	}
+
+	if krbErr, ok := err.(messages.KRBError); ok {
+		return krbErr.Marshal()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return rep.Marshal()
+}
+
+func (kdc *KDC) handleAS(req messages.ASReq) (*messages.ASRep, error) {
+	body := req.ReqBody
+
+	clientKey, ok := kdc.key(body.CName)
+	if !ok {
+		return nil, messages.NewKRBError(body.SName, kdc.realm, errorcode.KDC_ERR_C_PRINCIPAL_UNKNOWN, "client not found")
+	}
+
+	fields, err := kdc.reply(msgtype.KRB_AS_REP, body.CName, body, clientKey, keyusage.AS_REP_ENCPART)
+	if err != nil {
+		return nil, err
+	}
+
+	return &messages.ASRep{KDCRepFields: *fields}, nil
+}
+
+func (kdc *KDC) handleTGS(req messages.TGSReq) (*messages.TGSRep, error) {
+	body := req.ReqBody
+
+	var apReq *messages.APReq
+	for _, padata := range req.PAData {
+		if padata.PADataType == patype.PA_TGS_REQ {
+			apReq = &messages.APReq{}
+			if err := apReq.Unmarshal(padata.PADataValue); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if apReq == nil {
+		return nil, messages.NewKRBError(body.SName, kdc.realm, errorcode.KDC_ERR_PADATA_TYPE_NOSUPP, "missing ticket-granting ticket")
+	}
+
+	tgsKeytab, _ := kdc.principalKeytab(kdc.tgsPrincipal())
+	if ok, err := apReq.Verify(tgsKeytab, 5*time.Minute, types.HostAddress{}, nil); !ok {
+		if krbErr, isKRBErr := err.(messages.KRBError); isKRBErr {
+			return nil, krbErr
+		}
+		return nil, messages.NewKRBError(body.SName, kdc.realm, errorcode.KRB_AP_ERR_BAD_INTEGRITY, fmt.Sprint(err))
+	}
+
+	if !apReq.Ticket.SName.Equal(kdc.tgsPrincipal()) {
+		return nil, messages.NewKRBError(body.SName, kdc.realm, errorcode.KDC_ERR_POLICY, "ticket is not a ticket-granting ticket")
+	}
+
+	tgt := apReq.Ticket.DecryptedEncPart
+	fields, err := kdc.reply(msgtype.KRB_TGS_REP, tgt.CName, body, tgt.Key, keyusage.TGS_REP_ENCPART_SESSION_KEY)
+	if err != nil {
+		return nil, err
+	}
+
+	return &messages.TGSRep{KDCRepFields: *fields}, nil
+}
+
+// reply issues a ticket for the service requested in body to client
+// and returns the reply with the encrypted part encrypted with key.
+func (kdc *KDC) reply(msgType int, client types.PrincipalName, body messages.KDCReqBody, key types.EncryptionKey, usage uint32) (*messages.KDCRepFields, error) {
+	if _, ok := kdc.key(body.SName); !ok {
+		return nil, messages.NewKRBError(body.SName, kdc.realm, errorcode.KDC_ERR_S_PRINCIPAL_UNKNOWN, "server not found")
+	}
+
+	ticket, sessionKey, part, err := kdc.issue(client, body.SName, body.Till)
+	if err != nil {
+		return nil, err
+	}
+
+	repPart := messages.EncKDCRepPart{
+		Key:       sessionKey,
+		LastReqs:  []messages.LastReq{{LRType: 0, LRValue: part.AuthTime}},
+		Nonce:     body.Nonce,
+		Flags:     part.Flags,
+		AuthTime:  part.AuthTime,
+		StartTime: part.StartTime,
+		EndTime:   part.EndTime,
+		SRealm:    kdc.realm,
+		SName:     body.SName,
+	}
+
+	repPartBs, err := repPart.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	encPart, err := crypto.GetEncryptedData(repPartBs, key, usage, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &messages.KDCRepFields{
+		PVNO:    iana.PVNO,
+		MsgType: msgType,
+		CRealm:  kdc.realm,
+		CName:   client,
+		Ticket:  ticket,
+		EncPart: encPart,
+	}, nil
+}
+
+// issue returns a ticket for service issued to client valid until
+// till, limited to ticketLifetime.
+func (kdc *KDC) issue(client, service types.PrincipalName, till time.Time) (messages.Ticket, types.EncryptionKey, messages.EncTicketPart, error) {
+	now := time.Now().UTC().Truncate(time.Second)
+	end := now.Add(ticketLifetime)
+	if !till.IsZero() && till.Before(end) {
+		end = till.UTC().Truncate(time.Second)
+	}
+
+	serviceKeytab, ok := kdc.principalKeytab(service)
+	if !ok {
+		return messages.Ticket{}, types.EncryptionKey{}, messages.EncTicketPart{},
+			fmt.Errorf("krb5test: unknown principal %s", service.PrincipalNameString())
+	}
+
+	part := messages.EncTicketPart{
+		Flags:     types.NewKrbFlags(),
+		AuthTime:  now,
+		StartTime: now,
+		EndTime:   end,
+	}
+
+	ticket, sessionKey, err := messages.NewTicket(client, kdc.realm, service, kdc.realm, part.Flags,
+		serviceKeytab, etype, kvno, part.AuthTime, part.StartTime, part.EndTime, part.RenewTill)
+	if err != nil {
+		return messages.Ticket{}, types.EncryptionKey{}, messages.EncTicketPart{},
+			fmt.Errorf("krb5test: error issuing ticket: %w", err)
+	}
+
+	part.Key = sessionKey
+	return ticket, sessionKey, part, nil
+}
+
+// AcceptSecContext verifies the initial GSS-API token of a client with
+// the service key in kt and returns the client principal and the
+// AP-REP token for mutual authentication.
+func AcceptSecContext(kt *keytab.Keytab, token []byte) (types.PrincipalName, []byte, error) {
+	krb5Token := &spnego.KRB5Token{}
+	if err := krb5Token.Unmarshal(token); err != nil {
+		return types.PrincipalName{}, nil, fmt.Errorf("krb5test: error unmarshaling token: %w", err)
+	}
+
+	if !krb5Token.IsAPReq() {
+		return types.PrincipalName{}, nil, fmt.Errorf("krb5test: token is not an AP-REQ")
+	}
+
+	apReq := krb5Token.APReq
+	ok, creds, err := service.VerifyAPREQ(&apReq, service.NewSettings(kt, service.DecodePAC(false)))
+	if !ok {
+		return types.PrincipalName{}, nil, fmt.Errorf("krb5test: error verifying AP-REQ: %v", err)
+	}
+
+	// The AP-REP is defined in RFC 4120, section 5.5.2 and wrapped as
+	// defined in RFC 4121, section 4.1. gokrb5 only supports parsing
+	// it.
+	repPart, err := asn1.Marshal(messages.EncAPRepPart{
+		CTime: apReq.Authenticator.CTime,
+		Cusec: apReq.Authenticator.Cusec,
+	})
+	if err != nil {
+		return types.PrincipalName{}, nil, fmt.Errorf("krb5test: error marshaling AP-REP: %w", err)
+	}
+
+	encPart, err := crypto.GetEncryptedData(asn1tools.AddASNAppTag(repPart, asnAppTag.EncAPRepPart),
+		apReq.Ticket.DecryptedEncPart.Key, keyusage.AP_REP_ENCPART, 0)
+	if err != nil {
+		return types.PrincipalName{}, nil, fmt.Errorf("krb5test: error encrypting AP-REP: %w", err)
+	}
+
+	rep, err := asn1.Marshal(messages.APRep{
+		PVNO:    iana.PVNO,
+		MsgType: msgtype.KRB_AP_REP,
+		EncPart: encPart,
+	})
+	if err != nil {
+		return types.PrincipalName{}, nil, fmt.Errorf("krb5test: error marshaling AP-REP: %w", err)
+	}
+
+	oid, err := asn1.Marshal(gssapi.OIDKRB5.OID())
+	if err != nil {
+		return types.PrincipalName{}, nil, fmt.Errorf("krb5test: error marshaling OID: %w", err)
+	}
+
+	reply := append(oid, 0x02, 0x00)
+	reply = append(reply, asn1tools.AddASNAppTag(rep, asnAppTag.APREP)...)
+
+	return creds.CName(), asn1tools.AddASNAppTag(reply, 0), nil
+}
@@ -0,0 +1,383 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/SAP/go-dblib/asetypes"
+	"github.com/SAP/go-dblib/krb5"
+	"github.com/jcmturner/gokrb5/v8/client"
+	"github.com/jcmturner/gokrb5/v8/credentials"
+	"github.com/jcmturner/gokrb5/v8/keytab"
+	"github.com/jcmturner/gokrb5/v8/types"
+)
+
+// Authenticator authenticates a login with security tokens instead of
+// a password, e.g. for network-based authentication with Kerberos.
+//
+// The tokens are exchanged in TDS_MSG_SEC_OPAQUE messages. The initial
+// token is sent with the login payload. Each token returned by the
+// server is passed to Continue, whose reply is sent to the server until
+// the server accepts or rejects the login.
+type Authenticator interface {
+	// InitialToken returns the token sent with the login payload.
+	InitialToken(ctx context.Context) ([]byte, error)
+	// Continue is called with a token returned by the server and
+	// returns the token to reply with. A token accompanying the
+	// acceptance of the login is passed to Continue as well, in
+	// which case the reply is discarded.
+	Continue(ctx context.Context, token []byte) ([]byte, error)
+}
+
+// CompletingAuthenticator is implemented by Authenticators which must
+// confirm a login accepted by the server, e.g. as the server must
+// authenticate itself.
+type CompletingAuthenticator interface {
+	Authenticator
+	// Complete is called once the server accepted the login. The
+	// login fails if an error is returned.
+	Complete(ctx context.Context) error
+}
+
+// KerberosAuthenticator authenticates logins with Kerberos tickets for
+// the principal of the server, exchanged as GSS-API tokens.
+//
+// Mutual authentication is required - logins fail with
+// krb5.ErrMutualAuthentication unless the server returns a token,
+// which is then verified.
+type KerberosAuthenticator struct {
+	client  *client.Client
+	service string
+
+	lock     *sync.Mutex
+	secCtx   *krb5.SecContext
+	verified bool
+}
+
+// NewKerberosAuthenticator returns a KerberosAuthenticator acquiring
+// tickets for the service principal name service, e.g.
+// "sybase/host.example.com", with client.
+func NewKerberosAuthenticator(client *client.Client, service string) *KerberosAuthenticator {
+	return &KerberosAuthenticator{
+		client:  client,
+		service: service,
+		lock:    &sync.Mutex{},
+	}
+}
+
+// InitialToken implements the Authenticator interface.
+func (auth *KerberosAuthenticator) InitialToken(ctx context.Context) ([]byte, error) {
+	token, secCtx, err := krb5.InitSecContext(auth.client, auth.service)
+	if err != nil {
+		return nil, fmt.Errorf("error initiating Kerberos security context: %w", err)
+	}
+
+	auth.lock.Lock()
+	defer auth.lock.Unlock()
+
+	auth.secCtx = secCtx
+	auth.verified = false
+	return token, nil
+}
+
+// Continue implements the Authenticator interface.
+func (auth *KerberosAuthenticator) Continue(ctx context.Context, token []byte) ([]byte, error) {
+	auth.lock.Lock()
+	defer auth.lock.Unlock()
+
+	if auth.secCtx == nil {
+		return nil, errors.New("received Kerberos token before the initial token was sent")
+	}
+
+	if err := auth.secCtx.Verify(token); err != nil {
+		return nil, fmt.Errorf("error verifying Kerberos token of server: %w", err)
+	}
+
+	auth.verified = true
+	return nil, nil
+}
+
+// Complete implements the CompletingAuthenticator interface.
+func (auth *KerberosAuthenticator) Complete(ctx context.Context) error {
+	auth.lock.Lock()
+	defer auth.lock.Unlock()
+
+	if !auth.verified {
+		return fmt.Errorf("%w: server accepted the login without returning a token",
+			krb5.ErrMutualAuthentication)
+	}
+
+	return nil
+}
+
+// newKerberosAuthenticator returns a KerberosAuthenticator configured
+// with the Kerberos options of info.
+func newKerberosAuthenticator(info *Info) (*KerberosAuthenticator, error) {
+	kdcs := []string{}
+	for _, kdc := range strings.Split(info.KerberosKDCs, ",") {
+		if kdc = strings.TrimSpace(kdc); kdc != "" {
+			kdcs = append(kdcs, kdc)
+		}
+	}
+
+	var cl *client.Client
+	if info.KerberosKeytab != "" {
+		name, realm := types.ParseSPNString(info.KerberosPrincipal)
+		if len(name.NameString) == 0 || name.NameString[0] == "" {
+			return nil, errors.New("krb5-principal is required with krb5-keytab")
+		}
+
+		kt, err := keytab.Load(info.KerberosKeytab)
+		if err != nil {
+			return nil, fmt.Errorf("error loading krb5-keytab: %w", err)
+		}
+
+		cfg, err := krb5.NewConfig(realm, kdcs...)
+		if err != nil {
+			return nil, err
+		}
+		if realm == "" {
+			realm = cfg.LibDefaults.DefaultRealm
+		}
+
+		cl = client.NewWithKeytab(name.PrincipalNameString(), realm, kt, cfg, client.DisablePAFXFAST(true))
+	} else {
+		path := info.KerberosCCache
+		if path == "" {
+			path = krb5.DefaultCCachePath()
+		}
+
+		ccache, err := credentials.LoadCCache(strings.TrimPrefix(path, "FILE:"))
+		if err != nil {
+			return nil, fmt.Errorf("error loading credential cache: %w", err)
+		}
+
+		cfg, err := krb5.NewConfig(ccache.GetClientRealm(), kdcs...)
+		if err != nil {
+			return nil, err
+		}
+
+		if cl, err = client.NewFromCCache(ccache, cfg, client.DisablePAFXFAST(true)); err != nil {
+			return nil, fmt.Errorf("error loading credential cache: %w", err)
+		}
+	}
+
+	// The realm of the service defaults to the realm of the client,
+	// unless the service principal name or the configuration map the
+	// host to another realm.
+	service, realm := types.ParseSPNString(info.KerberosServicePrincipal)
+	if len(service.NameString) == 0 || service.NameString[0] == "" {
+		return nil, errors.New("krb5-service-principal is required")
+	}
+
+	host := service.NameString[len(service.NameString)-1]
+	if realm != "" {
+		cl.Config.DomainRealm[host] = realm
+	} else if cl.Config.ResolveRealm(host) == "" {
+		cl.Config.DomainRealm[host] = cl.Credentials.Realm()
+	}
+
+	return NewKerberosAuthenticator(cl, service.PrincipalNameString()), nil
+}
+
+// loginAuthenticator completes the login with the security tokens of
+// config.Authenticator. The login payload and capabilities must have
+// been queued.
+func (tdsChan *Channel) loginAuthenticator(ctx context.Context, config *LoginConfig) error {
+	token, err := config.Authenticator.InitialToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	for {
+		if err := tdsChan.queueSecurityToken(ctx, token); err != nil {
+			return err
+		}
+
+		if err := tdsChan.SendRemainingPackets(ctx); err != nil {
+			return fmt.Errorf("error sending security token: %w", err)
+		}
+
+		pkg, err := tdsChan.nextLoginPackage(ctx)
+		if err != nil {
+			return fmt.Errorf("error reading LoginAck package: %w", err)
+		}
+
+		loginack, ok := pkg.(*LoginAckPackage)
+		if !ok {
+			return fmt.Errorf("expected LoginAck as first response, received: %v", pkg)
+		}
+
+		switch loginack.Status {
+		case TDS_LOG_SUCCEED:
+			return tdsChan.finishAuthenticatorLogin(ctx, config)
+		case TDS_LOG_NEGOTIATE:
+		default:
+			return tdsChan.loginFailed(ctx, loginack.Status)
+		}
+
+		serverToken, err := tdsChan.readSecurityToken(ctx)
+		if err != nil {
+			return err
+		}
+
+		pkg, err = tdsChan.nextLoginPackage(ctx)
+		if err != nil {
+			return fmt.Errorf("error reading Done package: %w", err)
+		}
+
+		if _, ok := pkg.(*DonePackage); !ok {
+			return fmt.Errorf("expected done package after security token, received: %v", pkg)
+		}
+
+		if token, err = config.Authenticator.Continue(ctx, serverToken); err != nil {
+			return err
+		}
+	}
+}
+
+// finishAuthenticatorLogin reads the response to an accepted login,
+// which may contain a final security token and the capabilities of the
+// server, and completes the authentication if config.Authenticator is
+// a CompletingAuthenticator.
+func (tdsChan *Channel) finishAuthenticatorLogin(ctx context.Context, config *LoginConfig) error {
+	for {
+		pkg, err := tdsChan.nextLoginPackage(ctx)
+		if err != nil {
+			return fmt.Errorf("error reading login response: %w", err)
+		}
+
+		switch typed := pkg.(type) {
+		case *MsgPackage:
+			if typed.MsgId != TDS_MSG_SEC_OPAQUE {
+				continue
+			}
+
+			token, err := tdsChan.readSecurityTokenParams(ctx)
+			if err != nil {
+				return err
+			}
+
+			if _, err := config.Authenticator.Continue(ctx, token); err != nil {
+				return err
+			}
+		case *CapabilityPackage:
+			tdsChan.tdsConn.Caps = typed
+		case *DonePackage:
+			if typed.Status&TDS_DONE_FINAL != TDS_DONE_FINAL {
+				return fmt.Errorf("expected done package with status TDS_DONE_FINAL, received %s",
+					typed.Status)
+			}
+
+			if auth, ok := config.Authenticator.(CompletingAuthenticator); ok {
+				if err := auth.Complete(ctx); err != nil {
+					return err
+				}
+			}
+
+			return tdsChan.finishLogin(ctx, config)
+		}
+	}
+}
+
+// queueSecurityToken queues a TDS_MSG_SEC_OPAQUE message with token.
+func (tdsChan *Channel) queueSecurityToken(ctx context.Context, token []byte) error {
+	if err := tdsChan.QueuePackage(ctx, NewMsgPackage(TDS_MSG_HASARGS, TDS_MSG_SEC_OPAQUE)); err != nil {
+		return fmt.Errorf("error queueing message package for security token: %w", err)
+	}
+
+	typeFmt, typeData, err := LookupFieldFmtData(asetypes.INT4)
+	if err != nil {
+		return fmt.Errorf("failed to look up fields for INT4: %w", err)
+	}
+	typeData.SetValue(int32(TDS_SEC_SECSESS))
+
+	tokenFmt, tokenData, err := LookupFieldFmtData(asetypes.LONGBINARY)
+	if err != nil {
+		return fmt.Errorf("failed to look up fields for LONGBINARY: %w", err)
+	}
+	tokenData.SetValue(token)
+
+	if err := tdsChan.QueuePackage(ctx, NewParamFmtPackage(false, typeFmt, tokenFmt)); err != nil {
+		return fmt.Errorf("error queueing ParamFmt package for security token: %w", err)
+	}
+
+	if err := tdsChan.QueuePackage(ctx, NewParamsPackage(typeData, tokenData)); err != nil {
+		return fmt.Errorf("error queueing Params package for security token: %w", err)
+	}
+
+	return nil
+}
+
+// readSecurityToken reads a TDS_MSG_SEC_OPAQUE message and returns its
+// token.
+func (tdsChan *Channel) readSecurityToken(ctx context.Context) ([]byte, error) {
+	pkg, err := tdsChan.nextLoginPackage(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error reading Msg package: %w", err)
+	}
+
+	msg, ok := pkg.(*MsgPackage)
+	if !ok || msg.MsgId != TDS_MSG_SEC_OPAQUE {
+		return nil, fmt.Errorf("expected msg package with %s, received: %v", TDS_MSG_SEC_OPAQUE, pkg)
+	}
+
+	return tdsChan.readSecurityTokenParams(ctx)
+}
+
+// readSecurityTokenParams reads the parameters of a TDS_MSG_SEC_OPAQUE
+// message and returns its token.
+func (tdsChan *Channel) readSecurityTokenParams(ctx context.Context) ([]byte, error) {
+	pkg, err := tdsChan.nextLoginPackage(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error reading ParamFmt package: %w", err)
+	}
+
+	if _, ok := pkg.(*ParamFmtPackage); !ok {
+		return nil, fmt.Errorf("expected paramfmt package, received: %v", pkg)
+	}
+
+	pkg, err = tdsChan.nextLoginPackage(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error reading Params package: %w", err)
+	}
+
+	params, ok := pkg.(*ParamsPackage)
+	if !ok {
+		return nil, fmt.Errorf("expected params package, received: %v", pkg)
+	}
+
+	return SecurityToken(params)
+}
+
+// SecurityToken returns the token of the parameters of
+// a TDS_MSG_SEC_OPAQUE message.
+func SecurityToken(params *ParamsPackage) ([]byte, error) {
+	if len(params.DataFields) != 2 {
+		return nil, fmt.Errorf("invalid security token, expected 2 fields, got %d: %v",
+			len(params.DataFields), params)
+	}
+
+	tokenType, ok := params.DataFields[0].Value().(int32)
+	if !ok {
+		return nil, fmt.Errorf("expected token type as first parameter, got: %#v", params.DataFields[0])
+	}
+
+	if TDSOpaqueSecurityToken(tokenType) != TDS_SEC_SECSESS {
+		return nil, fmt.Errorf("unhandled security token type %d", tokenType)
+	}
+
+	token, ok := params.DataFields[1].Value().([]byte)
+	if !ok {
+		return nil, fmt.Errorf("expected token as second parameter, got: %#v", params.DataFields[1])
+	}
+
+	return token, nil
+}
@@ -0,0 +1,302 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tds_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SAP/go-dblib/krb5"
+	"github.com/SAP/go-dblib/krb5/krb5test"
+	"github.com/SAP/go-dblib/tds"
+	"github.com/SAP/go-dblib/tds/tdstest"
+	"github.com/jcmturner/gokrb5/v8/client"
+	"github.com/jcmturner/gokrb5/v8/keytab"
+)
+
+func TestChannel_LoginKerberos(t *testing.T) {
+	kdc, err := krb5test.NewKDC("EXAMPLE.COM")
+	if err != nil {
+		t.Fatalf("error creating KDC: %v", err)
+	}
+	defer kdc.Close()
+
+	userKeytab, err := kdc.AddPrincipal("user")
+	if err != nil {
+		t.Fatalf("error adding user: %v", err)
+	}
+
+	serviceKeytab, err := kdc.AddPrincipal("ase/db.example.com")
+	if err != nil {
+		t.Fatalf("error adding service: %v", err)
+	}
+
+	ccache, err := kdc.CCache("user")
+	if err != nil {
+		t.Fatalf("error creating credential cache: %v", err)
+	}
+
+	otherKDC, err := krb5test.NewKDC("EXAMPLE.COM")
+	if err != nil {
+		t.Fatalf("error creating KDC: %v", err)
+	}
+	defer otherKDC.Close()
+
+	otherServiceKeytab, err := otherKDC.AddPrincipal("ase/db.example.com")
+	if err != nil {
+		t.Fatalf("error adding service: %v", err)
+	}
+
+	// forgedReply is a valid AP-REP token for a session the clients
+	// under test do not share.
+	otherUserKeytab, err := otherKDC.AddPrincipal("user")
+	if err != nil {
+		t.Fatalf("error adding user: %v", err)
+	}
+
+	otherConfig, err := krb5.NewConfig(otherKDC.Realm(), otherKDC.Addr().String())
+	if err != nil {
+		t.Fatalf("error creating configuration: %v", err)
+	}
+
+	otherClient := client.NewWithKeytab("user", otherKDC.Realm(), otherUserKeytab, otherConfig, client.DisablePAFXFAST(true))
+	otherToken, _, err := krb5.InitSecContext(otherClient, "ase/db.example.com")
+	if err != nil {
+		t.Fatalf("error initiating security context: %v", err)
+	}
+
+	_, forgedReply, err := krb5test.AcceptSecContext(otherServiceKeytab, otherToken)
+	if err != nil {
+		t.Fatalf("error accepting security context: %v", err)
+	}
+
+	userKeytabBs, err := userKeytab.Marshal()
+	if err != nil {
+		t.Fatalf("error marshaling keytab: %v", err)
+	}
+
+	dir := t.TempDir()
+	keytabPath := filepath.Join(dir, "user.keytab")
+	if err := os.WriteFile(keytabPath, userKeytabBs, 0o600); err != nil {
+		t.Fatalf("error writing keytab: %v", err)
+	}
+
+	ccachePath := filepath.Join(dir, "krb5cc")
+	if err := os.WriteFile(ccachePath, ccache, 0o600); err != nil {
+		t.Fatalf("error writing credential cache: %v", err)
+	}
+
+	accept := func(kt *keytab.Keytab) tdstest.AuthenticationFunc {
+		return func(username string, token []byte) ([]byte, bool, error) {
+			client, reply, err := krb5test.AcceptSecContext(kt, token)
+			if err != nil {
+				return nil, false, err
+			}
+
+			if client.PrincipalNameString() != "user" {
+				return nil, false, fmt.Errorf("unexpected client %s", client.PrincipalNameString())
+			}
+
+			return reply, true, nil
+		}
+	}
+
+	cases := map[string]struct {
+		setInfo func(*tds.Info)
+		fn      tdstest.AuthenticationFunc
+		err     bool
+		errIs   error
+	}{
+		"keytab": {
+			setInfo: func(info *tds.Info) {
+				info.KerberosPrincipal = "user@EXAMPLE.COM"
+				info.KerberosKeytab = keytabPath
+			},
+			fn: accept(serviceKeytab),
+		},
+		"credential cache": {
+			setInfo: func(info *tds.Info) {
+				info.KerberosCCache = ccachePath
+			},
+			fn: accept(serviceKeytab),
+		},
+		"wrong service key": {
+			setInfo: func(info *tds.Info) {
+				info.KerberosCCache = ccachePath
+			},
+			fn:  accept(otherServiceKeytab),
+			err: true,
+		},
+		"forged mutual authentication": {
+			setInfo: func(info *tds.Info) {
+				info.KerberosCCache = ccachePath
+			},
+			fn: func(username string, token []byte) ([]byte, bool, error) {
+				if _, _, err := krb5test.AcceptSecContext(serviceKeytab, token); err != nil {
+					return nil, false, err
+				}
+
+				// Reply with the AP-REP of another session.
+				return forgedReply, true, nil
+			},
+			err:   true,
+			errIs: krb5.ErrMutualAuthentication,
+		},
+		"missing mutual authentication": {
+			setInfo: func(info *tds.Info) {
+				info.KerberosCCache = ccachePath
+			},
+			fn: func(username string, token []byte) ([]byte, bool, error) {
+				if _, _, err := krb5test.AcceptSecContext(serviceKeytab, token); err != nil {
+					return nil, false, err
+				}
+
+				// Accept the login without an AP-REP.
+				return nil, true, nil
+			},
+			err:   true,
+			errIs: krb5.ErrMutualAuthentication,
+		},
+	}
+
+	for title, cas := range cases {
+		t.Run(title, func(t *testing.T) {
+			server, err := tdstest.NewServer("", "")
+			if err != nil {
+				t.Fatalf("error creating server: %v", err)
+			}
+			defer server.Close()
+
+			server.HandleAuthentication(cas.fn)
+
+			info, err := server.Info()
+			if err != nil {
+				t.Fatalf("error getting info: %v", err)
+			}
+			info.Username = "user"
+			info.KerberosServicePrincipal = "ase/db.example.com"
+			info.KerberosKDCs = kdc.Addr().String()
+			cas.setInfo(info)
+
+			err = login(info)
+			if cas.err {
+				if err == nil {
+					t.Fatalf("expected login to fail")
+				}
+				if cas.errIs != nil && !errors.Is(err, cas.errIs) {
+					t.Fatalf("expected error %v, received: %v", cas.errIs, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("error logging in: %v", err)
+			}
+		})
+	}
+}
+
+func TestChannel_LoginAuthenticator(t *testing.T) {
+	server, err := tdstest.NewServer("", "")
+	if err != nil {
+		t.Fatalf("error creating server: %v", err)
+	}
+	defer server.Close()
+
+	// The server requires two rounds, echoing the tokens of the
+	// client.
+	rounds := 0
+	server.HandleAuthentication(func(username string, token []byte) ([]byte, bool, error) {
+		rounds++
+		if !bytes.Equal(token, []byte(fmt.Sprintf("token %d", rounds))) {
+			return nil, false, fmt.Errorf("unexpected token %q", token)
+		}
+
+		return token, rounds == 2, nil
+	})
+
+	info, err := server.Info()
+	if err != nil {
+		t.Fatalf("error getting info: %v", err)
+	}
+
+	config, err := tds.NewLoginConfig(info)
+	if err != nil {
+		t.Fatalf("error creating login config: %v", err)
+	}
+
+	auth := &countingAuthenticator{}
+	config.Authenticator = auth
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	conn, err := tds.NewConn(ctx, info)
+	if err != nil {
+		t.Fatalf("error opening connection: %v", err)
+	}
+	defer conn.Close()
+
+	ch, err := conn.NewChannel()
+	if err != nil {
+		t.Fatalf("error opening channel: %v", err)
+	}
+
+	if err := ch.Login(ctx, config); err != nil {
+		t.Fatalf("error logging in: %v", err)
+	}
+
+	if len(auth.received) != 2 {
+		t.Fatalf("expected two tokens from server, received %q", auth.received)
+	}
+}
+
+// countingAuthenticator sends numbered tokens and records the tokens of
+// the server.
+type countingAuthenticator struct {
+	sent     int
+	received [][]byte
+}
+
+func (auth *countingAuthenticator) InitialToken(ctx context.Context) ([]byte, error) {
+	auth.sent = 1
+	return []byte("token 1"), nil
+}
+
+func (auth *countingAuthenticator) Continue(ctx context.Context, token []byte) ([]byte, error) {
+	auth.received = append(auth.received, token)
+	auth.sent++
+	return []byte(fmt.Sprintf("token %d", auth.sent)), nil
+}
+
+// login opens a connection with info and logs in.
+func login(info *tds.Info) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	config, err := tds.NewLoginConfig(info)
+	if err != nil {
+		return err
+	}
+
+	conn, err := tds.NewConn(ctx, info)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ch, err := conn.NewChannel()
+	if err != nil {
+		return err
+	}
+
+	return ch.Login(ctx, config)
+}
@@ -0,0 +1,331 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultBulkCopyBatchSize is the number of rows sent per batch if
+// BulkCopyOptions.BatchSize is not set.
+const DefaultBulkCopyBatchSize = 1000
+
+// ErrBulkCopyDone is returned when rows are added to a BulkCopy after
+// Done was called.
+var ErrBulkCopyDone = errors.New("bulk copy is already done")
+
+// identifierRe matches regular identifiers, which do not require
+// delimiters.
+var identifierRe = regexp.MustCompile(`^[\pL_@#][\pL\pN_@#$]*$`)
+
+// BulkCopyOptions configure a BulkCopy.
+type BulkCopyOptions struct {
+	// BatchSize is the number of rows sent to the server in a single
+	// bulk message. If BatchSize is 0 DefaultBulkCopyBatchSize is used.
+	BatchSize int
+	// CommitInterval is the number of rows after which the inserted rows
+	// are committed.
+	//
+	// If CommitInterval is 0 the rows are not wrapped in a transaction
+	// and each batch is committed by the server on its own.
+	CommitInterval int
+}
+
+// BulkCopy inserts rows into a table using the bulk copy protocol.
+//
+// Each batch is sent as an `insert bulk` command followed by
+// a TDS_BUF_BULK message containing the rows of the batch as
+// BulkRowPackages. The server acknowledges each batch with the number
+// of inserted rows.
+type BulkCopy struct {
+	tdsChan *Channel
+	table   string
+	opts    BulkCopyOptions
+	fmts    []FieldFmt
+
+	rows        []*BulkRowPackage
+	rowCount    int
+	uncommitted int
+	inTx        bool
+	done        bool
+}
+
+// NewBulkCopy returns a BulkCopy inserting rows into table.
+//
+// The table is given as [[database.]owner.]table, whose parts are
+// either regular or delimited identifiers - enclosed in brackets or
+// double quotes. The parts are always sent enclosed in brackets.
+//
+// The column formats of the table are fetched from the server.
+func (tdsChan *Channel) NewBulkCopy(ctx context.Context, table string, opts BulkCopyOptions) (*BulkCopy, error) {
+	if !tdsChan.tdsConn.Caps.HasRequestCapability(TDS_REQ_BCP) {
+		return nil, errors.New("server does not support bulk copy")
+	}
+
+	if opts.BatchSize < 0 || opts.CommitInterval < 0 {
+		return nil, fmt.Errorf("invalid bulk copy options: batch size %d, commit interval %d",
+			opts.BatchSize, opts.CommitInterval)
+	}
+
+	if opts.BatchSize == 0 {
+		opts.BatchSize = DefaultBulkCopyBatchSize
+	}
+
+	quoted, err := quoteTableName(table)
+	if err != nil {
+		return nil, err
+	}
+
+	bulk := &BulkCopy{
+		tdsChan: tdsChan,
+		table:   quoted,
+		opts:    opts,
+	}
+
+	err = tdsChan.execLanguage(ctx, "select * from "+quoted+" where 1 = 2", func(pkg Package) error {
+		if rowFmt, ok := pkg.(*RowFmtPackage); ok {
+			bulk.fmts = rowFmt.Fmts
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching column formats of table %s: %w", table, err)
+	}
+
+	if len(bulk.fmts) == 0 {
+		return nil, fmt.Errorf("received no column formats for table %s", table)
+	}
+
+	return bulk, nil
+}
+
+// Columns returns the formats of the columns of the table.
+func (bulk BulkCopy) Columns() []FieldFmt {
+	return bulk.fmts
+}
+
+// RowCount returns the number of rows inserted by the server so far.
+func (bulk BulkCopy) RowCount() int {
+	return bulk.rowCount
+}
+
+// AddRow queues a row for insertion. The values must be in the order of
+// the columns.
+//
+// The queued rows are sent to the server when the batch size or the
+// commit interval is reached.
+func (bulk *BulkCopy) AddRow(ctx context.Context, values ...interface{}) error {
+	if bulk.done {
+		return ErrBulkCopyDone
+	}
+
+	if len(values) != len(bulk.fmts) {
+		return fmt.Errorf("received %d values for %d columns", len(values), len(bulk.fmts))
+	}
+
+	data := make([]FieldData, len(values))
+	for i, value := range values {
+		var err error
+		data[i], err = LookupFieldData(bulk.fmts[i])
+		if err != nil {
+			return fmt.Errorf("error looking up field data for column %d (%s): %w",
+				i, bulk.fmts[i].DataType(), err)
+		}
+		data[i].SetValue(value)
+	}
+
+	// Rows are encoded when they are added so that invalid values are
+	// reported before the batch is sent.
+	row := NewBulkRowPackage(data...)
+	if err := row.encode(); err != nil {
+		return fmt.Errorf("error encoding row %d: %w", bulk.rowCount+len(bulk.rows), err)
+	}
+
+	bulk.rows = append(bulk.rows, row)
+
+	if len(bulk.rows) >= bulk.opts.BatchSize {
+		return bulk.Flush(ctx)
+	}
+
+	if bulk.opts.CommitInterval > 0 && bulk.uncommitted+len(bulk.rows) >= bulk.opts.CommitInterval {
+		return bulk.Flush(ctx)
+	}
+
+	return nil
+}
+
+// Flush sends the queued rows to the server and commits the inserted
+// rows if the commit interval was reached.
+func (bulk *BulkCopy) Flush(ctx context.Context) error {
+	if len(bulk.rows) == 0 {
+		return nil
+	}
+
+	if bulk.opts.CommitInterval > 0 && !bulk.inTx {
+		if err := bulk.tdsChan.execLanguage(ctx, "begin transaction", nil); err != nil {
+			return fmt.Errorf("error starting transaction: %w", err)
+		}
+		bulk.inTx = true
+	}
+
+	if err := bulk.tdsChan.execLanguage(ctx, "insert bulk "+bulk.table, nil); err != nil {
+		return fmt.Errorf("error starting bulk insert: %w", err)
+	}
+
+	count, err := bulk.sendBatch(ctx)
+	if err != nil {
+		return err
+	}
+
+	bulk.rowCount += count
+	bulk.uncommitted += count
+	bulk.rows = bulk.rows[:0]
+
+	if bulk.inTx && bulk.uncommitted >= bulk.opts.CommitInterval {
+		return bulk.commit(ctx)
+	}
+
+	return nil
+}
+
+// Done sends the remaining rows, commits the inserted rows and returns
+// the number of inserted rows.
+func (bulk *BulkCopy) Done(ctx context.Context) (int, error) {
+	if bulk.done {
+		return bulk.rowCount, nil
+	}
+
+	if err := bulk.Flush(ctx); err != nil {
+		return bulk.rowCount, err
+	}
+
+	if bulk.inTx {
+		if err := bulk.commit(ctx); err != nil {
+			return bulk.rowCount, err
+		}
+	}
+
+	bulk.done = true
+	return bulk.rowCount, nil
+}
+
+// sendBatch sends the queued rows in a bulk message and returns the
+// number of rows inserted by the server.
+func (bulk *BulkCopy) sendBatch(ctx context.Context) (int, error) {
+	tdsChan := bulk.tdsChan
+	tdsChan.CurrentHeaderType = TDS_BUF_BULK
+
+	for i, row := range bulk.rows {
+		if err := tdsChan.QueuePackage(ctx, row); err != nil {
+			tdsChan.Reset()
+			return 0, fmt.Errorf("error queueing row %d: %w", bulk.rowCount+i, err)
+		}
+	}
+
+	if err := tdsChan.SendRemainingPackets(ctx); err != nil {
+		return 0, fmt.Errorf("error sending bulk rows: %w", err)
+	}
+
+	count := 0
+	_, err := tdsChan.NextPackageUntil(ctx, true, func(pkg Package) (bool, error) {
+		done, ok := pkg.(*DonePackage)
+		if !ok {
+			return false, nil
+		}
+
+		if done.Status&TDS_DONE_ERROR == TDS_DONE_ERROR {
+			return false, errDoneError
+		}
+
+		if done.Status&TDS_DONE_COUNT == TDS_DONE_COUNT {
+			count += int(done.Count)
+		}
+
+		return isDoneFinal(done)
+	})
+	if err != nil {
+		return count, fmt.Errorf("error inserting bulk rows: %w", err)
+	}
+
+	return count, nil
+}
+
+func (bulk *BulkCopy) commit(ctx context.Context) error {
+	if err := bulk.tdsChan.execLanguage(ctx, "commit transaction", nil); err != nil {
+		return fmt.Errorf("error committing bulk rows: %w", err)
+	}
+
+	bulk.inTx = false
+	bulk.uncommitted = 0
+	return nil
+}
+
+// quoteTableName validates table as [[database.]owner.]table and
+// returns it with all parts enclosed in brackets.
+func quoteTableName(table string) (string, error) {
+	parts := []string{}
+	for rest := table; ; {
+		var part string
+		switch {
+		case strings.HasPrefix(rest, "["), strings.HasPrefix(rest, `"`):
+			delim := "]"
+			if rest[0] == '"' {
+				delim = `"`
+			}
+
+			end := strings.Index(rest[1:], delim)
+			if end < 0 {
+				return "", fmt.Errorf("invalid table name %q: unterminated delimited identifier", table)
+			}
+			part, rest = rest[1:end+1], rest[end+2:]
+
+			if part == "" || strings.ContainsAny(part, "[]") {
+				return "", fmt.Errorf("invalid table name %q: invalid delimited identifier %q", table, part)
+			}
+		default:
+			end := strings.IndexByte(rest, '.')
+			if end < 0 {
+				end = len(rest)
+			}
+			part, rest = rest[:end], rest[end:]
+
+			if part != "" && !identifierRe.MatchString(part) {
+				return "", fmt.Errorf("invalid table name %q: invalid identifier %q", table, part)
+			}
+		}
+
+		parts = append(parts, part)
+
+		if rest == "" {
+			break
+		}
+		if rest[0] != '.' {
+			return "", fmt.Errorf("invalid table name %q: expected '.' after identifier %q", table, part)
+		}
+		rest = rest[1:]
+	}
+
+	if len(parts) > 3 {
+		return "", fmt.Errorf("invalid table name %q: expected at most three parts, received %d", table, len(parts))
+	}
+
+	for i, part := range parts {
+		// Only the owner may be omitted, and only if the database is
+		// given.
+		if part == "" && !(len(parts) == 3 && i == 1) {
+			return "", fmt.Errorf("invalid table name %q: empty identifier", table)
+		}
+
+		if part != "" {
+			parts[i] = "[" + part + "]"
+		}
+	}
+
+	return strings.Join(parts, "."), nil
+}
@@ -0,0 +1,227 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tds_test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SAP/go-dblib/asetypes"
+	"github.com/SAP/go-dblib/tds"
+	"github.com/SAP/go-dblib/tds/tdstest"
+)
+
+func TestBulkCopy(t *testing.T) {
+	server, err := tdstest.NewServer("user", "pass")
+	if err != nil {
+		t.Fatalf("error creating server: %v", err)
+	}
+	defer server.Close()
+
+	// Count the transaction commands per table.
+	lock := &sync.Mutex{}
+	commands := map[string]int{}
+	server.HandleFunc(func(request []tds.Package) ([]tds.Package, bool) {
+		lang, ok := request[0].(*tds.LanguagePackage)
+		if !ok || (lang.Cmd != "begin transaction" && lang.Cmd != "commit transaction") {
+			return nil, false
+		}
+
+		lock.Lock()
+		commands[lang.Cmd]++
+		lock.Unlock()
+
+		return []tds.Package{&tds.DonePackage{Status: tds.TDS_DONE_FINAL}}, true
+	})
+
+	rows := make([][]interface{}, 5)
+	for i := range rows {
+		rows[i] = []interface{}{int32(i), fmt.Sprintf("row %d", i)}
+	}
+
+	cases := map[string]struct {
+		table   string
+		opts    tds.BulkCopyOptions
+		commits int
+	}{
+		"default": {},
+		"batches": {
+			opts: tds.BulkCopyOptions{BatchSize: 2},
+		},
+		"commit interval": {
+			opts:    tds.BulkCopyOptions{BatchSize: 2, CommitInterval: 3},
+			commits: 2,
+		},
+		"delimited identifiers": {
+			table: `db.."bulk test"`,
+		},
+	}
+
+	for title, cas := range cases {
+		t.Run(title, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			lock.Lock()
+			commands = map[string]int{}
+			lock.Unlock()
+
+			// Registering the table resets its rows.
+			table := "bulk_test"
+			registered := table
+			if cas.table != "" {
+				table = cas.table
+				registered = "db..bulk test"
+			}
+			server.HandleBulk(registered,
+				tdstest.Column{Name: "id", DataType: asetypes.INT4},
+				tdstest.Column{Name: "name", DataType: asetypes.VARCHAR},
+			)
+
+			conn, ch, err := server.Connect(ctx)
+			if err != nil {
+				t.Fatalf("error connecting: %v", err)
+			}
+			defer conn.Close()
+
+			bulk, err := ch.NewBulkCopy(ctx, table, cas.opts)
+			if err != nil {
+				t.Fatalf("error creating bulk copy: %v", err)
+			}
+
+			if len(bulk.Columns()) != 2 {
+				t.Fatalf("expected 2 columns, received %d", len(bulk.Columns()))
+			}
+
+			for _, row := range rows {
+				if err := bulk.AddRow(ctx, row...); err != nil {
+					t.Fatalf("error adding row: %v", err)
+				}
+			}
+
+			count, err := bulk.Done(ctx)
+			if err != nil {
+				t.Fatalf("error finishing bulk copy: %v", err)
+			}
+
+			if count != len(rows) {
+				t.Errorf("expected row count %d, received %d", len(rows), count)
+			}
+
+			if received := server.BulkRows(registered); !reflect.DeepEqual(received, rows) {
+				t.Errorf("received unexpected rows:\nexpected: %v\nreceived: %v", rows, received)
+			}
+
+			lock.Lock()
+			defer lock.Unlock()
+			if commands["begin transaction"] != cas.commits || commands["commit transaction"] != cas.commits {
+				t.Errorf("expected %d transactions, received %v", cas.commits, commands)
+			}
+
+			if err := bulk.AddRow(ctx, rows[0]...); err == nil {
+				t.Errorf("expected error adding row after Done")
+			}
+		})
+	}
+}
+
+func TestBulkCopy_NullableColumns(t *testing.T) {
+	server, err := tdstest.NewServer("user", "pass")
+	if err != nil {
+		t.Fatalf("error creating server: %v", err)
+	}
+	defer server.Close()
+
+	server.HandleBulk("bulk_test",
+		tdstest.Column{Name: "id", DataType: asetypes.INT4},
+		tdstest.Column{Name: "flag", DataType: asetypes.BIT},
+		tdstest.Column{Name: "name", DataType: asetypes.VARCHAR, Nullable: true},
+		tdstest.Column{Name: "amount", DataType: asetypes.INTN, Nullable: true},
+		tdstest.Column{Name: "note", DataType: asetypes.TEXT, Nullable: true},
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	conn, ch, err := server.Connect(ctx)
+	if err != nil {
+		t.Fatalf("error connecting: %v", err)
+	}
+	defer conn.Close()
+
+	bulk, err := ch.NewBulkCopy(ctx, "bulk_test", tds.BulkCopyOptions{})
+	if err != nil {
+		t.Fatalf("error creating bulk copy: %v", err)
+	}
+
+	if err := bulk.AddRow(ctx, nil, true, "a", int32(1), "note"); err == nil {
+		t.Errorf("expected error adding NULL to column without NULL")
+	}
+
+	rows := [][]interface{}{
+		{int32(1), true, "a", int32(10), "first note"},
+		{int32(2), false, nil, nil, nil},
+		{int32(3), true, strings.Repeat("b", 200), int32(30), strings.Repeat("c", 1000)},
+	}
+
+	for _, row := range rows {
+		if err := bulk.AddRow(ctx, row...); err != nil {
+			t.Fatalf("error adding row: %v", err)
+		}
+	}
+
+	count, err := bulk.Done(ctx)
+	if err != nil {
+		t.Fatalf("error finishing bulk copy: %v", err)
+	}
+
+	if count != len(rows) {
+		t.Errorf("expected row count %d, received %d", len(rows), count)
+	}
+
+	if received := server.BulkRows("bulk_test"); !reflect.DeepEqual(received, rows) {
+		t.Errorf("received unexpected rows:\nexpected: %v\nreceived: %v", rows, received)
+	}
+}
+
+func TestChannel_NewBulkCopyInvalidTable(t *testing.T) {
+	server, err := tdstest.NewServer("user", "pass")
+	if err != nil {
+		t.Fatalf("error creating server: %v", err)
+	}
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	conn, ch, err := server.Connect(ctx)
+	if err != nil {
+		t.Fatalf("error connecting: %v", err)
+	}
+	defer conn.Close()
+
+	cases := map[string]string{
+		"empty":                 "",
+		"injection":             "t where 1 = 2; drop table t --",
+		"unterminated":          "[bulk test",
+		"bracket in identifier": "[bulk]]test]",
+		"empty owner":           ".bulk_test",
+		"empty table":           "dbo.",
+		"too many parts":        "a.b.c.d",
+	}
+
+	for title, table := range cases {
+		t.Run(title, func(t *testing.T) {
+			if _, err := ch.NewBulkCopy(ctx, table, tds.BulkCopyOptions{}); err == nil {
+				t.Errorf("expected error for table name %q", table)
+			}
+		})
+	}
+}
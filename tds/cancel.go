@@ -0,0 +1,113 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Cancel sends an attention to the server to cancel the request being
+// processed on the channel and discards all packages until the server
+// acknowledged the attention. The channel can be reused afterwards.
+//
+// Attentions are only sent if the server supports non-expedited
+// attentions. Expedited attentions are deliberately out of scope: they
+// are sent as urgent data, which the net package provides no means to
+// send, hence TDS_CON_OOB is not requested during the login.
+//
+// Cancel must not be called concurrently with NextPackage - instead
+// the context passed to NextPackage can be closed, which cancels the
+// request automatically.
+func (tdsChan *Channel) Cancel(ctx context.Context) error {
+	if !tdsChan.tdsConn.Caps.HasRequestCapability(TDS_CON_INBAND) {
+		return errors.New("server does not support attentions")
+	}
+
+	if err := tdsChan.sendAttention(); err != nil {
+		return err
+	}
+
+	// Discard packages until the acknowledgement. If the DonePackage
+	// acknowledging the attention has TDS_DONE_MORE set it is followed
+	// by a final DonePackage, which must be consumed as well.
+	acknowledged := false
+	for {
+		pkg, err := tdsChan.nextPackage(ctx, true)
+		if err != nil {
+			return fmt.Errorf("error waiting for acknowledgement of attention: %w", err)
+		}
+
+		done, ok := pkg.(*DonePackage)
+		if !ok {
+			continue
+		}
+
+		if isAttentionDone(done) {
+			break
+		}
+
+		if done.Status&TDS_DONE_ATTN == TDS_DONE_ATTN {
+			acknowledged = true
+			continue
+		}
+
+		if final, _ := isDoneFinal(done); acknowledged && final {
+			break
+		}
+	}
+
+	tdsChan.requestPending.Store(false)
+	return nil
+}
+
+// isAttentionDone returns true if done acknowledges an attention and
+// is not followed by further packages.
+func isAttentionDone(done *DonePackage) bool {
+	return done.Status&TDS_DONE_ATTN == TDS_DONE_ATTN && done.Status&TDS_DONE_MORE == 0
+}
+
+// sendAttention sends an attention packet to the server.
+func (tdsChan *Channel) sendAttention() error {
+	tdsChan.RLock()
+	defer tdsChan.RUnlock()
+	if tdsChan.closed {
+		return ErrChannelClosed
+	}
+
+	// Discard packets that were queued but not sent.
+	defer tdsChan.Reset()
+
+	attention := NewPacket(PacketHeaderSize)
+	attention.Header.Length = PacketHeaderSize
+	attention.Data = nil
+
+	tdsChan.CurrentHeaderType = TDS_BUF_ATTN
+	if err := tdsChan.sendPacket(attention); err != nil {
+		return fmt.Errorf("error sending attention: %w", err)
+	}
+
+	return nil
+}
+
+// defaultCancelTimeout is the time to wait for the acknowledgement of
+// an attention if no PacketReadTimeout is set.
+const defaultCancelTimeout = 50 * time.Second
+
+// cancelAfterContext cancels the current request after the context of
+// a call was closed.
+func (tdsChan *Channel) cancelAfterContext() error {
+	timeout := tdsChan.tdsConn.info.PacketReadTimeout
+	if timeout <= 0 {
+		timeout = defaultCancelTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	return tdsChan.Cancel(ctx)
+}
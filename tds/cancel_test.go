@@ -0,0 +1,181 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tds_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SAP/go-dblib/asetypes"
+	"github.com/SAP/go-dblib/tds"
+	"github.com/SAP/go-dblib/tds/tdstest"
+)
+
+func TestChannel_Cancel(t *testing.T) {
+	server, err := tdstest.NewServer("user", "pass")
+	if err != nil {
+		t.Fatalf("error creating server: %v", err)
+	}
+	defer server.Close()
+
+	columns := []tdstest.Column{{Name: "a", DataType: asetypes.INT4}}
+
+	rows := make([][]interface{}, 100)
+	for i := range rows {
+		rows[i] = []interface{}{int32(i)}
+	}
+	large, err := tdstest.Result(columns, rows...)
+	if err != nil {
+		t.Fatalf("error creating result: %v", err)
+	}
+	server.HandleLanguage("select a from large", large...)
+
+	single, err := tdstest.Result(columns, []interface{}{int32(1)})
+	if err != nil {
+		t.Fatalf("error creating result: %v", err)
+	}
+	server.HandleLanguage("select 1 as a", single...)
+
+	// Not answered by the server
+	server.HandleLanguage("waitfor delay '01:00:00'")
+
+	cases := map[string]struct {
+		cmd    string
+		info   func(*tds.Info)
+		cancel func(context.Context, *tds.Channel) error
+	}{
+		"pending request": {
+			cmd: "waitfor delay '01:00:00'",
+			cancel: func(ctx context.Context, ch *tds.Channel) error {
+				return ch.Cancel(ctx)
+			},
+		},
+		"partially read result": {
+			cmd: "select a from large",
+			cancel: func(ctx context.Context, ch *tds.Channel) error {
+				if _, err := ch.NextPackage(ctx, true); err != nil {
+					return err
+				}
+				return ch.Cancel(ctx)
+			},
+		},
+		"context closed": {
+			cmd: "waitfor delay '01:00:00'",
+			cancel: func(ctx context.Context, ch *tds.Channel) error {
+				nextCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+				defer cancel()
+
+				return nextPackageDeadline(nextCtx, ch)
+			},
+		},
+		"context closed without packet read timeout": {
+			cmd: "waitfor delay '01:00:00'",
+			info: func(info *tds.Info) {
+				info.PacketReadTimeout = 0
+			},
+			cancel: func(ctx context.Context, ch *tds.Channel) error {
+				nextCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+				defer cancel()
+
+				return nextPackageDeadline(nextCtx, ch)
+			},
+		},
+	}
+
+	for title, cas := range cases {
+		t.Run(title, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			info, err := server.Info()
+			if err != nil {
+				t.Fatalf("error creating info: %v", err)
+			}
+
+			if cas.info != nil {
+				cas.info(info)
+			}
+
+			conn, ch, err := server.ConnectInfo(ctx, info)
+			if err != nil {
+				t.Fatalf("error connecting: %v", err)
+			}
+			defer conn.Close()
+
+			if err := ch.SendPackage(ctx, &tds.LanguagePackage{Cmd: cas.cmd}); err != nil {
+				t.Fatalf("error sending request: %v", err)
+			}
+
+			if err := cas.cancel(ctx, ch); err != nil {
+				t.Fatalf("error cancelling request: %v", err)
+			}
+
+			// The channel must be reusable after the cancellation.
+			if err := ch.SendPackage(ctx, &tds.LanguagePackage{Cmd: "select 1 as a"}); err != nil {
+				t.Fatalf("error sending request: %v", err)
+			}
+
+			values := [][]interface{}{}
+			_, err = ch.NextPackageUntil(ctx, true, func(pkg tds.Package) (bool, error) {
+				switch typed := pkg.(type) {
+				case *tds.RowPackage:
+					values = append(values, []interface{}{typed.DataFields[0].Value()})
+				case *tds.DonePackage:
+					return typed.Status == tds.TDS_DONE_FINAL, nil
+				}
+				return false, nil
+			})
+			if err != nil {
+				t.Fatalf("error reading result: %v", err)
+			}
+
+			if expected := [][]interface{}{{int32(1)}}; !reflect.DeepEqual(values, expected) {
+				t.Errorf("received unexpected rows:\nexpected: %v\nreceived: %v", expected, values)
+			}
+		})
+	}
+}
+
+// nextPackageDeadline waits for a package until ctx is closed and
+// returns an error if the request could not be cancelled afterwards.
+func nextPackageDeadline(ctx context.Context, ch *tds.Channel) error {
+	_, err := ch.NextPackage(ctx, true)
+	if !errors.Is(err, context.DeadlineExceeded) || strings.Contains(err.Error(), "error cancelling request") {
+		return err
+	}
+	return nil
+}
+
+func TestChannel_NextPackageWithoutRequest(t *testing.T) {
+	server, err := tdstest.NewServer("user", "pass")
+	if err != nil {
+		t.Fatalf("error creating server: %v", err)
+	}
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	conn, ch, err := server.Connect(ctx)
+	if err != nil {
+		t.Fatalf("error connecting: %v", err)
+	}
+	defer conn.Close()
+
+	nextCtx, nextCancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer nextCancel()
+
+	if _, err := ch.NextPackage(nextCtx, true); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected error %v, received: %v", context.DeadlineExceeded, err)
+	}
+
+	if attentions := server.Attentions(); attentions != 0 {
+		t.Errorf("expected no attention without a pending request, server received %d", attentions)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"log"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/hashicorp/go-multierror"
@@ -52,14 +53,61 @@ type Channel struct {
 	// window is the amount of buffers transmitted between ACKs
 	window int
 
+	// loggingIn is set during the login, whose packages carry
+	// credentials.
+	loggingIn bool
+	// passwordExpired is the EED reporting that the password of the
+	// login has expired.
+	passwordExpired *EEDPackage
+	// loginAck is the LoginAck accepting the last login.
+	loginAck *LoginAckPackage
+	// redactTx/Rx redact credentials from packages logged with
+	// Info.DebugLogPackages.
+	redactTx, redactRx packageRedactor
+
 	// queues store unconsumed Packets
 	queueRx, queueTx *PacketQueue
 	// lastPkgRx/Tx are the last packages sent to/received from the TDS
 	// server
 	lastPkgRx, lastPkgTx Package
+	// rowFmtRx is the row format the next response is parsed with,
+	// taken over as lastPkgRx by the goroutine parsing packages. It is
+	// set with the channel's write lock.
+	rowFmtRx *RowFmtPackage
 	// packageCh stores Packages as they are parsed from Packets
 	packageCh chan Package
 
+	// event is the event notification currently being received.
+	event          *Event
+	eventLastPkgRx Package
+	// rxEventOnly is true if only event notifications were received
+	// since the last package passed to packageCh.
+	rxEventOnly bool
+
+	// requestPending is true from sending a request until the response
+	// was received completely.
+	requestPending atomic.Bool
+	// haFailoverMsg is true while receiving the parameters of
+	// a TDS_MSG_HAFAILOVER message.
+	haFailoverMsg bool
+	// migrationMsg is true while receiving the parameters of
+	// a TDS_MSG_MIG_REQ message, migrationLastPkgRx is the last package
+	// received before the message.
+	migrationMsg       bool
+	migrationLastPkgRx Package
+	// resuming is set for the channel resuming the session after
+	// a failover or migration, which is not blocked by the reconnect
+	// in progress.
+	resuming bool
+	// lost is set if the logical channel could not be set up again
+	// after a failover or migration.
+	lost atomic.Pointer[ChannelLostError]
+
+	// lobStream is the row whose fields are currently being streamed
+	// and lobStreamRx is the last row with streamed fields returned by
+	// NextPackage.
+	lobStream, lobStreamRx *lobStream
+
 	errCh chan error
 }
 
@@ -71,21 +119,7 @@ func (tds *Conn) NewChannel() (*Channel, error) {
 		return nil, fmt.Errorf("error getting channel ID: %w", err)
 	}
 
-	tdsChan := &Channel{
-		tdsConn:            tds,
-		channelId:          channelId,
-		envChangeHooks:     []EnvChangeHook{},
-		envChangeHooksLock: &sync.Mutex{},
-		eedHooks:           []EEDHook{},
-		eedHooksLock:       &sync.Mutex{},
-		CurrentHeaderType:  TDS_BUF_NORMAL,
-		window:             0, // TODO
-		queueRx:            NewPacketQueue(tds.PacketSize),
-		queueTx:            NewPacketQueue(tds.PacketSize),
-		packageCh:          make(chan Package, tds.info.ChannelPackageQueueSize),
-		errCh:              make(chan error, 10),
-	}
-
+	tdsChan := tds.newChannel(channelId)
 	tds.tdsChannels[channelId] = tdsChan
 
 	// channel 0 needs no setup
@@ -93,34 +127,61 @@ func (tds *Conn) NewChannel() (*Channel, error) {
 		return tdsChan, nil
 	}
 
-	// Send packets to setup logical channel
+	if err := tdsChan.setup(context.Background()); err != nil {
+		return nil, err
+	}
+
+	return tdsChan, nil
+}
+
+// setup communicates the creation of the logical channel to the
+// server and waits for the acknowledgement.
+func (tdsChan *Channel) setup(ctx context.Context) error {
 	setup := NewPacket(PacketHeaderSize)
 	setup.Header.Length = PacketHeaderSize
 	setup.Data = nil
 
 	tdsChan.CurrentHeaderType = TDS_BUF_SETUP
 	if err := tdsChan.sendPacket(setup); err != nil {
-		return nil, fmt.Errorf("error sending setup for channel %d: %w",
+		return fmt.Errorf("error sending setup for channel %d: %w",
 			tdsChan.channelId, err)
 	}
 
-	pkg, err := tdsChan.NextPackage(context.Background(), true)
+	pkg, err := tdsChan.NextPackage(ctx, true)
 	if err != nil {
-		return nil, fmt.Errorf("error receiving ack for channel setup: %w", err)
+		return fmt.Errorf("error receiving ack for channel setup: %w", err)
 	}
 
 	header, ok := pkg.(*HeaderOnlyPackage)
 	if !ok {
-		return nil, fmt.Errorf("did not received expected header-only packet: %v", pkg)
+		return fmt.Errorf("did not received expected header-only packet: %v", pkg)
 	}
 
 	if header.Header.MsgType&TDS_BUF_PROTACK != TDS_BUF_PROTACK {
-		return nil, fmt.Errorf("did not receive protack in header-only packet: %s",
+		return fmt.Errorf("did not receive protack in header-only packet: %s",
 			header)
 	}
 
 	tdsChan.Reset()
-	return tdsChan, nil
+	return nil
+}
+
+// newChannel returns an initialized Channel with the passed ID.
+func (tds *Conn) newChannel(channelId int) *Channel {
+	return &Channel{
+		tdsConn:            tds,
+		channelId:          channelId,
+		envChangeHooks:     []EnvChangeHook{},
+		envChangeHooksLock: &sync.Mutex{},
+		eedHooks:           []EEDHook{},
+		eedHooksLock:       &sync.Mutex{},
+		CurrentHeaderType:  TDS_BUF_NORMAL,
+		window:             0, // TODO
+		queueRx:            NewPacketQueue(tds.PacketSize),
+		queueTx:            NewPacketQueue(tds.PacketSize),
+		packageCh:          make(chan Package, tds.info.ChannelPackageQueueSize),
+		errCh:              make(chan error, 10),
+	}
 }
 
 // Reset resets the Channel after a communication has been completed.
@@ -136,6 +197,19 @@ func (tdsChan *Channel) Reset() {
 	tdsChan.lastPkgTx = nil
 }
 
+// resetRx discards the received data of a response that was
+// interrupted by a failover.
+func (tdsChan *Channel) resetRx() {
+	tdsChan.queueRx.Reset()
+	tdsChan.lastPkgRx = nil
+	tdsChan.rowFmtRx = nil
+	tdsChan.event = nil
+	tdsChan.haFailoverMsg = false
+	tdsChan.migrationMsg = false
+	tdsChan.migrationLastPkgRx = nil
+	tdsChan.lobStream = nil
+}
+
 // Close communicates the termination of the channel with the TDS
 // server.
 //
@@ -152,7 +226,7 @@ func (tdsChan *Channel) Close() error {
 		if err := tdsChan.Logout(); err != nil {
 			me = multierror.Append(me, fmt.Errorf("error in logout sequence: %w", err))
 		}
-	} else {
+	} else if tdsChan.lost.Load() == nil {
 		// Closing of logical channels must be communicated using
 		// header-only packets
 
@@ -206,6 +280,12 @@ func (tdsChan *Channel) Close() error {
 
 // Logout performs the logout sequence.
 func (tdsChan *Channel) Logout() error {
+	if tdsChan.channelId == 0 {
+		// The server closes the connection after the logout, which
+		// must not be mistaken for a failover.
+		tdsChan.tdsConn.closing.Store(true)
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
 	defer cancel()
 
@@ -236,8 +316,24 @@ func (tdsChan *Channel) Logout() error {
 // skipped.
 // An error is returned if the handling errored.
 func (tdsChan *Channel) handleSpecialPackage(pkg Package) (bool, error) {
+	if isEvent, err := tdsChan.handleEventPackage(pkg); isEvent {
+		return false, err
+	}
+
+	if tdsChan.handleHAFailoverPackage(pkg) {
+		return false, nil
+	}
+
+	if tdsChan.handleMigrationPackage(pkg) {
+		return false, nil
+	}
+
 	if envChange, ok := pkg.(*EnvChangePackage); ok {
 		for _, member := range envChange.members {
+			if member.Type == TDS_ENV_DB {
+				tdsChan.tdsConn.setDatabase(member.NewValue)
+			}
+
 			if member.Type == TDS_ENV_PACKSIZE {
 				packSize, err := strconv.Atoi(member.NewValue)
 				if err != nil {
@@ -252,6 +348,15 @@ func (tdsChan *Channel) handleSpecialPackage(pkg Package) (bool, error) {
 		return false, nil
 	}
 
+	switch typed := pkg.(type) {
+	case *RowPackage:
+		tdsChan.bindLobLocators(typed.DataFields)
+	case *ParamsPackage:
+		tdsChan.bindLobLocators(typed.DataFields)
+	case *ReturnValuePackage:
+		tdsChan.bindLobLocators([]FieldData{typed.Data})
+	}
+
 	if eed, ok := pkg.(*EEDPackage); ok {
 		// TDS_EED_INFO packages are not supposed to leave the client
 		// library.
@@ -274,6 +379,15 @@ func (tdsChan *Channel) SetLastPkgRx(pkg Package) {
 	tdsChan.lastPkgRx = pkg
 }
 
+// setRowFmtRx hands rowFmt over to the goroutine parsing packages,
+// which parses the next response with it. It must be called before the
+// request is sent.
+func (tdsChan *Channel) setRowFmtRx(rowFmt *RowFmtPackage) {
+	tdsChan.Lock()
+	defer tdsChan.Unlock()
+	tdsChan.rowFmtRx = rowFmt
+}
+
 func (tdsChan *Channel) SetLastPkgTx(pkg Package) {
 	// Write lock needs to be used to prevent data races being detected
 	// despite data races not being possible.
@@ -292,7 +406,21 @@ func (tdsChan *Channel) SetLastPkgTx(pkg Package) {
 //
 // If multiple errors and a package are ready a random error or package
 // will be returned, as stated in the spec for select.
+//
+// If the passed context is closed while a request is being processed
+// by the server the request is cancelled with Cancel.
 func (tdsChan *Channel) NextPackage(ctx context.Context, wait bool) (Package, error) {
+	pkg, err := tdsChan.nextPackage(ctx, wait)
+	if err != nil && ctx != nil && ctx.Err() != nil && errors.Is(err, ctx.Err()) && tdsChan.requestPending.Load() {
+		if cancelErr := tdsChan.cancelAfterContext(); cancelErr != nil {
+			return nil, fmt.Errorf("%w; error cancelling request: %v", err, cancelErr)
+		}
+	}
+
+	return pkg, err
+}
+
+func (tdsChan *Channel) nextPackage(ctx context.Context, wait bool) (Package, error) {
 	tdsChan.RLock()
 	defer tdsChan.RUnlock()
 
@@ -300,12 +428,18 @@ func (tdsChan *Channel) NextPackage(ctx context.Context, wait bool) (Package, er
 		return nil, ErrChannelClosed
 	}
 
+	// Discard the unread data of the fields of the last row.
+	if tdsChan.lobStreamRx != nil {
+		tdsChan.lobStreamRx.closeReaders()
+		tdsChan.lobStreamRx = nil
+	}
+
 	// Try reading from the package channel once before setting up
 	// a loop. This prevents spurious errors due to random selection in
 	// select statements.
 	select {
 	case pkg := <-tdsChan.packageCh:
-		return pkg, nil
+		return tdsChan.receivedPackage(pkg), nil
 	default:
 	}
 
@@ -333,12 +467,22 @@ func (tdsChan *Channel) NextPackage(ctx context.Context, wait bool) (Package, er
 		return nil, fmt.Errorf("error in TDS channel %d: %w",
 			tdsChan.channelId, err)
 	case pkg := <-tdsChan.packageCh:
-		return pkg, nil
+		return tdsChan.receivedPackage(pkg), nil
 	case err := <-ch:
 		return nil, err
 	}
 }
 
+// receivedPackage records pkg if it is a row whose fields may be
+// streamed. Only lobStream is checked as the goroutine receiving
+// packages may still be reading the fields of the row.
+func (tdsChan *Channel) receivedPackage(pkg Package) Package {
+	if row, ok := pkg.(*RowPackage); ok && row.lobStream != nil {
+		tdsChan.lobStreamRx = row.lobStream
+	}
+	return pkg
+}
+
 // NextPackageUntil calls NextPackage until the passed function
 // processPkg returns true.
 //
@@ -463,6 +607,10 @@ func (tdsChan *Channel) QueuePackage(ctx context.Context, pkg Package) error {
 		return ErrChannelClosed
 	}
 
+	if err := tdsChan.checkWrite(pkg); err != nil {
+		return err
+	}
+
 	if acceptor, ok := pkg.(LastPkgAcceptor); ok {
 		if err := acceptor.LastPkg(tdsChan.lastPkgTx); err != nil {
 			return fmt.Errorf("error calling LastPkg on %s: %w", pkg, err)
@@ -473,7 +621,7 @@ func (tdsChan *Channel) QueuePackage(ctx context.Context, pkg Package) error {
 		return fmt.Errorf("error queueing packets from package %s: %w", pkg, err)
 	}
 	if tdsChan.tdsConn.info.DebugLogPackages {
-		log.Printf("TX: %s", pkg)
+		log.Printf("TX: %s", tdsChan.redactTx.format(pkg, tdsChan.loggingIn))
 	}
 	tdsChan.lastPkgTx = pkg
 
@@ -509,6 +657,16 @@ func (tdsChan *Channel) SendPackage(ctx context.Context, pkg Package) error {
 func (tdsChan *Channel) sendPackets(ctx context.Context, onlyFull bool) error {
 	defer tdsChan.queueTx.DiscardUntilCurrentPosition()
 
+	if !tdsChan.resuming {
+		if err := tdsChan.tdsConn.waitReconnect(ctx); err != nil {
+			return err
+		}
+	}
+
+	if lost := tdsChan.lost.Load(); lost != nil {
+		return lost
+	}
+
 	for i, packet := range tdsChan.queueTx.queue {
 		select {
 		case <-ctx.Done():
@@ -555,9 +713,10 @@ func (tdsChan *Channel) sendPacket(packet *Packet) error {
 	if len(packet.Data) != tdsChan.tdsConn.PacketBodySize() {
 		// Data portion is not exhausted, this is the last packet.
 		packet.Header.Status |= TDS_BUFSTAT_EOM
+		tdsChan.requestPending.Store(true)
 	}
 
-	n, err := packet.WriteTo(tdsChan.tdsConn.conn)
+	n, err := packet.WriteTo(tdsChan.tdsConn.netConn())
 	if err != nil {
 		return fmt.Errorf("error writing packet to server: %w", err)
 	}
@@ -582,7 +741,8 @@ func (tdsChan *Channel) WritePacket(packet *Packet) {
 	// The packet is header-only - pass it directly into the package
 	// channel.
 	if packet.Header.Length == PacketHeaderSize {
-		tdsChan.packageCh <- HeaderOnlyPackage{Header: packet.Header}
+		tdsChan.requestPending.Store(false)
+		tdsChan.packageCh <- &HeaderOnlyPackage{Header: packet.Header}
 		return
 	}
 
@@ -594,8 +754,16 @@ func (tdsChan *Channel) WritePacket(packet *Packet) {
 		// isn't enough to fill a Package.
 		curPacket, curData := tdsChan.queueRx.Position()
 
-		// Attempt to parse a Package.
-		if ok := tdsChan.tryParsePackage(); !ok {
+		// Attempt to parse a Package or continue streaming the fields
+		// of a row.
+		var ok bool
+		if tdsChan.lobStream != nil {
+			ok = tdsChan.continueLobStream()
+		} else {
+			ok = tdsChan.tryParsePackage()
+		}
+
+		if !ok {
 			// Attempting to parse package failed
 			if tdsChan.queueRx.IsEOM() {
 				// And queue is EOM - reset queue
@@ -624,8 +792,17 @@ func (tdsChan *Channel) tryParsePackage() bool {
 			// TDS doesn't always send a DonePackage with TDS_DONE_FINAL
 			// - usually only when a procedure with multiple commands is
 			// being executed.
-			if lastPkg, ok := tdsChan.lastPkgRx.(*DonePackage); !ok || lastPkg.Status != TDS_DONE_FINAL {
-				tdsChan.packageCh <- &DonePackage{Status: TDS_DONE_FINAL}
+			// Event notifications are sent in separate messages and
+			// are not terminated by a final DonePackage.
+			if tdsChan.rxEventOnly {
+				tdsChan.rxEventOnly = false
+			} else {
+				tdsChan.requestPending.Store(false)
+				// A DonePackage acknowledging an attention without
+				// TDS_DONE_MORE is final as well.
+				if lastPkg, ok := tdsChan.lastPkgRx.(*DonePackage); !ok || (lastPkg.Status != TDS_DONE_FINAL && !isAttentionDone(lastPkg)) {
+					tdsChan.packageCh <- &DonePackage{Status: TDS_DONE_FINAL}
+				}
 			}
 		}
 		return false
@@ -638,6 +815,12 @@ func (tdsChan *Channel) tryParsePackage() bool {
 		return false
 	}
 
+	// Take over the row format handed over for this response.
+	if tdsChan.rowFmtRx != nil {
+		tdsChan.lastPkgRx = tdsChan.rowFmtRx
+		tdsChan.rowFmtRx = nil
+	}
+
 	// If the Package is tokenless write the token byte back in.
 	if tokenless, ok := pkg.(*TokenlessPackage); ok {
 		tokenless.Data.WriteByte(tokenByte)
@@ -650,8 +833,21 @@ func (tdsChan *Channel) tryParsePackage() bool {
 		}
 	}
 
+	var stream *lobStream
+	if row, ok := pkg.(*RowPackage); ok && tdsChan.tdsConn.info.StreamLobs {
+		stream = newLobStream(row, tdsChan.tdsConn.info.ChannelPackageQueueSize, tdsChan.tdsConn.ctx.Done())
+	}
+
 	// Read data into Package.
-	if err := pkg.ReadFrom(tdsChan.queueRx); err != nil {
+	if err := pkg.ReadFrom(tdsChan.queueRx); errors.Is(err, errLobStream) {
+		// The remaining fields are read by continueLobStream once the
+		// data of the streamed field was passed to its reader.
+		if err := stream.detach(); err != nil {
+			tdsChan.errCh <- fmt.Errorf("error streaming package %T: %w", pkg, err)
+			return false
+		}
+		tdsChan.lobStream = stream
+	} else if err != nil {
 		if errors.Is(err, ErrNotEnoughBytes) {
 			// Not enough bytes available to parse package
 			return false
@@ -663,7 +859,9 @@ func (tdsChan *Channel) tryParsePackage() bool {
 	}
 
 	if tdsChan.tdsConn.info.DebugLogPackages {
-		log.Printf("RX: %s", pkg)
+		// Servers do not send login payloads - loggingIn is only
+		// accessed by the goroutine sending packages.
+		log.Printf("RX: %s", tdsChan.redactRx.format(pkg, false))
 	}
 
 	pass, err := tdsChan.handleSpecialPackage(pkg)
@@ -681,5 +879,6 @@ func (tdsChan *Channel) tryParsePackage() bool {
 
 	tdsChan.packageCh <- pkg
 	tdsChan.lastPkgRx = pkg
+	tdsChan.rxEventOnly = false
 	return true
 }
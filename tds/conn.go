@@ -7,19 +7,16 @@ package tds
 import (
 	"context"
 	"crypto/tls"
-	"crypto/x509"
-	"encoding/pem"
 	"errors"
 	"fmt"
 	"io"
 	"math"
 	"net"
-	"os"
 	"strings"
 	"sync"
 	"sync/atomic"
-	"time"
 
+	"github.com/SAP/go-dblib/dsn"
 	"github.com/hashicorp/go-multierror"
 )
 
@@ -41,8 +38,51 @@ type Conn struct {
 	tdsChannelsLock     *sync.RWMutex
 	errCh               chan error
 
+	// eventSubs maps names of registered procedures to the channels
+	// of their subscribers.
+	eventSubs     map[string][]chan Event
+	eventSubsLock *sync.Mutex
+	// eventRegLock serializes subscriptions, so the connection is
+	// registered once per name.
+	eventRegLock *sync.Mutex
+
 	// packetSize is the negotiated packet size
 	packetSize int
+
+	// connLock guards conn and addr, which are replaced on failovers
+	// and migrations. reconnectDone is closed once a failover or
+	// migration in progress is completed.
+	connLock      *sync.RWMutex
+	addr          string
+	reconnectDone chan struct{}
+	// closing is set once the connection is being closed and must not
+	// fail over.
+	closing atomic.Bool
+
+	// loginConfig is the configuration of the login on the main
+	// channel, haSessionID and haAlternates are the HA session and the
+	// addresses of the companion servers sent by the server.
+	haLock       *sync.Mutex
+	loginConfig  *LoginConfig
+	haSessionID  []byte
+	haAlternates []string
+
+	failoverHooks     []FailoverHook
+	failoverHooksLock *sync.Mutex
+
+	// migration is the state of a migration waiting for the reply of
+	// the server.
+	migrationLock      *sync.Mutex
+	migration          *migration
+	migrationHooks     []MigrationHook
+	migrationHooksLock *sync.Mutex
+
+	// database and sendLocator are the session state restored after
+	// a migration, serverVersion is the version of the server.
+	sessionLock   *sync.Mutex
+	database      string
+	sendLocator   bool
+	serverVersion *ServerVersion
 }
 
 // Dial returns a prepared and dialed Conn.
@@ -51,71 +91,27 @@ type Conn struct {
 // to abort any interaction with the server - hence closing the parent
 // context will abort all interaction with the server.
 func NewConn(ctx context.Context, info *Info) (*Conn, error) {
-	// Dial returns a prepared and dialed Conn.
-	c, err := net.Dial(info.Network, fmt.Sprintf("%s:%s", info.Host, info.Port))
-	if err != nil {
-		return nil, fmt.Errorf("error opening connection: %w", err)
+	if err := dsn.Validate(info); err != nil {
+		return nil, fmt.Errorf("tds: invalid connection information: %w", err)
 	}
 
-	if info.TLSEnable || strings.TrimSpace(strings.Replace(info.Port, "0", "", -1)) == "443" {
-		tlsConfig := &tls.Config{}
-		tlsConfig.ServerName = info.Host
-		tlsConfig.InsecureSkipVerify = info.TLSSkipValidation
-
-		if info.TLSHostname != "" {
-
-			hostname := strings.TrimPrefix(info.TLSHostname, "CN=")
-
-			tlsConfig.ServerName = hostname
-		}
-
-		if info.TLSCAFile != "" {
-			bs, err := os.ReadFile(info.TLSCAFile)
-			if err != nil {
-				return nil, fmt.Errorf("error reading file at ssl-ca path '%s': %w",
-					info.TLSCAFile, err)
-			}
-
-			tlsConfig.RootCAs = x509.NewCertPool()
-
-			withCaCert := false
-
-			for {
-				var block *pem.Block
-				block, bs = pem.Decode(bs)
-				if block == nil {
-					break
-				}
-
-				caCert, err := x509.ParseCertificate(block.Bytes)
-				if err != nil {
-					return nil, fmt.Errorf("error parsing CA PEM at ssl-ca path '%s': %w",
-						info.TLSCAFile, err)
-				}
-
-				tlsConfig.RootCAs.AddCert(caCert)
-				withCaCert = true
-				if len(bs) == 0 {
-					break
-				}
-			}
-
-			if !withCaCert {
-				return nil, fmt.Errorf("could not parse any valid CA certificate from file '%s'", info.TLSCAFile)
-			}
-		}
-
-		tlsClient := tls.Client(c, tlsConfig)
-		if err := tlsClient.Handshake(); err != nil {
-			return nil, fmt.Errorf("error during TLS handshake with server: %w", err)
-		}
-		c = tlsClient
+	c, addr, err := connect(ctx, info)
+	if err != nil {
+		return nil, err
 	}
 
 	tds := &Conn{
-		info:       info,
-		conn:       c,
-		packetSize: 512,
+		info:              info,
+		conn:              c,
+		packetSize:        512,
+		connLock:          &sync.RWMutex{},
+		addr:              addr,
+		haLock:            &sync.Mutex{},
+		failoverHooksLock: &sync.Mutex{},
+
+		migrationLock:      &sync.Mutex{},
+		migrationHooksLock: &sync.Mutex{},
+		sessionLock:        &sync.Mutex{},
 	}
 
 	if err := tds.setCapabilities(); err != nil {
@@ -131,6 +127,9 @@ func NewConn(ctx context.Context, info *Info) (*Conn, error) {
 	tds.tdsChannels = make(map[int]*Channel)
 	tds.tdsChannelsLock = &sync.RWMutex{}
 	tds.errCh = make(chan error, 10)
+	tds.eventSubs = make(map[string][]chan Event)
+	tds.eventSubsLock = &sync.Mutex{}
+	tds.eventRegLock = &sync.Mutex{}
 
 	// A goroutine automatically reads payloads from the server and
 	// passes them to the corresponding channel.
@@ -141,6 +140,56 @@ func NewConn(ctx context.Context, info *Info) (*Conn, error) {
 	return tds, nil
 }
 
+// address returns the address of the server at host and port for
+// network.
+func address(network, host, port string) string {
+	if strings.HasPrefix(network, "unix") {
+		// The host is the path of the socket.
+		return host
+	}
+
+	return net.JoinHostPort(host, port)
+}
+
+// dial opens a connection to the server at host and port.
+//
+// Establishing the connection and the TLS handshake are aborted when
+// ctx is closed or Info.ConnectTimeout is exceeded.
+func dial(ctx context.Context, info *Info, host, port string) (net.Conn, error) {
+	if info.ConnectTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, info.ConnectTimeout)
+		defer cancel()
+	}
+
+	dialContext := info.DialContext
+	if dialContext == nil {
+		dialContext = (&net.Dialer{}).DialContext
+	}
+
+	c, err := dialContext(ctx, info.Network, address(info.Network, host, port))
+	if err != nil {
+		return nil, fmt.Errorf("error opening connection: %w", err)
+	}
+
+	tlsConfig, err := info.tlsConfig(host)
+	if err != nil {
+		c.Close()
+		return nil, fmt.Errorf("error configuring TLS: %w", err)
+	}
+
+	if tlsConfig != nil {
+		tlsClient := tls.Client(c, tlsConfig)
+		if err := tlsClient.HandshakeContext(ctx); err != nil {
+			c.Close()
+			return nil, fmt.Errorf("error during TLS handshake with server: %w", err)
+		}
+		c = tlsClient
+	}
+
+	return c, nil
+}
+
 // Close closes a Conn and its unclosed Channels.
 //
 // Teardown and closing on the client side is guaranteed, even if Close
@@ -163,15 +212,25 @@ func (tds *Conn) Close() error {
 		}
 	}
 
+	tds.closing.Store(true)
 	tds.ctxCancel()
+	tds.closeEventSubscriptions()
 
-	if err := tds.conn.Close(); err != nil {
+	if err := tds.netConn().Close(); err != nil {
 		me = multierror.Append(me, fmt.Errorf("error closing connection: %w", err))
 	}
 
 	return me
 }
 
+// netConn returns the connection to the server.
+func (tds *Conn) netConn() io.ReadWriteCloser {
+	tds.connLock.RLock()
+	defer tds.connLock.RUnlock()
+
+	return tds.conn
+}
+
 // PacketSize returns the negotiated packet size.
 func (tds *Conn) PacketSize() int {
 	// Must be pointer-receive as it is passed to Channels to acquire
@@ -215,7 +274,18 @@ func (tds *Conn) ReadFrom() {
 		}
 
 		packet := &Packet{}
-		_, err := packet.ReadFrom(tds.ctx, tds.conn, time.Duration(tds.info.PacketReadTimeout)*time.Second)
+		_, err := packet.ReadFrom(tds.ctx, tds.netConn(), tds.info.PacketReadTimeout)
+		// A close packet ends the connection as requested by the
+		// server.
+		closed := errors.Is(err, io.EOF) && packet.Header.MsgType == TDS_BUF_CLOSE
+		if err != nil && !closed && isConnectionError(err) && tds.canFailover() {
+			if failoverErr := tds.failover(); failoverErr != nil {
+				tds.errCh <- fmt.Errorf("error reading packet: %w; %v", err, failoverErr)
+				return
+			}
+			continue
+		}
+
 		if err != nil && !errors.Is(err, io.EOF) {
 			tds.errCh <- fmt.Errorf("error reading packet: %w", err)
 			continue
@@ -245,15 +315,15 @@ func (tds *Conn) setCapabilities() error {
 			// Support language requests
 			TDS_REQ_LANG,
 			// Support RPC requests
-			// TODO: TDS_REQ_RPC,
+			TDS_REQ_RPC,
 			// Support procedure event notifications
-			// TODO: TDS_REQ_EVT,
+			TDS_REQ_EVT,
 			// Support multiple commands per request
 			TDS_REQ_MSTMT,
 			// Support bulk copy
-			// TODO: TDS_REQ_BCP,
+			TDS_REQ_BCP,
 			// Support cursors requests
-			// TODO: TDS_REQ_CURSOR,
+			TDS_REQ_CURSOR,
 			// Support dynamic SQL
 			TDS_REQ_DYNF,
 			// Support MSG requests
@@ -309,14 +379,15 @@ func (tds *Conn) setCapabilities() error {
 			TDS_DATA_XML,
 			TDS_DATA_BIGDATETIME,
 			TDS_DATA_USECS,
-			//TODO: TDS_DATA_LOBLOCATOR,
+			TDS_DATA_LOBLOCATOR,
 
 			// Support streaming
 			//TODO: TDS_OBJECT_CHAR,
 			//TODO: TDS_OBJECT_BINARY,
 
-			// Support expedited and non-expedited attentions
-			TDS_CON_OOB,
+			// Support non-expedited attentions - expedited attentions are
+			// out of scope as they require urgent data, which the net
+			// package cannot send
 			TDS_CON_INBAND,
 			// Use urgent notifications
 			TDS_REQ_URGEVT,
@@ -334,6 +405,11 @@ func (tds *Conn) setCapabilities() error {
 			TDS_WIDETABLES,
 
 			// Support scrollable cursors
+			TDS_CSR_PREV,
+			TDS_CSR_FIRST,
+			TDS_CSR_LAST,
+			TDS_CSR_ABS,
+			TDS_CSR_REL,
 			TDS_CSR_SCROLL,
 			TDS_CSR_SENSITIVE,
 			TDS_CSR_INSENSITIVE,
@@ -344,8 +420,8 @@ func (tds *Conn) setCapabilities() error {
 			TDS_REQ_SRVPKTSIZE,
 
 			// Support cluster failover and migration
-			//TODO: TDS_CAP_CLUSTERFAILOVER,
-			//TODO: TDS_REQ_MIGRATE,
+			TDS_CAP_CLUSTERFAILOVER,
+			TDS_REQ_MIGRATE,
 
 			// Support batched parameters
 			TDS_REQ_DYN_BATCH,
@@ -354,9 +430,6 @@ func (tds *Conn) setCapabilities() error {
 
 			// Support on demand encryption
 			TDS_REQ_COMMAND_ENCRYPTION,
-
-			// Client will only perform readonly operations
-			//TODO: TDS_REQ_READONLY,
 		},
 		[]ResponseCapability{
 			// Ignore format control
@@ -369,6 +442,13 @@ func (tds *Conn) setCapabilities() error {
 		return fmt.Errorf("error creating capability package: %w", err)
 	}
 
+	// Client will only perform readonly operations
+	if tds.info.ReadOnly {
+		if err := caps.SetRequestCapability(TDS_REQ_READONLY, true); err != nil {
+			return fmt.Errorf("error requesting read-only session: %w", err)
+		}
+	}
+
 	tds.Caps = caps
 	return nil
 }
@@ -0,0 +1,322 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tds_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/SAP/go-dblib/dsn"
+	"github.com/SAP/go-dblib/tds"
+	"github.com/SAP/go-dblib/tds/tdstest"
+	"github.com/hashicorp/go-multierror"
+)
+
+// proxy forwards connections accepted on listener to addr.
+func proxy(t *testing.T, listener net.Listener, addr string) {
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			c, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			upstream, err := net.Dial("tcp", addr)
+			if err != nil {
+				c.Close()
+				continue
+			}
+
+			go func() {
+				defer upstream.Close()
+				io.Copy(upstream, c)
+			}()
+			go func() {
+				defer c.Close()
+				io.Copy(c, upstream)
+			}()
+		}
+	}()
+}
+
+// unixProxy forwards connections accepted on a unix socket to addr and
+// returns the path of the socket.
+func unixProxy(t *testing.T, addr string) string {
+	path := filepath.Join(t.TempDir(), "tds.sock")
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("error opening unix socket: %v", err)
+	}
+	proxy(t, listener, addr)
+
+	return path
+}
+
+func TestNewConn_Dial(t *testing.T) {
+	server, err := tdstest.NewServer("user", "pass")
+	if err != nil {
+		t.Fatalf("error creating server: %v", err)
+	}
+	defer server.Close()
+
+	cases := map[string]struct {
+		setup func(*tds.Info)
+		err   error
+	}{
+		"tcp": {
+			setup: func(info *tds.Info) {},
+		},
+		"unix": {
+			setup: func(info *tds.Info) {
+				info.Network = "unix"
+				info.Host = unixProxy(t, server.Addr().String())
+				info.Port = ""
+			},
+		},
+		"custom dialer": {
+			setup: func(info *tds.Info) {
+				addr := server.Addr().String()
+				info.Host = "tunnelled.invalid"
+				info.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+					var d net.Dialer
+					return d.DialContext(ctx, "tcp", addr)
+				}
+			},
+		},
+		"connect timeout": {
+			setup: func(info *tds.Info) {
+				info.ConnectTimeout = time.Second
+				info.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+					<-ctx.Done()
+					return nil, ctx.Err()
+				}
+			},
+			err: context.DeadlineExceeded,
+		},
+	}
+
+	for title, cas := range cases {
+		t.Run(title, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			info, err := server.Info()
+			if err != nil {
+				t.Fatalf("error getting info: %v", err)
+			}
+			cas.setup(info)
+
+			conn, err := tds.NewConn(ctx, info)
+			if cas.err != nil {
+				if !errors.Is(err, cas.err) {
+					t.Fatalf("expected error %v, received: %v", cas.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error opening connection: %v", err)
+			}
+			defer conn.Close()
+
+			ch, err := conn.NewChannel()
+			if err != nil {
+				t.Fatalf("error opening channel: %v", err)
+			}
+
+			config, err := tds.NewLoginConfig(info)
+			if err != nil {
+				t.Fatalf("error creating login config: %v", err)
+			}
+
+			if err := ch.Login(ctx, config); err != nil {
+				t.Fatalf("error logging in: %v", err)
+			}
+		})
+	}
+}
+
+func TestNewConn_Validate(t *testing.T) {
+	cases := map[string]struct {
+		setup func(*tds.Info)
+		keys  []string
+	}{
+		"network": {
+			setup: func(info *tds.Info) { info.Network = "udp" },
+			keys:  []string{"network"},
+		},
+		"timeouts without unit": {
+			setup: func(info *tds.Info) {
+				info.ConnectTimeout = 30
+				info.PacketReadTimeout = 50
+			},
+			keys: []string{"connect-timeout", "packet-read-timeout"},
+		},
+	}
+
+	for title, cas := range cases {
+		t.Run(title, func(t *testing.T) {
+			info := &tds.Info{}
+			if err := tds.SetInfo(info); err != nil {
+				t.Fatalf("error setting info defaults: %v", err)
+			}
+			info.Host = "localhost"
+			info.Port = "5000"
+			cas.setup(info)
+
+			_, err := tds.NewConn(context.Background(), info)
+
+			var me *multierror.Error
+			if !errors.As(err, &me) {
+				t.Fatalf("expected validation errors, received: %v", err)
+			}
+
+			keys := []string{}
+			for _, err := range me.Errors {
+				var verr dsn.ValidationError
+				if errors.As(err, &verr) {
+					keys = append(keys, verr.Key)
+				}
+			}
+
+			if !reflect.DeepEqual(keys, cas.keys) {
+				t.Errorf("expected violations of %q, received: %v", cas.keys, err)
+			}
+		})
+	}
+}
+
+func TestNewConn_ConnectStrategy(t *testing.T) {
+	servers := make([]string, 2)
+	for i := range servers {
+		server, err := tdstest.NewServer("user", "pass")
+		if err != nil {
+			t.Fatalf("error creating server: %v", err)
+		}
+		defer server.Close()
+
+		server.SetVersion(fmt.Sprintf("server%d/16.0", i+1))
+		servers[i] = server.Addr().String()
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("error opening listener: %v", err)
+	}
+	unreachable := listener.Addr().String()
+	listener.Close()
+
+	cases := map[string]struct {
+		hosts    []string
+		strategy string
+		// connected are the versions of the servers connected to by
+		// consecutive connections.
+		connected []string
+		errs      int
+	}{
+		"failover": {
+			hosts:     []string{unreachable, servers[0], servers[1]},
+			connected: []string{"server1/16.0", "server1/16.0"},
+		},
+		"round-robin": {
+			hosts:     []string{servers[0], servers[1]},
+			strategy:  "round-robin",
+			connected: []string{"server1/16.0", "server2/16.0", "server1/16.0"},
+		},
+		"random": {
+			hosts:     []string{servers[1], servers[1]},
+			strategy:  "random",
+			connected: []string{"server2/16.0"},
+		},
+		"unreachable": {
+			hosts: []string{unreachable, unreachable},
+			errs:  2,
+		},
+		"invalid strategy": {
+			hosts:    []string{servers[0]},
+			strategy: "fastest",
+		},
+	}
+
+	for title, cas := range cases {
+		t.Run(title, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			info := &tds.Info{}
+			if err := tds.SetInfo(info); err != nil {
+				t.Fatalf("error setting info defaults: %v", err)
+			}
+			info.Host, info.Port, _ = net.SplitHostPort(cas.hosts[0])
+			info.Hosts = cas.hosts
+			info.Username = "user"
+			info.Password = "pass"
+			info.ConnectStrategy = cas.strategy
+
+			if len(cas.connected) == 0 {
+				_, err := tds.NewConn(ctx, info)
+				if err == nil {
+					t.Fatalf("expected error connecting")
+				}
+
+				var me *multierror.Error
+				if cas.errs > 0 && (!errors.As(err, &me) || len(me.Errors) != cas.errs) {
+					t.Fatalf("expected %d errors, received: %v", cas.errs, err)
+				}
+				return
+			}
+
+			for _, expected := range cas.connected {
+				if err := connectTo(ctx, info, expected); err != nil {
+					t.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// connectTo logs in with info and returns an error if the connection is
+// not established to the server with version.
+func connectTo(ctx context.Context, info *tds.Info, version string) error {
+	conn, err := tds.NewConn(ctx, info)
+	if err != nil {
+		return fmt.Errorf("error opening connection: %w", err)
+	}
+	defer conn.Close()
+
+	ch, err := conn.NewChannel()
+	if err != nil {
+		return fmt.Errorf("error opening channel: %w", err)
+	}
+
+	config, err := tds.NewLoginConfig(info)
+	if err != nil {
+		return fmt.Errorf("error creating login config: %w", err)
+	}
+
+	if err := ch.Login(ctx, config); err != nil {
+		return fmt.Errorf("error logging in: %w", err)
+	}
+
+	v, err := ch.QueryServerVersion(ctx)
+	if err != nil {
+		return fmt.Errorf("error querying server version: %w", err)
+	}
+
+	if raw := v.Raw; raw != version {
+		return fmt.Errorf("expected connection to %q, connected to %q", version, raw)
+	}
+
+	return nil
+}
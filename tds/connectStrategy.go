@@ -0,0 +1,88 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tds
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// connectRand shuffles the addresses for the random connect
+	// strategy, guarded by connectRandLock.
+	connectRandLock = &sync.Mutex{}
+	connectRand     = rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	// roundRobinOffsets maps the joined addresses of DSNs with the
+	// round-robin connect strategy to the *atomic.Uint32 counting
+	// their connections.
+	roundRobinOffsets = &sync.Map{}
+)
+
+// connect opens a connection to one of the servers of info, tried in
+// the order of Info.ConnectStrategy, and returns it with the address of
+// the server.
+func connect(ctx context.Context, info *Info) (net.Conn, string, error) {
+	if strings.HasPrefix(info.Network, "unix") {
+		c, err := dial(ctx, info, info.Host, info.Port)
+		if err != nil {
+			return nil, "", err
+		}
+		return c, address(info.Network, info.Host, info.Port), nil
+	}
+
+	addresses, err := info.connectAddresses()
+	if err != nil {
+		return nil, "", err
+	}
+
+	return dialAddresses(ctx, info, addresses)
+}
+
+// connectAddresses returns the addresses of the servers of info as
+// host:port in the order of Info.ConnectStrategy.
+//
+// Hosts without a port use Info.Port.
+func (info *Info) connectAddresses() ([]string, error) {
+	addresses := []string{net.JoinHostPort(info.Host, info.Port)}
+	if len(info.Hosts) > 0 {
+		addresses = make([]string, len(info.Hosts))
+		for i, host := range info.Hosts {
+			h, p, err := net.SplitHostPort(host)
+			if err != nil {
+				h, p = host, ""
+			}
+			if p == "" {
+				p = info.Port
+			}
+			addresses[i] = net.JoinHostPort(h, p)
+		}
+	}
+
+	switch strings.ToLower(info.ConnectStrategy) {
+	case "", "failover":
+	case "random":
+		connectRandLock.Lock()
+		connectRand.Shuffle(len(addresses), func(i, j int) {
+			addresses[i], addresses[j] = addresses[j], addresses[i]
+		})
+		connectRandLock.Unlock()
+	case "round-robin":
+		counter, _ := roundRobinOffsets.LoadOrStore(strings.Join(addresses, ","), &atomic.Uint32{})
+		offset := int(counter.(*atomic.Uint32).Add(1)-1) % len(addresses)
+		addresses = append(addresses[offset:], addresses[:offset]...)
+	default:
+		return nil, fmt.Errorf("invalid connect-strategy %q, expected one of 'failover', 'random' or 'round-robin'",
+			info.ConnectStrategy)
+	}
+
+	return addresses, nil
+}
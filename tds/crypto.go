@@ -12,13 +12,17 @@ import (
 	"crypto/sha1"
 	"crypto/x509"
 	"encoding/pem"
+	"errors"
 	"fmt"
 )
 
-// rsaEncrypt encrypts a password with the given public key using the
-// given nonce.
-func rsaEncrypt(pemPubKey, nonce, password []byte) ([]byte, error) {
+// parsePublicKey parses a PEM encoded PKCS#1 public key.
+func parsePublicKey(pemPubKey []byte) (*rsa.PublicKey, error) {
 	pubKeyBlock, rest := pem.Decode(pemPubKey)
+	if pubKeyBlock == nil {
+		return nil, errors.New("public key is not PEM encoded")
+	}
+
 	if len(rest) > 0 {
 		return nil, fmt.Errorf("trailing bytes in public key: %#v", rest)
 	}
@@ -28,9 +32,31 @@ func rsaEncrypt(pemPubKey, nonce, password []byte) ([]byte, error) {
 		return nil, fmt.Errorf("failed to parse PKCS#1 public key: %w", err)
 	}
 
+	return publicKey, nil
+}
+
+// rsaEncrypt encrypts a password with the given public key using the
+// given nonce.
+func rsaEncrypt(pemPubKey, nonce, password []byte) ([]byte, error) {
+	publicKey, err := parsePublicKey(pemPubKey)
+	if err != nil {
+		return nil, err
+	}
+
 	return rsa.EncryptOAEP(sha1.New(), rand.Reader, publicKey, append(nonce, []byte(password)...), []byte{})
 }
 
+// rsaEncryptPKCS1v15 encrypts a password with the given public key
+// using PKCS#1 v1.5 padding, as used by TDS_MSG_SEC_ENCRYPT2.
+func rsaEncryptPKCS1v15(pemPubKey, password []byte) ([]byte, error) {
+	publicKey, err := parsePublicKey(pemPubKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return rsa.EncryptPKCS1v15(rand.Reader, publicKey, password)
+}
+
 // generateSymmetricKey creates a cryptographically secure key to use
 // for the odceCipher.
 func generateSymmetricKey(odce odceCipher) ([]byte, error) {
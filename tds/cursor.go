@@ -0,0 +1,285 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrCursorNotOpen is returned when rows are fetched from or modified
+// through a cursor that is not open.
+var ErrCursorNotOpen = errors.New("cursor is not open")
+
+// Cursor is a server-side cursor.
+type Cursor struct {
+	tdsChan *Channel
+
+	// ID is the ID assigned to the cursor by the server.
+	ID      int32
+	Name    string
+	Options CursorOption
+	// Status is the last status of the cursor communicated by the
+	// server.
+	Status CursorIStatus
+	// RowCount is the number of rows returned by a single fetch.
+	RowCount int32
+
+	rowFmt *RowFmtPackage
+}
+
+// DeclareCursor declares a cursor for stmt.
+//
+// The options are sent as is - e.g. TDS_CUR_DOPT_SCROLLABLE
+// | TDS_CUR_DOPT_KEYSETDRIVEN declares a scrollable, keyset-driven
+// cursor.
+func (tdsChan *Channel) DeclareCursor(ctx context.Context, name, stmt string, options CursorOption) (*Cursor, error) {
+	pkg, err := NewCurDeclarePackage(name, stmt, TDS_CUR_DSTAT_UNUSED, options)
+	if err != nil {
+		return nil, fmt.Errorf("error creating CurDeclare package: %w", err)
+	}
+	pkg.wide = tdsChan.tdsConn.Caps.HasRequestCapability(TDS_WIDETABLES)
+
+	cursor := &Cursor{
+		tdsChan:  tdsChan,
+		Name:     name,
+		Options:  options,
+		RowCount: 1,
+	}
+
+	if err := cursor.request(ctx, pkg, nil); err != nil {
+		return nil, fmt.Errorf("error declaring cursor %s: %w", name, err)
+	}
+
+	if cursor.ID == 0 {
+		return nil, fmt.Errorf("server did not assign an ID to cursor %s", name)
+	}
+
+	return cursor, nil
+}
+
+// SetRowCount sets the number of rows returned by a single fetch.
+func (cursor *Cursor) SetRowCount(ctx context.Context, rowCount int32) error {
+	if rowCount < 1 {
+		return fmt.Errorf("invalid row count %d", rowCount)
+	}
+
+	pkg := cursor.curInfo(TDS_CUR_CMD_SETCURROWS)
+	pkg.Status = TDS_CUR_ISTAT_ROWCNT
+	pkg.RowCount = rowCount
+
+	if err := cursor.request(ctx, pkg, nil); err != nil {
+		return fmt.Errorf("error setting row count of cursor %s: %w", cursor.Name, err)
+	}
+
+	cursor.RowCount = rowCount
+	return nil
+}
+
+// Open opens the cursor.
+func (cursor *Cursor) Open(ctx context.Context) error {
+	pkg := &CurOpenPackage{CursorID: cursor.ID, Status: TDS_CUR_OSTAT_UNUSED}
+
+	if err := cursor.request(ctx, pkg, nil); err != nil {
+		return fmt.Errorf("error opening cursor %s: %w", cursor.Name, err)
+	}
+
+	if cursor.rowFmt == nil {
+		return fmt.Errorf("received no row format for cursor %s", cursor.Name)
+	}
+
+	return nil
+}
+
+// Columns returns the formats of the columns of the cursor. Columns
+// returns nil until the cursor was opened.
+func (cursor Cursor) Columns() []FieldFmt {
+	if cursor.rowFmt == nil {
+		return nil
+	}
+	return cursor.rowFmt.Fmts
+}
+
+// Fetch fetches up to RowCount rows from the cursor and returns their
+// values.
+//
+// rowNumber is only sent with TDS_CUR_ABS and TDS_CUR_REL.
+func (cursor *Cursor) Fetch(ctx context.Context, fetchType CursorFetchType, rowNumber int32) ([][]interface{}, error) {
+	if cursor.rowFmt == nil {
+		return nil, ErrCursorNotOpen
+	}
+
+	pkg := &CurFetchPackage{CursorID: cursor.ID, Type: fetchType, RowNumber: rowNumber}
+
+	// The server only sends the row format when the cursor is opened.
+	cursor.tdsChan.setRowFmtRx(cursor.rowFmt)
+
+	rows := [][]interface{}{}
+	err := cursor.request(ctx, pkg, func(pkg Package) {
+		row, ok := pkg.(*RowPackage)
+		if !ok {
+			return
+		}
+
+		values := make([]interface{}, len(row.DataFields))
+		for i, field := range row.DataFields {
+			values[i] = field.Value()
+		}
+		rows = append(rows, values)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching %s from cursor %s: %w", fetchType, cursor.Name, err)
+	}
+
+	return rows, nil
+}
+
+// Next fetches the next rows.
+func (cursor *Cursor) Next(ctx context.Context) ([][]interface{}, error) {
+	return cursor.Fetch(ctx, TDS_CUR_NEXT, 0)
+}
+
+// Prev fetches the previous rows.
+func (cursor *Cursor) Prev(ctx context.Context) ([][]interface{}, error) {
+	return cursor.Fetch(ctx, TDS_CUR_PREV, 0)
+}
+
+// First fetches the first rows.
+func (cursor *Cursor) First(ctx context.Context) ([][]interface{}, error) {
+	return cursor.Fetch(ctx, TDS_CUR_FIRST, 0)
+}
+
+// Last fetches the last rows.
+func (cursor *Cursor) Last(ctx context.Context) ([][]interface{}, error) {
+	return cursor.Fetch(ctx, TDS_CUR_LAST, 0)
+}
+
+// Absolute fetches the rows starting at rowNumber. Negative row numbers
+// are counted from the end of the result set.
+func (cursor *Cursor) Absolute(ctx context.Context, rowNumber int32) ([][]interface{}, error) {
+	return cursor.Fetch(ctx, TDS_CUR_ABS, rowNumber)
+}
+
+// Relative fetches the rows starting offset rows from the current
+// position.
+func (cursor *Cursor) Relative(ctx context.Context, offset int32) ([][]interface{}, error) {
+	return cursor.Fetch(ctx, TDS_CUR_REL, offset)
+}
+
+// Update executes the update statement stmt on the current row of the
+// cursor and returns the number of updated rows.
+//
+// stmt is the update statement without the `where current of` clause,
+// e.g. `update t set a = 1`.
+func (cursor *Cursor) Update(ctx context.Context, table, stmt string) (int, error) {
+	if cursor.rowFmt == nil {
+		return 0, ErrCursorNotOpen
+	}
+
+	pkg := &CurUpdatePackage{CursorID: cursor.ID, TableName: table, Stmt: stmt}
+
+	count, err := cursor.requestCount(ctx, pkg)
+	if err != nil {
+		return count, fmt.Errorf("error updating current row of cursor %s: %w", cursor.Name, err)
+	}
+
+	return count, nil
+}
+
+// Delete deletes the current row of the cursor from table and returns
+// the number of deleted rows.
+func (cursor *Cursor) Delete(ctx context.Context, table string) (int, error) {
+	if cursor.rowFmt == nil {
+		return 0, ErrCursorNotOpen
+	}
+
+	pkg := &CurDeletePackage{CursorID: cursor.ID, TableName: table}
+
+	count, err := cursor.requestCount(ctx, pkg)
+	if err != nil {
+		return count, fmt.Errorf("error deleting current row of cursor %s: %w", cursor.Name, err)
+	}
+
+	return count, nil
+}
+
+// Close closes and deallocates the cursor.
+func (cursor *Cursor) Close(ctx context.Context) error {
+	pkg := &CurClosePackage{CursorID: cursor.ID, Options: TDS_CUR_COPT_DEALLOC}
+
+	if err := cursor.request(ctx, pkg, nil); err != nil {
+		return fmt.Errorf("error closing cursor %s: %w", cursor.Name, err)
+	}
+
+	cursor.rowFmt = nil
+	return nil
+}
+
+func (cursor Cursor) curInfo(command CursorCommand) *CurInfoPackage {
+	pkg := NewCurInfoPackage(cursor.tdsChan.tdsConn.Caps.HasRequestCapability(TDS_REQ_CURINFO3))
+	pkg.CursorID = cursor.ID
+	pkg.Command = command
+	if cursor.ID == 0 {
+		pkg.Name = cursor.Name
+	}
+	return pkg
+}
+
+// requestCount sends pkg and returns the row count communicated in the
+// response.
+func (cursor *Cursor) requestCount(ctx context.Context, pkg Package) (int, error) {
+	count := 0
+	err := cursor.request(ctx, pkg, func(pkg Package) {
+		if done, ok := pkg.(*DonePackage); ok && done.Status&TDS_DONE_COUNT == TDS_DONE_COUNT {
+			count += int(done.Count)
+		}
+	})
+	return count, err
+}
+
+// request sends pkg and processes the response until the final
+// DonePackage. CurInfoPackages and RowFmtPackages are recorded in the
+// cursor, all other packages are passed to processPkg.
+func (cursor *Cursor) request(ctx context.Context, pkg Package, processPkg func(Package)) error {
+	if err := cursor.tdsChan.SendPackage(ctx, pkg); err != nil {
+		return fmt.Errorf("error sending %T: %w", pkg, err)
+	}
+
+	_, err := cursor.tdsChan.NextPackageUntil(ctx, true, func(pkg Package) (bool, error) {
+		switch typed := pkg.(type) {
+		case *CurInfoPackage:
+			// The server assigns the ID in response to the
+			// declaration.
+			if cursor.ID == 0 {
+				cursor.ID = typed.CursorID
+			}
+			cursor.Status = typed.Status
+			if typed.Status&TDS_CUR_ISTAT_ROWCNT == TDS_CUR_ISTAT_ROWCNT {
+				cursor.RowCount = typed.RowCount
+			}
+		case *RowFmtPackage:
+			cursor.rowFmt = typed
+		case *DonePackage:
+			if processPkg != nil {
+				processPkg(typed)
+			}
+
+			if typed.Status&TDS_DONE_ERROR == TDS_DONE_ERROR {
+				return false, errDoneError
+			}
+
+			return isDoneFinal(typed)
+		default:
+			if processPkg != nil {
+				processPkg(pkg)
+			}
+		}
+
+		return false, nil
+	})
+
+	return err
+}
@@ -0,0 +1,147 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tds_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/SAP/go-dblib/asetypes"
+	"github.com/SAP/go-dblib/tds"
+	"github.com/SAP/go-dblib/tds/tdstest"
+)
+
+func TestCursor(t *testing.T) {
+	server, err := tdstest.NewServer("user", "pass")
+	if err != nil {
+		t.Fatalf("error creating server: %v", err)
+	}
+	defer server.Close()
+
+	const stmt = "select id from t"
+	server.HandleCursor(stmt, []tdstest.Column{{Name: "id", DataType: asetypes.INT4}},
+		[]interface{}{int32(1)},
+		[]interface{}{int32(2)},
+		[]interface{}{int32(3)},
+		[]interface{}{int32(4)},
+		[]interface{}{int32(5)},
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	conn, ch, err := server.Connect(ctx)
+	if err != nil {
+		t.Fatalf("error connecting: %v", err)
+	}
+	defer conn.Close()
+
+	cursor, err := ch.DeclareCursor(ctx, "c", stmt, tds.TDS_CUR_DOPT_SCROLLABLE|tds.TDS_CUR_DOPT_KEYSETDRIVEN)
+	if err != nil {
+		t.Fatalf("error declaring cursor: %v", err)
+	}
+
+	if _, err := cursor.Next(ctx); err == nil {
+		t.Errorf("expected error fetching from unopened cursor")
+	}
+
+	if err := cursor.SetRowCount(ctx, 2); err != nil {
+		t.Fatalf("error setting row count: %v", err)
+	}
+
+	if cursor.RowCount != 2 {
+		t.Errorf("expected row count 2, received %d", cursor.RowCount)
+	}
+
+	if err := cursor.Open(ctx); err != nil {
+		t.Fatalf("error opening cursor: %v", err)
+	}
+
+	if len(cursor.Columns()) != 1 {
+		t.Fatalf("expected 1 column, received %d", len(cursor.Columns()))
+	}
+
+	ids := func(ids ...int32) [][]interface{} {
+		rows := make([][]interface{}, len(ids))
+		for i, id := range ids {
+			rows[i] = []interface{}{id}
+		}
+		return rows
+	}
+
+	cases := map[string]struct {
+		fetch    func() ([][]interface{}, error)
+		expected [][]interface{}
+	}{
+		"next": {
+			fetch:    func() ([][]interface{}, error) { return cursor.Next(ctx) },
+			expected: ids(1, 2),
+		},
+		"next again": {
+			fetch:    func() ([][]interface{}, error) { return cursor.Next(ctx) },
+			expected: ids(3, 4),
+		},
+		"prev": {
+			fetch:    func() ([][]interface{}, error) { return cursor.Prev(ctx) },
+			expected: ids(1, 2),
+		},
+		"last": {
+			fetch:    func() ([][]interface{}, error) { return cursor.Last(ctx) },
+			expected: ids(4, 5),
+		},
+		"first": {
+			fetch:    func() ([][]interface{}, error) { return cursor.First(ctx) },
+			expected: ids(1, 2),
+		},
+		"absolute": {
+			fetch:    func() ([][]interface{}, error) { return cursor.Absolute(ctx, 3) },
+			expected: ids(3, 4),
+		},
+		"relative": {
+			fetch:    func() ([][]interface{}, error) { return cursor.Relative(ctx, -1) },
+			expected: ids(2, 3),
+		},
+		"after delete": {
+			fetch: func() ([][]interface{}, error) {
+				if count, err := cursor.Update(ctx, "t", "update t set id = id"); err != nil || count != 1 {
+					t.Errorf("unexpected result updating current row: %d, %v", count, err)
+				}
+
+				// Deletes row 3, the last fetched row.
+				if count, err := cursor.Delete(ctx, "t"); err != nil || count != 1 {
+					t.Errorf("unexpected result deleting current row: %d, %v", count, err)
+				}
+
+				return cursor.Absolute(ctx, 2)
+			},
+			expected: ids(2, 4),
+		},
+	}
+
+	// The cases depend on the cursor position and are run in order.
+	for _, title := range []string{"next", "next again", "prev", "last", "first", "absolute", "relative", "after delete"} {
+		cas := cases[title]
+		t.Run(title, func(t *testing.T) {
+			rows, err := cas.fetch()
+			if err != nil {
+				t.Fatalf("error fetching rows: %v", err)
+			}
+
+			if !reflect.DeepEqual(rows, cas.expected) {
+				t.Errorf("received unexpected rows:\nexpected: %v\nreceived: %v", cas.expected, rows)
+			}
+		})
+	}
+
+	if err := cursor.Close(ctx); err != nil {
+		t.Fatalf("error closing cursor: %v", err)
+	}
+
+	if _, err := cursor.Next(ctx); err == nil {
+		t.Errorf("expected error fetching from closed cursor")
+	}
+}
@@ -0,0 +1,218 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/SAP/go-dblib/asetypes"
+)
+
+// Event is a notification of a registered procedure event.
+type Event struct {
+	// Name is the name of the registered procedure.
+	Name string
+	// ChannelID is the ID of the logical channel the notification was
+	// received on.
+	ChannelID int
+	// Params are the parameters the procedure was executed with.
+	Params []FieldData
+}
+
+// SubscribeEvent registers the connection for notifications of the
+// registered procedure name using sp_regwatch and returns a channel the
+// notifications are delivered on.
+//
+// The connection is registered once per name, further subscriptions
+// share the registration until all of them are unsubscribed.
+//
+// The registration is executed on the main channel, which must not be
+// in use at the same time. Notifications are delivered regardless of
+// the logical channel they arrive on.
+//
+// ctx only applies to the registration. The returned channel is closed
+// when it is passed to UnsubscribeEvent or the connection is closed. If
+// the channel is full notifications are dropped and an error is
+// recorded in the channel the notification was received on.
+func (tds *Conn) SubscribeEvent(ctx context.Context, name string) (<-chan Event, error) {
+	if !tds.Caps.HasRequestCapability(TDS_REQ_EVT) {
+		return nil, errors.New("server does not support event notifications")
+	}
+
+	tds.eventRegLock.Lock()
+	defer tds.eventRegLock.Unlock()
+
+	events := make(chan Event, tds.info.ChannelPackageQueueSize)
+
+	// Subscribe before registering to not miss notifications sent
+	// immediately after the registration.
+	tds.eventSubsLock.Lock()
+	tds.eventSubs[name] = append(tds.eventSubs[name], events)
+	registered := len(tds.eventSubs[name]) > 1
+	tds.eventSubsLock.Unlock()
+
+	if registered {
+		return events, nil
+	}
+
+	if err := tds.eventRPC(ctx, "sp_regwatch", name, "nowait"); err != nil {
+		tds.removeEventSubscription(name, events)
+		return nil, fmt.Errorf("error registering for event %s: %w", name, err)
+	}
+
+	return events, nil
+}
+
+// UnsubscribeEvent closes events, which was returned by SubscribeEvent
+// for name. If it is the last subscription for name the connection is
+// deregistered from notifications using sp_regnowatch.
+func (tds *Conn) UnsubscribeEvent(ctx context.Context, name string, events <-chan Event) error {
+	tds.eventRegLock.Lock()
+	defer tds.eventRegLock.Unlock()
+
+	tds.eventSubsLock.Lock()
+	subs := tds.eventSubs[name]
+	var sub chan Event
+	for _, s := range subs {
+		if s == events {
+			sub = s
+			break
+		}
+	}
+	tds.eventSubsLock.Unlock()
+
+	if sub == nil {
+		return fmt.Errorf("channel is not subscribed to event %s", name)
+	}
+
+	if len(subs) == 1 {
+		if err := tds.eventRPC(ctx, "sp_regnowatch", name); err != nil {
+			return fmt.Errorf("error deregistering from event %s: %w", name, err)
+		}
+	}
+
+	tds.removeEventSubscription(name, sub)
+	return nil
+}
+
+func (tds *Conn) eventRPC(ctx context.Context, procedure string, args ...string) error {
+	tds.tdsChannelsLock.RLock()
+	tdsChan, ok := tds.tdsChannels[0]
+	tds.tdsChannelsLock.RUnlock()
+	if !ok {
+		return errors.New("main channel is not open")
+	}
+
+	params := make([]RPCParam, len(args))
+	for i, arg := range args {
+		params[i] = RPCParam{DataType: asetypes.VARCHAR, Value: arg}
+	}
+
+	result, err := tdsChan.RPC(ctx, procedure, params...)
+	if err != nil {
+		return err
+	}
+
+	if result.HasReturnStatus && result.ReturnStatus != 0 {
+		return fmt.Errorf("%s returned status %d", procedure, result.ReturnStatus)
+	}
+
+	return nil
+}
+
+func (tds *Conn) removeEventSubscription(name string, events chan Event) {
+	tds.eventSubsLock.Lock()
+	defer tds.eventSubsLock.Unlock()
+
+	subs := tds.eventSubs[name]
+	for i, sub := range subs {
+		if sub != events {
+			continue
+		}
+
+		tds.eventSubs[name] = append(subs[:i], subs[i+1:]...)
+		if len(tds.eventSubs[name]) == 0 {
+			delete(tds.eventSubs, name)
+		}
+		close(events)
+		return
+	}
+}
+
+// closeEventSubscriptions closes the channels of all subscriptions.
+func (tds *Conn) closeEventSubscriptions() {
+	tds.eventSubsLock.Lock()
+	defer tds.eventSubsLock.Unlock()
+
+	for name, subs := range tds.eventSubs {
+		for _, events := range subs {
+			close(events)
+		}
+		delete(tds.eventSubs, name)
+	}
+}
+
+// dispatchEvent delivers event to the subscribers of its name.
+func (tds *Conn) dispatchEvent(event Event) error {
+	tds.eventSubsLock.Lock()
+	defer tds.eventSubsLock.Unlock()
+
+	dropped := 0
+	for _, events := range tds.eventSubs[event.Name] {
+		select {
+		case events <- event:
+		default:
+			dropped++
+		}
+	}
+
+	if dropped > 0 {
+		return fmt.Errorf("dropped notification of event %s for %d subscribers with full channels",
+			event.Name, dropped)
+	}
+
+	return nil
+}
+
+// handleEventPackage collects the packages of an event notification and
+// dispatches the event once the notification is complete.
+//
+// The returned boolean signals if pkg was part of an event notification.
+func (tdsChan *Channel) handleEventPackage(pkg Package) (bool, error) {
+	if notice, ok := pkg.(*EventNoticePackage); ok {
+		tdsChan.event = &Event{Name: notice.Name, ChannelID: tdsChan.channelId}
+		// Notifications may arrive in between the packages of
+		// a response, the last package of the response is restored
+		// after the notification.
+		tdsChan.eventLastPkgRx = tdsChan.lastPkgRx
+		tdsChan.lastPkgRx = pkg
+		return true, nil
+	}
+
+	if tdsChan.event == nil {
+		return false, nil
+	}
+
+	switch typed := pkg.(type) {
+	case *ParamFmtPackage:
+		tdsChan.lastPkgRx = pkg
+	case *ParamsPackage:
+		tdsChan.event.Params = append(tdsChan.event.Params, typed.DataFields...)
+		tdsChan.lastPkgRx = pkg
+	case *DonePackage:
+		event := *tdsChan.event
+		tdsChan.event = nil
+		tdsChan.lastPkgRx = tdsChan.eventLastPkgRx
+		tdsChan.eventLastPkgRx = nil
+		tdsChan.rxEventOnly = true
+		return true, tdsChan.tdsConn.dispatchEvent(event)
+	default:
+		return false, nil
+	}
+
+	return true, nil
+}
@@ -0,0 +1,157 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tds_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/SAP/go-dblib/asetypes"
+	"github.com/SAP/go-dblib/tds"
+	"github.com/SAP/go-dblib/tds/tdstest"
+)
+
+func TestConn_SubscribeEvent(t *testing.T) {
+	server, err := tdstest.NewServer("user", "pass")
+	if err != nil {
+		t.Fatalf("error creating server: %v", err)
+	}
+	defer server.Close()
+
+	response, err := tdstest.Result([]tdstest.Column{{Name: "a", DataType: asetypes.INT4}}, []interface{}{int32(1)})
+	if err != nil {
+		t.Fatalf("error creating result: %v", err)
+	}
+	server.HandleLanguage("select 1 as a", response...)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	conn, ch, err := server.Connect(ctx)
+	if err != nil {
+		t.Fatalf("error connecting: %v", err)
+	}
+	defer conn.Close()
+
+	// The subscription outlives the context of the registration.
+	subCtx, subCancel := context.WithCancel(ctx)
+	events, err := conn.SubscribeEvent(subCtx, "proc_event")
+	subCancel()
+	if err != nil {
+		t.Fatalf("error subscribing to event: %v", err)
+	}
+
+	// Further subscriptions share the registration.
+	shared, err := conn.SubscribeEvent(ctx, "proc_event")
+	if err != nil {
+		t.Fatalf("error subscribing to event: %v", err)
+	}
+
+	closedEvents, err := conn.SubscribeEvent(ctx, "other_event")
+	if err != nil {
+		t.Fatalf("error subscribing to event: %v", err)
+	}
+
+	if n := server.EventRegistrations(); n != 2 {
+		t.Errorf("expected 2 registrations, server received %d", n)
+	}
+
+	param, err := tds.LookupFieldFmt(asetypes.INT4)
+	if err != nil {
+		t.Fatalf("error looking up INT4: %v", err)
+	}
+	param.SetName("@id")
+	paramData, err := tds.LookupFieldData(param)
+	if err != nil {
+		t.Fatalf("error looking up INT4 data: %v", err)
+	}
+	paramData.SetValue(int32(42))
+
+	if n, err := server.Notify("proc_event", paramData); err != nil || n != 1 {
+		t.Fatalf("unexpected result notifying clients: %d, %v", n, err)
+	}
+
+	for _, subscription := range []<-chan tds.Event{events, shared} {
+		select {
+		case event := <-subscription:
+			if event.Name != "proc_event" || event.ChannelID != 0 {
+				t.Errorf("received unexpected event: %v", event)
+			}
+
+			if len(event.Params) != 1 || event.Params[0].Value() != int32(42) || event.Params[0].Format().Name() != "@id" {
+				t.Errorf("received unexpected event parameters: %v", event.Params)
+			}
+		case <-ctx.Done():
+			t.Fatalf("did not receive event notification")
+		}
+	}
+
+	// The notification must not interfere with following requests.
+	if err := ch.SendPackage(ctx, &tds.LanguagePackage{Cmd: "select 1 as a"}); err != nil {
+		t.Fatalf("error sending request: %v", err)
+	}
+
+	pkg, err := ch.NextPackage(ctx, true)
+	if err != nil {
+		t.Fatalf("error reading response: %v", err)
+	}
+
+	if _, ok := pkg.(*tds.RowFmtPackage); !ok {
+		t.Fatalf("expected RowFmtPackage, received %s", pkg)
+	}
+
+	if _, err := ch.NextPackageUntil(ctx, true, nil); err != nil {
+		t.Fatalf("error reading remaining response: %v", err)
+	}
+
+	// Unsubscribing closes only the passed subscription, the
+	// registration is kept for the remaining one.
+	if err := conn.UnsubscribeEvent(ctx, "proc_event", events); err != nil {
+		t.Errorf("error unsubscribing: %v", err)
+	}
+
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Errorf("received event after unsubscribing")
+		}
+	case <-ctx.Done():
+		t.Errorf("events channel was not closed after unsubscribing")
+	}
+
+	if n, err := server.Notify("proc_event", paramData); err != nil || n != 1 {
+		t.Errorf("unexpected result notifying clients with remaining subscription: %d, %v", n, err)
+	}
+
+	select {
+	case <-shared:
+	case <-ctx.Done():
+		t.Errorf("remaining subscription did not receive event notification")
+	}
+
+	if err := conn.UnsubscribeEvent(ctx, "proc_event", events); err == nil {
+		t.Errorf("expected error unsubscribing twice")
+	}
+
+	if err := conn.UnsubscribeEvent(ctx, "proc_event", shared); err != nil {
+		t.Errorf("error unsubscribing: %v", err)
+	}
+
+	if n, err := server.Notify("proc_event", paramData); err != nil || n != 0 {
+		t.Errorf("unexpected result notifying clients after unsubscribing: %d, %v", n, err)
+	}
+
+	conn.Close()
+
+	select {
+	case _, ok := <-closedEvents:
+		if ok {
+			t.Errorf("received event after closing the connection")
+		}
+	case <-ctx.Done():
+		t.Errorf("events channel was not closed after closing the connection")
+	}
+}
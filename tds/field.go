@@ -415,6 +415,7 @@ func (field *fieldDataBase) readFrom(ch BytesChannel) (int, error) {
 	if err != nil {
 		return n, fmt.Errorf("failed to read %d bytes: %w", length, err)
 	}
+	n += len(bs)
 
 	field.value, err = field.fmt.DataType().GoValue(endian, bs)
 	if err != nil {
@@ -773,7 +774,11 @@ type fieldFmtBlob struct {
 
 // FormatByteLength implements the tds.FieldFmt interface.
 func (field fieldFmtBlob) FormatByteLength() int {
-	return 1 + 1 + len(field.classID) + field.LengthBytes()
+	n := 1 + field.LengthBytes()
+	if field.blobType == TDS_BLOB_FULLCLASSNAME || field.blobType == TDS_BLOB_DBID_CLASSDEF {
+		n += 2 + len(field.classID)
+	}
+	return n
 }
 
 // ReadFrom implements the tds.FieldFmt interface.
@@ -836,6 +841,16 @@ func (field fieldFmtBlob) WriteTo(ch BytesChannel) (int, error) {
 	return n, nil
 }
 
+// BlobType returns the type of the blob.
+func (field fieldFmtBlob) BlobType() BlobType {
+	return field.blobType
+}
+
+// SetBlobType sets the type of the blob.
+func (field *fieldFmtBlob) SetBlobType(blobType BlobType) {
+	field.blobType = blobType
+}
+
 type BlobFieldFmt struct{ fieldFmtBlob }
 
 type fieldDataBlob struct {
@@ -874,17 +889,21 @@ func (field *fieldDataBlob) ReadFrom(ch BytesChannel) (int, error) {
 			field.serializationType = NativeCharacterFormat
 		case TDS_BLOB_BINARY:
 			field.serializationType = BinaryData
-		case TDS_BLOB_UNICHAR:
+		case TDS_BLOB_UNICHAR, TDS_LOBLOC_UNICHAR:
 			field.serializationType = UnicharUTF16
+		case TDS_LOBLOC_CHAR:
+			field.serializationType = NativeCharacterFormat
+		case TDS_LOBLOC_BINARY:
+			field.serializationType = BinaryData
 		}
 	case 1:
-		if fieldFmt.blobType != TDS_BLOB_UNICHAR {
+		if fieldFmt.blobType != TDS_BLOB_UNICHAR && fieldFmt.blobType != TDS_LOBLOC_UNICHAR {
 			return n, fmt.Errorf("invalid blob (%s) and serialization (%d) type combination",
 				fieldFmt.blobType, serialization)
 		}
 		field.serializationType = UnicharUTF8
 	case 2:
-		if fieldFmt.blobType != TDS_BLOB_UNICHAR {
+		if fieldFmt.blobType != TDS_BLOB_UNICHAR && fieldFmt.blobType != TDS_LOBLOC_UNICHAR {
 			return n, fmt.Errorf("invalid blob (%s) and serialization (%d) type combination",
 				fieldFmt.blobType, serialization)
 		}
@@ -938,29 +957,36 @@ func (field *fieldDataBlob) ReadFrom(ch BytesChannel) (int, error) {
 		highBitSet := dataLen&fieldDataBlobHighBit == fieldDataBlobHighBit
 		dataLen = dataLen &^ fieldDataBlobHighBit
 
-		// if high bit is set and dataLen is zero no data array follows,
-		// instead read the next data length immediately
-		if highBitSet {
-			break
-		}
+		if dataLen > 0 {
+			dataPart, err := ch.Bytes(int(dataLen))
+			if err != nil {
+				return 0, ErrNotEnoughBytes
+			}
+			n += int(dataLen)
 
-		if dataLen == 0 {
-			continue
+			// TODO this is inefficient for large datasets - must be
+			// replaced by a low-overhead extensible byte storage (so - not
+			// a slice)
+			data = append(data, dataPart...)
 		}
 
-		dataPart, err := ch.Bytes(int(dataLen))
-		if err != nil {
-			return 0, ErrNotEnoughBytes
+		if !highBitSet {
+			break
 		}
-		n += int(dataLen)
-
-		// TODO this is inefficient for large datasets - must be
-		// replaced by a low-overhead extensible byte storage (so - not
-		// a slice)
-		data = append(data, dataPart...)
 	}
 
-	field.value = data
+	switch fieldFmt.blobType {
+	case TDS_LOBLOC_CHAR, TDS_LOBLOC_BINARY, TDS_LOBLOC_UNICHAR:
+		// The data sent with a locator is the prefetched beginning of
+		// the LOB.
+		field.value = &LobLocator{
+			Type:       fieldFmt.blobType,
+			Locator:    []byte(field.locator),
+			Prefetched: data,
+		}
+	default:
+		field.value = data
+	}
 
 	return n, nil
 }
@@ -1003,6 +1029,11 @@ func (field fieldDataBlob) WriteTo(ch BytesChannel) (int, error) {
 		}
 		n += len(field.subClassID)
 	case TDS_LOBLOC_CHAR, TDS_LOBLOC_BINARY, TDS_LOBLOC_UNICHAR:
+		if lob, ok := field.value.(*LobLocator); ok {
+			field.locator = string(lob.Locator)
+			field.value = lob.Prefetched
+		}
+
 		if err := ch.WriteUint16(uint16(len(field.locator))); err != nil {
 			return n, fmt.Errorf("failed to write Locator length: %w", err)
 		}
@@ -1026,8 +1057,8 @@ func (field fieldDataBlob) WriteTo(ch BytesChannel) (int, error) {
 
 	start, end := 0, dataLen
 	for {
-		passLen := uint32(dataLen)
-		if end == len(data) {
+		passLen := uint32(end - start)
+		if end != len(data) {
 			passLen |= fieldDataBlobHighBit
 		}
 
@@ -1047,6 +1078,9 @@ func (field fieldDataBlob) WriteTo(ch BytesChannel) (int, error) {
 
 		start = end
 		end += dataLen
+		if end > len(data) {
+			end = len(data)
+		}
 	}
 
 	return n, nil
@@ -1123,42 +1157,53 @@ type fieldDataTxtPtr struct {
 
 // ReadFrom implements the tds.FieldData interface.
 func (field *fieldDataTxtPtr) ReadFrom(ch BytesChannel) (int, error) {
-	n, err := field.readFromStatus(ch)
+	n, dataLen, err := field.readHeaderFrom(ch)
 	if err != nil {
 		return n, err
 	}
 
-	txtPtrLen, err := ch.Uint8()
+	field.value, err = ch.Bytes(dataLen)
 	if err != nil {
 		return 0, ErrNotEnoughBytes
 	}
+	n += dataLen
+
+	return n, nil
+}
+
+// readHeaderFrom reads the field up to the data and returns the length
+// of the data.
+func (field *fieldDataTxtPtr) readHeaderFrom(ch BytesChannel) (int, int, error) {
+	n, err := field.readFromStatus(ch)
+	if err != nil {
+		return n, 0, err
+	}
+
+	txtPtrLen, err := ch.Uint8()
+	if err != nil {
+		return 0, 0, ErrNotEnoughBytes
+	}
 	n++
 
 	field.txtPtr, err = ch.Bytes(int(txtPtrLen))
 	if err != nil {
-		return 0, ErrNotEnoughBytes
+		return 0, 0, ErrNotEnoughBytes
 	}
 	n += int(txtPtrLen)
 
 	field.timeStamp, err = ch.Bytes(8)
 	if err != nil {
-		return 0, ErrNotEnoughBytes
+		return 0, 0, ErrNotEnoughBytes
 	}
 	n += 8
 
 	dataLen, err := ch.Uint32()
 	if err != nil {
-		return 0, ErrNotEnoughBytes
+		return 0, 0, ErrNotEnoughBytes
 	}
 	n += 4
 
-	field.value, err = ch.Bytes(int(dataLen))
-	if err != nil {
-		return 0, ErrNotEnoughBytes
-	}
-	n += int(dataLen)
-
-	return n, nil
+	return n, int(dataLen), nil
 }
 
 // WriteTo implements the tds.FieldData interface.
@@ -1178,10 +1223,13 @@ func (field fieldDataTxtPtr) WriteTo(ch BytesChannel) (int, error) {
 	}
 	n += len(field.txtPtr)
 
-	if err := ch.WriteBytes(field.timeStamp); err != nil {
+	// The timestamp is always eight bytes long.
+	timeStamp := make([]byte, 8)
+	copy(timeStamp, field.timeStamp)
+	if err := ch.WriteBytes(timeStamp); err != nil {
 		return n, fmt.Errorf("failed to write TimeStamp: %w", err)
 	}
-	n += len(field.timeStamp)
+	n += len(timeStamp)
 
 	var data []byte
 	switch t := field.value.(type) {
@@ -0,0 +1,393 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+// ErrHAFailover is returned for requests that were in progress when the
+// connection failed over to the companion server. The request must be
+// repeated.
+var ErrHAFailover = errors.New("connection failed over to the HA companion server")
+
+// ChannelLostError is returned for requests on a channel which could
+// not be restored on the server after a failover or migration, either
+// because the logical channel could not be set up again or because the
+// session could not be resumed on the target server of a migration.
+// The channel must be closed.
+type ChannelLostError struct {
+	ChannelID int
+	Err       error
+}
+
+func (err *ChannelLostError) Error() string {
+	return fmt.Sprintf("logical channel %d was lost when reconnecting: %v", err.ChannelID, err.Err)
+}
+
+func (err *ChannelLostError) Unwrap() error {
+	return err.Err
+}
+
+// FailoverHook defines the signature of functions called by a Conn
+// after failing over to a companion server.
+//
+// from and to are the addresses of the failed server and the companion
+// server. err is the error if resuming the session on the companion
+// server failed.
+type FailoverHook func(from, to string, err error)
+
+// RegisterFailoverHooks registers a function to be called when the
+// connection fails over to a companion server.
+//
+// Note that all registered hooks are called in sequence of being
+// registered. Hooks with a longer run time or waiting on locks should
+// utilize goroutines or use other means to prevent blocking other
+// hooks.
+func (tds *Conn) RegisterFailoverHooks(fns ...FailoverHook) error {
+	tds.failoverHooksLock.Lock()
+	defer tds.failoverHooksLock.Unlock()
+
+	for i, fn := range fns {
+		if fn == nil {
+			return fmt.Errorf("tds: received nil function as hook at index %d", i)
+		}
+	}
+
+	tds.failoverHooks = append(tds.failoverHooks, fns...)
+	return nil
+}
+
+func (tds *Conn) callFailoverHooks(from, to string, err error) {
+	tds.failoverHooksLock.Lock()
+	defer tds.failoverHooksLock.Unlock()
+
+	for _, fn := range tds.failoverHooks {
+		fn(from, to, err)
+	}
+}
+
+// HASession returns the ID of the HA session and the addresses of the
+// companion servers sent by the server.
+//
+// The session ID is nil if the server did not grant an HA session.
+func (tds *Conn) HASession() ([]byte, []string) {
+	tds.haLock.Lock()
+	defer tds.haLock.Unlock()
+
+	return tds.haSessionID, tds.haAlternates
+}
+
+// handleHAFailoverPackage records the HA session communicated by
+// a TDS_MSG_HAFAILOVER message.
+//
+// The message is followed by parameters with the session ID and the
+// addresses of the companion servers.
+func (tdsChan *Channel) handleHAFailoverPackage(pkg Package) bool {
+	if msg, ok := pkg.(*MsgPackage); ok && msg.MsgId == TDS_MSG_HAFAILOVER {
+		tdsChan.haFailoverMsg = true
+		tdsChan.lastPkgRx = pkg
+		return true
+	}
+
+	if !tdsChan.haFailoverMsg {
+		return false
+	}
+
+	switch typed := pkg.(type) {
+	case *ParamFmtPackage:
+		tdsChan.lastPkgRx = pkg
+		return true
+	case *ParamsPackage:
+		tdsChan.haFailoverMsg = false
+		tdsChan.lastPkgRx = pkg
+		tdsChan.tdsConn.setHASession(typed.DataFields)
+		return true
+	}
+
+	tdsChan.haFailoverMsg = false
+	return false
+}
+
+func (tds *Conn) setHASession(fields []FieldData) {
+	if len(fields) == 0 {
+		return
+	}
+
+	sessionID, ok := fields[0].Value().([]byte)
+	if !ok {
+		return
+	}
+
+	alternates := []string{}
+	for _, field := range fields[1:] {
+		if alternate, ok := field.Value().(string); ok {
+			alternates = append(alternates, alternate)
+		}
+	}
+
+	tds.haLock.Lock()
+	defer tds.haLock.Unlock()
+
+	tds.haSessionID = sessionID
+	tds.haAlternates = alternates
+}
+
+// canFailover returns true if an HA session was granted and the
+// connection is not being closed.
+func (tds *Conn) canFailover() bool {
+	if tds.closing.Load() || tds.ctx.Err() != nil {
+		return false
+	}
+
+	tds.haLock.Lock()
+	defer tds.haLock.Unlock()
+
+	return tds.haSessionID != nil && len(tds.haAlternates) > 0 && tds.loginConfig != nil
+}
+
+// failover connects to a companion server and resumes the HA session
+// in the background.
+//
+// Channels with a request in progress receive ErrHAFailover and new
+// requests are blocked until the session was resumed.
+//
+// If a migration or the resumption of a previous failover is in
+// progress failover waits for it to complete instead, after which the
+// connection is read again.
+func (tds *Conn) failover() error {
+	tds.haLock.Lock()
+	sessionID, alternates := tds.haSessionID, tds.haAlternates
+	config := *tds.loginConfig
+	tds.haLock.Unlock()
+
+	done := make(chan struct{})
+
+	tds.connLock.Lock()
+	if inProgress := tds.reconnectDone; inProgress != nil {
+		tds.connLock.Unlock()
+
+		select {
+		case <-tds.ctx.Done():
+			return fmt.Errorf("connection context is closed: %w", tds.ctx.Err())
+		case <-inProgress:
+			return nil
+		}
+	}
+	from, c := tds.addr, tds.conn
+	tds.reconnectDone = done
+	tds.connLock.Unlock()
+
+	// Requests are blocked by reconnectDone, the lock must not be held
+	// while connecting as closing the connection acquires it.
+	c.Close()
+
+	c, to, err := dialAddresses(tds.ctx, tds.info, alternates)
+
+	tds.connLock.Lock()
+	if err == nil && tds.closing.Load() {
+		// Close already closed the previous connection.
+		c.Close()
+		err = errors.New("connection is closed")
+	}
+	if err == nil {
+		tds.conn = c
+		tds.addr = to
+	} else {
+		tds.reconnectDone = nil
+	}
+	tds.connLock.Unlock()
+
+	if err != nil {
+		close(done)
+
+		tds.callFailoverHooks(from, "", err)
+		return fmt.Errorf("error connecting to companion server: %w", err)
+	}
+
+	tds.tdsChannelsLock.RLock()
+	for _, tdsChan := range tds.tdsChannels {
+		tdsChan.resetRx()
+		if !tdsChan.requestPending.Swap(false) {
+			continue
+		}
+		select {
+		case tdsChan.errCh <- ErrHAFailover:
+		default:
+		}
+	}
+	tds.tdsChannelsLock.RUnlock()
+
+	// The login must run in the background as the responses are
+	// received by the goroutine calling failover.
+	go func() {
+		err := tds.resumeSession(config, sessionID)
+
+		tds.connLock.Lock()
+		tds.reconnectDone = nil
+		tds.connLock.Unlock()
+		close(done)
+
+		tds.callFailoverHooks(from, to, err)
+	}()
+
+	return nil
+}
+
+// resumeSession resumes the HA session on the companion server.
+func (tds *Conn) resumeSession(config LoginConfig, sessionID []byte) error {
+	config.HALogin = TDS_HA_LOG_SESSION | TDS_HA_LOG_RESUME | TDS_HA_LOG_FAILOVERSRV
+	config.HASessionID = sessionID
+
+	if err := tds.loginSession(config, nil); err != nil {
+		return fmt.Errorf("error resuming HA session: %w", err)
+	}
+
+	return nil
+}
+
+// loginSession logs in on the connection after a failover or migration
+// and calls fn with the channel used for the login. Afterwards the
+// logical channels are set up again.
+//
+// The login is performed on a separate channel with the ID 0, which
+// replaces the main channel until fn returns.
+func (tds *Conn) loginSession(config LoginConfig, fn func(context.Context, *Channel) error) error {
+	tdsChan := tds.newChannel(0)
+	tdsChan.resuming = true
+
+	tds.tdsChannelsLock.Lock()
+	mainChan := tds.tdsChannels[0]
+	tds.tdsChannels[0] = tdsChan
+	tds.tdsChannelsLock.Unlock()
+
+	defer func() {
+		tds.tdsChannelsLock.Lock()
+		tds.tdsChannels[0] = mainChan
+		tds.tdsChannelsLock.Unlock()
+	}()
+
+	ctx, cancel := tds.reconnectContext()
+	defer cancel()
+
+	if err := tdsChan.Login(ctx, &config); err != nil {
+		return err
+	}
+
+	if fn != nil {
+		if err := fn(ctx, tdsChan); err != nil {
+			return err
+		}
+	}
+
+	tds.setupChannels(ctx)
+	return nil
+}
+
+// reconnectContext returns the context for reconnecting after
+// a failover or migration, which expires after the ConnectTimeout. If
+// no ConnectTimeout is set the context only ends with the connection.
+func (tds *Conn) reconnectContext() (context.Context, context.CancelFunc) {
+	if tds.info.ConnectTimeout <= 0 {
+		return context.WithCancel(tds.ctx)
+	}
+
+	return context.WithTimeout(tds.ctx, tds.info.ConnectTimeout)
+}
+
+// isConnectionError returns true if err signals that the connection to
+// the server was lost, in which case the connection can fail over.
+// Protocol errors are not resolved by failing over.
+func isConnectionError(err error) bool {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, ErrEOFAfterZeroRead) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// setupChannels sets up the logical channels on the connection after
+// a failover or migration.
+//
+// Channels which cannot be set up are marked as lost and return
+// a *ChannelLostError for subsequent requests.
+func (tds *Conn) setupChannels(ctx context.Context) {
+	tds.tdsChannelsLock.RLock()
+	channels := make([]*Channel, 0, len(tds.tdsChannels))
+	for id, tdsChan := range tds.tdsChannels {
+		if id > 0 && tdsChan.lost.Load() == nil {
+			channels = append(channels, tdsChan)
+		}
+	}
+	tds.tdsChannelsLock.RUnlock()
+
+	for _, tdsChan := range channels {
+		// Requests on the channel are blocked until the reconnect is
+		// completed, the packet numbers start over on the new
+		// connection.
+		tdsChan.curPacketNr = 0
+		if err := tdsChan.setup(ctx); err != nil {
+			tdsChan.lost.Store(&ChannelLostError{ChannelID: tdsChan.channelId, Err: err})
+		}
+	}
+}
+
+// dialAddresses connects to the first reachable server of addresses in
+// the form host:port.
+//
+// If no server is reachable the returned error is a *multierror.Error
+// with the errors of all addresses.
+func dialAddresses(ctx context.Context, info *Info, addresses []string) (net.Conn, string, error) {
+	if len(addresses) == 0 {
+		return nil, "", errors.New("no addresses")
+	}
+
+	var me error
+	for _, address := range addresses {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			me = multierror.Append(me, fmt.Errorf("error parsing address %q: %w", address, err))
+			continue
+		}
+
+		c, err := dial(ctx, info, host, port)
+		if err == nil {
+			return c, address, nil
+		}
+		me = multierror.Append(me, fmt.Errorf("error connecting to %s: %w", address, err))
+
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	return nil, "", me
+}
+
+// waitReconnect blocks until a failover or migration in progress is
+// completed.
+func (tds *Conn) waitReconnect(ctx context.Context) error {
+	tds.connLock.RLock()
+	done := tds.reconnectDone
+	tds.connLock.RUnlock()
+
+	if done == nil {
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("passed context is closed while waiting for failover: %w", ctx.Err())
+	case <-done:
+		return nil
+	}
+}
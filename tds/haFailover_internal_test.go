@@ -0,0 +1,49 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tds
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+)
+
+func TestIsConnectionError(t *testing.T) {
+	cases := map[string]struct {
+		err      error
+		expected bool
+	}{
+		"eof": {
+			err:      io.EOF,
+			expected: true,
+		},
+		"eof after zero read": {
+			err:      ErrEOFAfterZeroRead,
+			expected: true,
+		},
+		"unexpected eof": {
+			err:      fmt.Errorf("read 3 of 8 expected bytes from reader: %w", io.ErrUnexpectedEOF),
+			expected: true,
+		},
+		"network error": {
+			err:      fmt.Errorf("error reading body: %w", &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}),
+			expected: true,
+		},
+		"protocol error": {
+			err:      fmt.Errorf("read 3 of 8 expected bytes from reader: %w", nil),
+			expected: false,
+		},
+	}
+
+	for title, cas := range cases {
+		t.Run(title, func(t *testing.T) {
+			if received := isConnectionError(cas.err); received != cas.expected {
+				t.Errorf("expected %t for %v, received %t", cas.expected, cas.err, received)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tds_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/SAP/go-dblib/asetypes"
+	"github.com/SAP/go-dblib/tds"
+	"github.com/SAP/go-dblib/tds/tdstest"
+)
+
+type failover struct {
+	from, to string
+	err      error
+}
+
+func TestConn_HAFailover(t *testing.T) {
+	sessionID := []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06}
+
+	cases := map[string]struct {
+		info func(*tds.Info)
+	}{
+		"default": {},
+		// The session must be resumed without a deadline.
+		"without packet read timeout": {
+			info: func(info *tds.Info) {
+				info.PacketReadTimeout = 0
+			},
+		},
+	}
+
+	for title, cas := range cases {
+		t.Run(title, func(t *testing.T) {
+			primary, err := tdstest.NewServer("user", "pass")
+			if err != nil {
+				t.Fatalf("error creating primary server: %v", err)
+			}
+			defer primary.Close()
+
+			companion, err := tdstest.NewServer("user", "pass")
+			if err != nil {
+				t.Fatalf("error creating companion server: %v", err)
+			}
+			defer companion.Close()
+
+			primary.HandleHAFailover(sessionID, companion.Addr().String())
+			companion.HandleHAFailover(sessionID, primary.Addr().String())
+
+			// Not answered by the primary server
+			primary.HandleLanguage("waitfor delay '01:00:00'")
+
+			single, err := tdstest.Result([]tdstest.Column{{Name: "a", DataType: asetypes.INT4}}, []interface{}{int32(1)})
+			if err != nil {
+				t.Fatalf("error creating result: %v", err)
+			}
+			companion.HandleLanguage("select 1 as a", single...)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			info, err := primary.Info()
+			if err != nil {
+				t.Fatalf("error creating info: %v", err)
+			}
+
+			if cas.info != nil {
+				cas.info(info)
+			}
+
+			conn, ch, err := primary.ConnectInfo(ctx, info)
+			if err != nil {
+				t.Fatalf("error connecting: %v", err)
+			}
+			defer conn.Close()
+
+			received, alternates := conn.HASession()
+			if !bytes.Equal(received, sessionID) {
+				t.Errorf("received unexpected session ID: %q", received)
+			}
+			if expected := []string{companion.Addr().String()}; !reflect.DeepEqual(alternates, expected) {
+				t.Errorf("received unexpected companion servers:\nexpected: %v\nreceived: %v", expected, alternates)
+			}
+
+			logical, err := conn.NewChannel()
+			if err != nil {
+				t.Fatalf("error opening logical channel: %v", err)
+			}
+
+			failovers := make(chan failover, 1)
+			if err := conn.RegisterFailoverHooks(func(from, to string, err error) {
+				failovers <- failover{from: from, to: to, err: err}
+			}); err != nil {
+				t.Fatalf("error registering hook: %v", err)
+			}
+
+			if err := ch.SendPackage(ctx, &tds.LanguagePackage{Cmd: "waitfor delay '01:00:00'"}); err != nil {
+				t.Fatalf("error sending request: %v", err)
+			}
+
+			if err := primary.Close(); err != nil {
+				t.Fatalf("error closing primary server: %v", err)
+			}
+
+			if _, err := ch.NextPackage(ctx, true); !errors.Is(err, tds.ErrHAFailover) {
+				t.Fatalf("expected ErrHAFailover for request in progress, received: %v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				t.Fatalf("failover hook was not called: %v", ctx.Err())
+			case fo := <-failovers:
+				expected := failover{from: primary.Addr().String(), to: companion.Addr().String()}
+				if fo != expected {
+					t.Fatalf("received unexpected failover:\nexpected: %v\nreceived: %v", expected, fo)
+				}
+			}
+
+			// The companion server only accepts requests on logical channels
+			// that were set up again.
+			for _, ch := range []*tds.Channel{ch, logical} {
+				values, err := selectOne(ctx, ch)
+				if err != nil {
+					t.Fatalf("error querying channel: %v", err)
+				}
+
+				if expected := [][]interface{}{{int32(1)}}; !reflect.DeepEqual(values, expected) {
+					t.Errorf("received unexpected rows:\nexpected: %v\nreceived: %v", expected, values)
+				}
+			}
+		})
+	}
+}
+
+// selectOne sends "select 1 as a" on ch and returns the rows.
+func selectOne(ctx context.Context, ch *tds.Channel) ([][]interface{}, error) {
+	if err := ch.SendPackage(ctx, &tds.LanguagePackage{Cmd: "select 1 as a"}); err != nil {
+		return nil, fmt.Errorf("error sending request: %w", err)
+	}
+
+	values := [][]interface{}{}
+	_, err := ch.NextPackageUntil(ctx, true, func(pkg tds.Package) (bool, error) {
+		switch typed := pkg.(type) {
+		case *tds.RowPackage:
+			values = append(values, []interface{}{typed.DataFields[0].Value()})
+		case *tds.DonePackage:
+			return typed.Status == tds.TDS_DONE_FINAL, nil
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading result: %w", err)
+	}
+
+	return values, nil
+}
@@ -28,6 +28,26 @@ func writeString(stream io.Writer, s string, padTo int) error {
 	return err
 }
 
+// readString reads a string written by writeString from ch.
+func readString(ch BytesChannel, padTo int) (string, error) {
+	bs, err := ch.Bytes(padTo)
+	if err != nil {
+		return "", err
+	}
+
+	length, err := ch.Uint8()
+	if err != nil {
+		return "", err
+	}
+
+	if int(length) > padTo {
+		return "", fmt.Errorf("string length %d exceeds maximum length of %d bytes",
+			length, padTo)
+	}
+
+	return string(bs[:length]), nil
+}
+
 func deBitmask(bitmask int, maxValue int) []int {
 	curVal := 1
 	ret := []int{}
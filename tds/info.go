@@ -5,8 +5,11 @@
 package tds
 
 import (
+	"context"
 	"fmt"
+	"net"
 	"os"
+	"time"
 
 	"github.com/SAP/go-dblib/dsn"
 )
@@ -16,16 +19,54 @@ import (
 type Info struct {
 	dsn.Info
 
-	Network        string `json:"network" doc:"Network to use, either 'tcp' or 'udp'"`
+	Network        string `json:"network" enum:"tcp,tcp4,tcp6,unix" doc:"Network to use, either 'tcp', 'tcp4', 'tcp6' or 'unix' with the socket path as host"`
 	ClientHostname string `json:"client-hostname" doc:"Hostname to send to server"`
 
-	TLSEnable         bool   `json:"tls-enable" doc:"Enforce TLS use"`
+	PacketSize    int    `json:"packet-size" range:"512..65535" doc:"Packet size in bytes requested from the server, between 512 and 65535"`
+	AppName       string `json:"app-name" doc:"Application name sent to the server, e.g. shown by sp_who"`
+	ProgramName   string `json:"program-name" doc:"Name of the client library sent to the server, at most 10 bytes"`
+	Language      string `json:"language" doc:"Language of server messages, e.g. 'us_english'"`
+	CharSet       string `json:"charset" doc:"Character set of the client, e.g. 'utf8'"`
+	RemoteServers string `json:"remote-servers" secret:"true" doc:"Comma-separated list of remote servers and their passwords as name:password, used for remote procedure calls"`
+
+	// ConnectTimeout and PacketReadTimeout were integers of seconds
+	// before and are durations now. Values between zero and
+	// a millisecond are rejected as they are likely seconds assigned
+	// without the unit, e.g. 30 instead of 30*time.Second.
+	ConnectTimeout  time.Duration `json:"connect-timeout" range:"0|1ms.." doc:"Time to wait for the connection and TLS handshake to each server to be established, e.g. '30s' - 0 disables the timeout"`
+	ConnectStrategy string        `json:"connect-strategy" validate:"omitempty" enum:"failover,random,round-robin" doc:"Order in which multiple servers are tried, either 'failover' (default, in the listed order), 'random' or 'round-robin'"`
+	// DialContext is used to open connections to the server instead
+	// of net.Dialer.DialContext if set, e.g. to connect through
+	// a tunnel or proxy.
+	DialContext func(ctx context.Context, network, address string) (net.Conn, error)
+
+	TLSMode           string `json:"tls-mode" validate:"omitempty" enum:"disable,require,verify-full" doc:"TLS mode, either 'disable', 'require' (accepts any certificate) or 'verify-full' - derived from tls-enable and tls-skip-validation if empty"`
+	TLSEnable         bool   `json:"tls-enable" doc:"Enforce TLS use, superseded by tls-mode"`
 	TLSHostname       string `json:"tls-hostname" doc:"Remote hostname to validate against SANs"`
-	TLSSkipValidation bool   `json:"tls-skip-validation" doc:"Skip TLS validation - accepts any TLS certificate"`
+	TLSSkipValidation bool   `json:"tls-skip-validation" doc:"Skip TLS validation - accepts any TLS certificate, superseded by tls-mode"`
 	TLSCAFile         string `json:"tls-ca-file" doc:"Path to CA file to validate server certificate against"`
+	TLSSystemRoots    bool   `json:"tls-system-roots" doc:"Validate server certificate against the system root pool in addition to tls-ca-file"`
+	TLSCertFile       string `json:"tls-cert-file" doc:"Path to client certificate file for mutual TLS"`
+	TLSKeyFile        string `json:"tls-key-file" doc:"Path to client key file for mutual TLS"`
+	TLSMinVersion     string `json:"tls-min-version" validate:"omitempty" enum:"1.0,1.1,1.2,1.3" doc:"Minimum TLS version, one of '1.0', '1.1', '1.2' or '1.3'"`
+	TLSMaxVersion     string `json:"tls-max-version" validate:"omitempty" enum:"1.0,1.1,1.2,1.3" doc:"Maximum TLS version, one of '1.0', '1.1', '1.2' or '1.3'"`
+	TLSCipherSuites   string `json:"tls-cipher-suites" doc:"Comma-separated list of allowed cipher suites for TLS 1.0-1.2, e.g. 'TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384'"`
+	TLSFingerprints   string `json:"tls-fingerprints" doc:"Comma-separated list of SHA-256 fingerprints of accepted server certificates"`
+
+	LoginEncryptionMin string `json:"login-encryption-min" validate:"omitempty" enum:"encrypt4,encrypt3,encrypt2" doc:"Weakest password encryption accepted from servers not supporting 'encrypt4' (default), either 'encrypt3' or 'encrypt2'"`
+
+	KerberosServicePrincipal string `json:"krb5-service-principal" doc:"Principal of the server, e.g. 'sybase/host.example.com' - enables Kerberos authentication instead of the password, the realm defaults to the realm of the client"`
+	KerberosPrincipal        string `json:"krb5-principal" doc:"Principal of the client whose key is read from krb5-keytab"`
+	KerberosKeytab           string `json:"krb5-keytab" doc:"Path to keytab with the key of krb5-principal - if empty, the tickets from krb5-ccache are used"`
+	KerberosCCache           string `json:"krb5-ccache" doc:"Path to credential cache, e.g. as created by kinit - defaults to KRB5CCNAME or /tmp/krb5cc_<uid>"`
+	KerberosKDCs             string `json:"krb5-kdcs" doc:"Comma-separated list of KDCs of the realm as host[:port] - if empty, the KDCs are read from KRB5_CONFIG or /etc/krb5.conf"`
+
+	PacketReadTimeout       time.Duration `json:"packet-read-timeout" range:"0|1ms.." doc:"Time to wait before aborting a connection when no response is received from the server, e.g. '50s'"`
+	ChannelPackageQueueSize int           `json:"channel-package-queue-size" range:"1.." doc:"How many TDS packages can be queued in a TDS channel"`
 
-	PacketReadTimeout       int `json:"packet-read-timeout" doc:"Time in seconds to wait before aborting a connection when no response is received from the server"`
-	ChannelPackageQueueSize int `json:"channel-package-queue-size" doc:"How many TDS packages can be queued in a TDS channel"`
+	StreamLobs bool `json:"stream-lobs" doc:"Return TEXT, IMAGE, UNITEXT and XML values as tds.LobReader, which buffer up to channel-package-queue-size times 8 KiB of the data not yet read"`
+
+	ReadOnly bool `json:"read-only" doc:"Request a read-only session, which the server enforces, and refuse recognized writes before sending them"`
 
 	DebugLogPackages bool `json:"debug-log-packages" doc:"Log packages as they are transmitted/received"`
 }
@@ -39,8 +80,21 @@ func SetInfo(info *Info) error {
 	}
 	info.ClientHostname = hostname
 
-	info.PacketReadTimeout = 50
+	info.PacketSize = 512
+	info.AppName = "github.com/SAP/go-dblib/tds"
+	info.ProgramName = libraryName
+	info.Language = "us_english"
+	info.CharSet = "utf8"
+
+	info.ConnectTimeout = 30 * time.Second
+	info.PacketReadTimeout = 50 * time.Second
 	info.ChannelPackageQueueSize = 100
 
 	return nil
 }
+
+// String returns info as a simple DSN with the password and other
+// secrets masked, hence it is safe to log.
+func (info Info) String() string {
+	return dsn.FormatSimple(&info)
+}
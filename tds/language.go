@@ -0,0 +1,51 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// errDoneError is returned by execLanguage if the server signals an
+// error in a DonePackage.
+var errDoneError = errors.New("received DonePackage with error status")
+
+// execLanguage sends cmd as a language command and passes all received
+// packages to processPkg until the final DonePackage was received.
+//
+// processPkg may be nil if the response is not of interest. If the
+// server reports an error the returned error is an EEDError if the
+// server communicated the error details.
+func (tdsChan *Channel) execLanguage(ctx context.Context, cmd string, processPkg func(Package) error) error {
+	if err := tdsChan.SendPackage(ctx, &LanguagePackage{Cmd: cmd}); err != nil {
+		return fmt.Errorf("error sending language command: %w", err)
+	}
+
+	_, err := tdsChan.NextPackageUntil(ctx, true, func(pkg Package) (bool, error) {
+		if processPkg != nil {
+			if err := processPkg(pkg); err != nil {
+				return false, err
+			}
+		}
+
+		done, ok := pkg.(*DonePackage)
+		if !ok {
+			return false, nil
+		}
+
+		if done.Status&TDS_DONE_ERROR == TDS_DONE_ERROR {
+			return false, errDoneError
+		}
+
+		return isDoneFinal(done)
+	})
+	if err != nil {
+		return fmt.Errorf("error executing %q: %w", cmd, err)
+	}
+
+	return nil
+}
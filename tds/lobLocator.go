@@ -0,0 +1,299 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tds
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// lobLocatorChunkSize is the maximum number of bytes read or written
+// by a single server-side locator function call.
+const lobLocatorChunkSize = 16384
+
+// ErrLobLocatorUnbound is returned by LobLocator operations if the
+// locator was not received on a Channel.
+var ErrLobLocatorUnbound = errors.New("LOB locator is not bound to a channel")
+
+// LobLocator is a handle to a LOB stored on the server.
+//
+// LOB locators are received as the value of BLOB fields instead of the
+// LOB itself if sending locators is enabled with
+// Channel.SetSendLocator.
+//
+// The LOB is accessed through server-side locator functions on the
+// Channel the locator was received on, which must not be in use at the
+// same time.
+type LobLocator struct {
+	// Type is one of TDS_LOBLOC_CHAR, TDS_LOBLOC_BINARY and
+	// TDS_LOBLOC_UNICHAR.
+	Type    BlobType
+	Locator []byte
+	// Prefetched is the beginning of the LOB the server sent with the
+	// locator.
+	Prefetched []byte
+
+	tdsChan *Channel
+}
+
+// SetSendLocator enables or disables sending LOB locators instead of
+// TEXT, IMAGE and UNITEXT values.
+func (tdsChan *Channel) SetSendLocator(ctx context.Context, enable bool) error {
+	if !tdsChan.tdsConn.Caps.HasRequestCapability(TDS_DATA_LOBLOCATOR) {
+		return errors.New("server does not support LOB locators")
+	}
+
+	value := "off"
+	if enable {
+		value = "on"
+	}
+
+	if err := tdsChan.execLanguage(ctx, "set send_locator "+value, nil); err != nil {
+		return err
+	}
+
+	tdsChan.tdsConn.sessionLock.Lock()
+	tdsChan.tdsConn.sendLocator = enable
+	tdsChan.tdsConn.sessionLock.Unlock()
+
+	return nil
+}
+
+// bindLobLocators binds the LOB locators in fields to tdsChan.
+func (tdsChan *Channel) bindLobLocators(fields []FieldData) {
+	for _, field := range fields {
+		if lob, ok := field.Value().(*LobLocator); ok {
+			lob.tdsChan = tdsChan
+		}
+	}
+}
+
+// Literal returns the locator as a SQL literal.
+func (lob LobLocator) Literal() string {
+	return fmt.Sprintf("locator_literal(%s, 0x%s)", lob.typeName(), hex.EncodeToString(lob.Locator))
+}
+
+func (lob LobLocator) typeName() string {
+	switch lob.Type {
+	case TDS_LOBLOC_CHAR:
+		return "text_locator"
+	case TDS_LOBLOC_UNICHAR:
+		return "unitext_locator"
+	default:
+		return "image_locator"
+	}
+}
+
+// Length returns the length of the LOB - in bytes for binary LOBs and
+// in characters otherwise.
+func (lob LobLocator) Length(ctx context.Context) (int64, error) {
+	function := "char_length"
+	if lob.Type == TDS_LOBLOC_BINARY {
+		function = "datalength"
+	}
+
+	value, err := lob.query(ctx, fmt.Sprintf("select %s(%s)", function, lob.Literal()))
+	if err != nil {
+		return 0, fmt.Errorf("error querying LOB length: %w", err)
+	}
+
+	switch typed := value.(type) {
+	case int32:
+		return int64(typed), nil
+	case int64:
+		return typed, nil
+	case nil:
+		return 0, nil
+	}
+
+	return 0, fmt.Errorf("received length of unexpected type %T", value)
+}
+
+// Substring returns up to length units of the LOB starting at offset,
+// starting at 0. Units are bytes for binary LOBs and characters
+// otherwise, whose data is returned UTF-8 encoded.
+func (lob LobLocator) Substring(ctx context.Context, offset int64, length int) ([]byte, error) {
+	value, err := lob.query(ctx, fmt.Sprintf("select substring(%s, %d, %d)", lob.Literal(), offset+1, length))
+	if err != nil {
+		return nil, fmt.Errorf("error querying LOB substring: %w", err)
+	}
+
+	switch typed := value.(type) {
+	case []byte:
+		return typed, nil
+	case string:
+		return []byte(typed), nil
+	case nil:
+		return []byte{}, nil
+	}
+
+	return nil, fmt.Errorf("received substring of unexpected type %T", value)
+}
+
+// ReadAtContext reads the LOB starting at off into p and returns the
+// number of bytes read. Unlike io.ReaderAt it takes a context, as each
+// call is a request to the server.
+//
+// As with Substring off counts bytes for binary LOBs and characters
+// otherwise. Only whole characters are read into p, hence less than
+// len(p) bytes may be read without an error - the number of characters
+// read is utf8.RuneCount(p[:n]). If the end of the LOB was reached the
+// returned error is io.EOF.
+func (lob LobLocator) ReadAtContext(ctx context.Context, p []byte, off int64) (int, error) {
+	n := 0
+	for n < len(p) {
+		// A character is at least one byte long, so the remaining
+		// space bounds the number of units to request.
+		length := len(p) - n
+		if length > lobLocatorChunkSize {
+			length = lobLocatorChunkSize
+		}
+
+		bs, err := lob.Substring(ctx, off, length)
+		if err != nil {
+			return n, err
+		}
+
+		size, units := lob.fit(bs, len(p)-n)
+		n += copy(p[n:], bs[:size])
+		off += int64(units)
+
+		if size < len(bs) {
+			// p cannot hold the next character.
+			if n == 0 {
+				return 0, io.ErrShortBuffer
+			}
+			return n, nil
+		}
+
+		if units < length {
+			return n, io.EOF
+		}
+	}
+
+	return n, nil
+}
+
+// fit returns the size in bytes and units of the longest prefix of bs
+// consisting of whole units and not exceeding space bytes.
+func (lob LobLocator) fit(bs []byte, space int) (int, int) {
+	if lob.Type == TDS_LOBLOC_BINARY {
+		if len(bs) > space {
+			return space, space
+		}
+		return len(bs), len(bs)
+	}
+
+	size, units := 0, 0
+	for size < len(bs) {
+		_, runeSize := utf8.DecodeRune(bs[size:])
+		if size+runeSize > space {
+			break
+		}
+		size += runeSize
+		units++
+	}
+
+	return size, units
+}
+
+// WriteAtContext writes p into the LOB starting at off and returns the
+// number of bytes written. Unlike io.WriterAt it takes a context, as
+// each call is a request to the server.
+//
+// As with Substring off counts bytes for binary LOBs and characters
+// otherwise, in which case p must be UTF-8 encoded.
+func (lob LobLocator) WriteAtContext(ctx context.Context, p []byte, off int64) (int, error) {
+	if lob.tdsChan != nil && lob.tdsChan.tdsConn.info.ReadOnly {
+		return 0, ReadOnlyError{Request: "write to LOB " + lob.Literal()}
+	}
+
+	n := 0
+	for n < len(p) {
+		end := n + lobLocatorChunkSize
+		if end > len(p) {
+			end = len(p)
+		}
+
+		units := end - n
+		if lob.Type != TDS_LOBLOC_BINARY {
+			// Do not split characters between chunks.
+			for end < len(p) && end > n+1 && !utf8.RuneStart(p[end]) {
+				end--
+			}
+			units = utf8.RuneCount(p[n:end])
+		}
+
+		cmd := fmt.Sprintf("select setdata(%s, %d, %s)", lob.Literal(), off+1, lob.valueLiteral(p[n:end]))
+		if _, err := lob.query(ctx, cmd); err != nil {
+			return n, fmt.Errorf("error writing to LOB: %w", err)
+		}
+
+		n = end
+		off += int64(units)
+	}
+
+	return n, nil
+}
+
+// Free deallocates the locator on the server.
+func (lob LobLocator) Free(ctx context.Context) error {
+	if lob.tdsChan == nil {
+		return ErrLobLocatorUnbound
+	}
+
+	if err := lob.tdsChan.execLanguage(ctx, "deallocate locator "+lob.Literal(), nil); err != nil {
+		return fmt.Errorf("error deallocating LOB locator: %w", err)
+	}
+
+	return nil
+}
+
+func (lob LobLocator) valueLiteral(p []byte) string {
+	if lob.Type == TDS_LOBLOC_BINARY {
+		return "0x" + hex.EncodeToString(p)
+	}
+
+	return "'" + strings.ReplaceAll(string(p), "'", "''") + "'"
+}
+
+// query executes cmd and returns the value of the first column of the
+// first row.
+func (lob LobLocator) query(ctx context.Context, cmd string) (interface{}, error) {
+	if lob.tdsChan == nil {
+		return nil, ErrLobLocatorUnbound
+	}
+
+	var value interface{}
+	received := false
+	err := lob.tdsChan.execLanguage(ctx, cmd, func(pkg Package) error {
+		row, ok := pkg.(*RowPackage)
+		if !ok || received || len(row.DataFields) == 0 {
+			return nil
+		}
+
+		value = row.DataFields[0].Value()
+		received = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !received {
+		return nil, errors.New("received no row")
+	}
+
+	return value, nil
+}
+
+func (lob LobLocator) String() string {
+	return fmt.Sprintf("%T(%s, %x)", lob, lob.Type, lob.Locator)
+}
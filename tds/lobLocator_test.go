@@ -0,0 +1,332 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tds_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/SAP/go-dblib/asetypes"
+	"github.com/SAP/go-dblib/tds"
+	"github.com/SAP/go-dblib/tds/tdstest"
+)
+
+// lobServer emulates the locator functions on a single LOB. Offsets
+// and lengths count bytes or, if text is set, characters.
+type lobServer struct {
+	sync.Mutex
+	literal string
+	text    bool
+	data    []byte
+}
+
+var (
+	lobSubstringRe = regexp.MustCompile(`^select substring\((.+), (\d+), (\d+)\)$`)
+	lobSetdataRe   = regexp.MustCompile(`^select setdata\((.+), (\d+), (?:0x([0-9a-f]*)|'(.*)')\)$`)
+)
+
+// units splits data into bytes or characters.
+func (lob *lobServer) units(data []byte) [][]byte {
+	units := [][]byte{}
+	for len(data) > 0 {
+		size := 1
+		if lob.text {
+			_, size = utf8.DecodeRune(data)
+		}
+		units = append(units, data[:size])
+		data = data[size:]
+	}
+	return units
+}
+
+func (lob *lobServer) handle(request []tds.Package) ([]tds.Package, bool) {
+	lang, ok := request[0].(*tds.LanguagePackage)
+	if !ok {
+		return nil, false
+	}
+
+	lob.Lock()
+	defer lob.Unlock()
+
+	var column tdstest.Column
+	var value interface{}
+
+	units := lob.units(lob.data)
+
+	switch {
+	case lang.Cmd == "select datalength("+lob.literal+")" && !lob.text,
+		lang.Cmd == "select char_length("+lob.literal+")" && lob.text:
+		column = tdstest.Column{Name: "length", DataType: asetypes.INT4}
+		value = int32(len(units))
+	case lobSubstringRe.MatchString(lang.Cmd):
+		match := lobSubstringRe.FindStringSubmatch(lang.Cmd)
+		if match[1] != lob.literal {
+			return nil, false
+		}
+		start, _ := strconv.Atoi(match[2])
+		length, _ := strconv.Atoi(match[3])
+
+		start--
+		if start > len(units) {
+			start = len(units)
+		}
+		end := start + length
+		if end > len(units) {
+			end = len(units)
+		}
+
+		column = tdstest.Column{Name: "substring", DataType: asetypes.LONGBINARY}
+		value = bytes.Join(units[start:end], nil)
+		if lob.text {
+			column.DataType = asetypes.LONGCHAR
+			value = string(value.([]byte))
+		}
+	case lobSetdataRe.MatchString(lang.Cmd):
+		match := lobSetdataRe.FindStringSubmatch(lang.Cmd)
+		if match[1] != lob.literal {
+			return nil, false
+		}
+		start, _ := strconv.Atoi(match[2])
+
+		var bs []byte
+		if lob.text {
+			bs = []byte(strings.ReplaceAll(match[4], "''", "'"))
+		} else {
+			var err error
+			if bs, err = hex.DecodeString(match[3]); err != nil {
+				return nil, false
+			}
+		}
+
+		start--
+		written := lob.units(bs)
+		if end := start + len(written); end > len(units) {
+			units = append(units, make([][]byte, end-len(units))...)
+		}
+		copy(units[start:], written)
+		lob.data = bytes.Join(units, nil)
+
+		column = tdstest.Column{Name: "setdata", DataType: asetypes.INT4}
+		value = int32(len(written))
+	default:
+		return nil, false
+	}
+
+	response, err := tdstest.Result([]tdstest.Column{column}, []interface{}{value})
+	if err != nil {
+		return nil, false
+	}
+	return response, true
+}
+
+// receiveLobLocator registers lob with server and returns locator as
+// received in a result.
+func receiveLobLocator(ctx context.Context, t *testing.T, server *tdstest.Server, locator *tds.LobLocator, lob *lobServer) *tds.LobLocator {
+	lob.literal = locator.Literal()
+	server.HandleFunc(lob.handle)
+
+	fieldFmt, fieldData, err := tds.LookupFieldFmtData(asetypes.BLOB)
+	if err != nil {
+		t.Fatalf("error looking up BLOB: %v", err)
+	}
+	fieldFmt.SetName("lob")
+	fieldFmt.(*tds.BlobFieldFmt).SetBlobType(locator.Type)
+	fieldData.SetValue(locator)
+
+	server.HandleLanguage("select lob from t",
+		tds.NewRowFmtPackage(true, fieldFmt),
+		tds.NewRowPackage(fieldData),
+		&tds.DonePackage{Status: tds.TDS_DONE_COUNT, Count: 1},
+	)
+
+	conn, ch, err := server.Connect(ctx)
+	if err != nil {
+		t.Fatalf("error connecting: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	if err := ch.SendPackage(ctx, &tds.LanguagePackage{Cmd: "select lob from t"}); err != nil {
+		t.Fatalf("error sending query: %v", err)
+	}
+
+	var received *tds.LobLocator
+	_, err = ch.NextPackageUntil(ctx, true, func(pkg tds.Package) (bool, error) {
+		switch typed := pkg.(type) {
+		case *tds.RowPackage:
+			received, _ = typed.DataFields[0].Value().(*tds.LobLocator)
+		case *tds.DonePackage:
+			return typed.Status == tds.TDS_DONE_FINAL, nil
+		}
+		return false, nil
+	})
+	if err != nil {
+		t.Fatalf("error reading result: %v", err)
+	}
+
+	if received == nil {
+		t.Fatalf("received no locator")
+	}
+
+	if !bytes.Equal(received.Locator, locator.Locator) || !bytes.Equal(received.Prefetched, locator.Prefetched) {
+		t.Fatalf("received unexpected locator: %v", received)
+	}
+
+	return received
+}
+
+func TestLobLocator(t *testing.T) {
+	server, err := tdstest.NewServer("user", "pass")
+	if err != nil {
+		t.Fatalf("error creating server: %v", err)
+	}
+	defer server.Close()
+
+	locator := &tds.LobLocator{
+		Type:       tds.TDS_LOBLOC_BINARY,
+		Locator:    []byte("loc1"),
+		Prefetched: []byte("0123"),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	received := receiveLobLocator(ctx, t, server, locator, &lobServer{data: []byte("0123456789")})
+
+	length, err := received.Length(ctx)
+	if err != nil {
+		t.Fatalf("error reading length: %v", err)
+	}
+	if length != 10 {
+		t.Errorf("received unexpected length %d", length)
+	}
+
+	if _, err := received.WriteAtContext(ctx, []byte("abc"), 8); err != nil {
+		t.Fatalf("error writing: %v", err)
+	}
+
+	cases := map[string]struct {
+		offset   int64
+		length   int
+		expected []byte
+		err      error
+	}{
+		"start": {
+			length:   4,
+			expected: []byte("0123"),
+		},
+		"written": {
+			offset:   6,
+			length:   5,
+			expected: []byte("67abc"),
+		},
+		"past end": {
+			offset:   9,
+			length:   4,
+			expected: []byte("bc"),
+			err:      io.EOF,
+		},
+	}
+
+	for title, cas := range cases {
+		t.Run(title, func(t *testing.T) {
+			p := make([]byte, cas.length)
+			n, err := received.ReadAtContext(ctx, p, cas.offset)
+			if !errors.Is(err, cas.err) {
+				t.Fatalf("received unexpected error: %v", err)
+			}
+
+			if !bytes.Equal(p[:n], cas.expected) {
+				t.Errorf("received unexpected data:\nexpected: %q\nreceived: %q", cas.expected, p[:n])
+			}
+		})
+	}
+
+	if _, err := locator.Length(ctx); !errors.Is(err, tds.ErrLobLocatorUnbound) {
+		t.Errorf("expected ErrLobLocatorUnbound for unbound locator, received: %v", err)
+	}
+}
+
+func TestLobLocator_Text(t *testing.T) {
+	server, err := tdstest.NewServer("user", "pass")
+	if err != nil {
+		t.Fatalf("error creating server: %v", err)
+	}
+	defer server.Close()
+
+	locator := &tds.LobLocator{
+		Type:       tds.TDS_LOBLOC_UNICHAR,
+		Locator:    []byte("loc2"),
+		Prefetched: []byte("äö"),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	received := receiveLobLocator(ctx, t, server, locator, &lobServer{text: true, data: []byte("äöüß€abc")})
+
+	length, err := received.Length(ctx)
+	if err != nil {
+		t.Fatalf("error reading length: %v", err)
+	}
+	if length != 8 {
+		t.Errorf("received unexpected length %d", length)
+	}
+
+	if _, err := received.WriteAtContext(ctx, []byte("x'€"), 6); err != nil {
+		t.Fatalf("error writing: %v", err)
+	}
+
+	cases := map[string]struct {
+		offset   int64
+		length   int
+		expected []byte
+		err      error
+	}{
+		"start": {
+			length:   4,
+			expected: []byte("äö"),
+		},
+		"whole characters": {
+			offset:   2,
+			length:   6,
+			expected: []byte("üß"),
+		},
+		"written": {
+			offset:   4,
+			length:   12,
+			expected: []byte("€ax'€"),
+			err:      io.EOF,
+		},
+		"short buffer": {
+			offset: 4,
+			length: 2,
+			err:    io.ErrShortBuffer,
+		},
+	}
+
+	for title, cas := range cases {
+		t.Run(title, func(t *testing.T) {
+			p := make([]byte, cas.length)
+			n, err := received.ReadAtContext(ctx, p, cas.offset)
+			if !errors.Is(err, cas.err) {
+				t.Fatalf("received unexpected error: %v", err)
+			}
+
+			if !bytes.Equal(p[:n], cas.expected) {
+				t.Errorf("received unexpected data:\nexpected: %q\nreceived: %q", cas.expected, p[:n])
+			}
+		})
+	}
+}
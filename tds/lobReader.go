@@ -0,0 +1,338 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tds
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// lobReaderChunkSize is the maximum number of bytes passed to
+// a LobReader at once.
+const lobReaderChunkSize = 8192
+
+// errLobStream is returned by RowPackage.ReadFrom if the data of
+// a field is streamed instead of read.
+var errLobStream = errors.New("field data is streamed")
+
+// ErrLobReaderClosed is returned by LobReader.Read after the reader was
+// closed.
+var ErrLobReaderClosed = errors.New("LOB reader is closed")
+
+// LobReader is the value of TEXT, IMAGE, UNITEXT and XML fields if
+// Info.StreamLobs is set.
+//
+// The data is passed to the reader as it is received. Data that was not
+// read yet is buffered up to Info.ChannelPackageQueueSize times
+// 8 KiB. Once the buffer is full receiving packages on the connection
+// waits for the reader, as it does for unread packages.
+//
+// The values of the fields following the field in the row are only set
+// once the reader returned io.EOF.
+//
+// Calling NextPackage closes all readers of the last row returned.
+type LobReader struct {
+	length int
+
+	// lock guards chunks, buffered, finished and trailing, which are
+	// written by the goroutine receiving packages. ready is signalled
+	// when data was added or the reader was finished, space when data
+	// was read.
+	lock     *sync.Mutex
+	chunks   [][]byte
+	buffered int
+	limit    int
+	finished bool
+	ready    chan struct{}
+	space    chan struct{}
+	// chunk is the chunk currently being read.
+	chunk []byte
+
+	// fields are the fields of the row, in which trailing is set from
+	// offset on when the reader returns io.EOF. trailing are the
+	// fields following the field up to and including the next
+	// streamed field.
+	fields   []FieldData
+	offset   int
+	trailing []FieldData
+
+	closed    chan struct{}
+	closeOnce *sync.Once
+	// discard is closed once all readers of the row are closed and
+	// done when the connection is closed.
+	discard <-chan struct{}
+	done    <-chan struct{}
+}
+
+func newLobReader(length int, stream *lobStream) *LobReader {
+	return &LobReader{
+		length:    length,
+		lock:      &sync.Mutex{},
+		limit:     stream.queueSize * lobReaderChunkSize,
+		ready:     make(chan struct{}, 1),
+		space:     make(chan struct{}, 1),
+		closed:    make(chan struct{}),
+		closeOnce: &sync.Once{},
+		discard:   stream.discard,
+		done:      stream.done,
+	}
+}
+
+// Len returns the length of the data in bytes.
+func (reader *LobReader) Len() int {
+	return reader.length
+}
+
+// Read implements the io.Reader interface.
+func (reader *LobReader) Read(p []byte) (int, error) {
+	for len(reader.chunk) == 0 {
+		select {
+		case <-reader.closed:
+			return 0, ErrLobReaderClosed
+		case <-reader.discard:
+			return 0, ErrLobReaderClosed
+		default:
+		}
+
+		reader.lock.Lock()
+		chunks, finished, trailing := len(reader.chunks), reader.finished, reader.trailing
+		if chunks > 0 {
+			reader.chunk = reader.chunks[0]
+			reader.chunks[0] = nil
+			reader.chunks = reader.chunks[1:]
+			reader.buffered -= len(reader.chunk)
+		} else if finished {
+			reader.trailing = nil
+		}
+		reader.lock.Unlock()
+
+		if chunks > 0 {
+			signal(reader.space)
+			break
+		}
+
+		if finished {
+			// The fields are set by the goroutine reading the row, as
+			// it may access them concurrently.
+			copy(reader.fields[reader.offset:], trailing)
+			return 0, io.EOF
+		}
+
+		select {
+		case <-reader.closed:
+			return 0, ErrLobReaderClosed
+		case <-reader.discard:
+			return 0, ErrLobReaderClosed
+		case <-reader.ready:
+		}
+	}
+
+	n := copy(p, reader.chunk)
+	reader.chunk = reader.chunk[n:]
+	return n, nil
+}
+
+// Close discards the unread data.
+func (reader *LobReader) Close() error {
+	reader.closeOnce.Do(func() {
+		close(reader.closed)
+
+		reader.lock.Lock()
+		reader.chunks = nil
+		reader.buffered = 0
+		reader.lock.Unlock()
+	})
+	return nil
+}
+
+func (reader LobReader) String() string {
+	return fmt.Sprintf("%T(%d)", reader, reader.length)
+}
+
+// feed buffers chunk for the reader, waiting while the buffer is full.
+// The chunk is discarded if the reader was closed.
+func (reader *LobReader) feed(chunk []byte) {
+	select {
+	case <-reader.closed:
+		return
+	case <-reader.discard:
+		return
+	default:
+	}
+
+	for {
+		reader.lock.Lock()
+		full := reader.buffered >= reader.limit
+		if !full {
+			reader.chunks = append(reader.chunks, chunk)
+			reader.buffered += len(chunk)
+		}
+		reader.lock.Unlock()
+
+		if !full {
+			signal(reader.ready)
+			return
+		}
+
+		select {
+		case <-reader.closed:
+			return
+		case <-reader.discard:
+			return
+		case <-reader.done:
+			return
+		case <-reader.space:
+		}
+	}
+}
+
+// finish signals the end of the data to the reader and passes the
+// fields following the field in the row.
+func (reader *LobReader) finish(trailing []FieldData) {
+	reader.lock.Lock()
+	reader.finished = true
+	reader.trailing = trailing
+	reader.lock.Unlock()
+
+	signal(reader.ready)
+}
+
+// signal wakes up a goroutine waiting on ch.
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// lobStream is the state of a row with streamed fields.
+//
+// Once a field is streamed the row is passed on and the remaining
+// fields are read into fields, a copy owned by the goroutine receiving
+// packages. The fields are handed to the readers of the row when their
+// data was passed.
+type lobStream struct {
+	row *RowPackage
+	// rowFields are the fields of the row and fields the copy the
+	// remaining fields are read into.
+	rowFields []FieldData
+	fields    *ParamsPackage
+	// reader is the reader of the field currently being streamed and
+	// remaining is the number of bytes not yet passed to the reader.
+	reader    *LobReader
+	remaining int
+
+	// queueSize is the number of chunks buffered by readers.
+	queueSize int
+
+	discard     chan struct{}
+	discardOnce *sync.Once
+	done        <-chan struct{}
+}
+
+func newLobStream(row *RowPackage, queueSize int, done <-chan struct{}) *lobStream {
+	stream := &lobStream{
+		row:         row,
+		queueSize:   queueSize,
+		discard:     make(chan struct{}),
+		discardOnce: &sync.Once{},
+		done:        done,
+	}
+	row.lobStream = stream
+	return stream
+}
+
+// detach starts streaming the first streamed field of the row. The
+// remaining fields are read into copies, as the row is passed on.
+func (stream *lobStream) detach() error {
+	row := stream.row
+
+	fields := make([]FieldData, len(row.DataFields))
+	for i := row.fieldIndex; i < len(fields); i++ {
+		field, err := LookupFieldData(row.DataFields[i].Format())
+		if err != nil {
+			return fmt.Errorf("error copying field %d: %w", i, err)
+		}
+		fields[i] = field
+	}
+
+	stream.rowFields = row.DataFields
+	stream.fields = &ParamsPackage{
+		DataFields: fields,
+		lobStream:  stream,
+		fieldIndex: row.fieldIndex,
+	}
+	stream.start(row.streamed, row.fieldIndex)
+	return nil
+}
+
+// start starts streaming the data to reader, whose following fields
+// start at offset.
+func (stream *lobStream) start(reader *LobReader, offset int) {
+	reader.fields = stream.rowFields
+	reader.offset = offset
+	stream.reader = reader
+	stream.remaining = reader.Len()
+}
+
+// closeReaders discards the data of all readers of the row.
+func (stream *lobStream) closeReaders() {
+	stream.discardOnce.Do(func() {
+		close(stream.discard)
+	})
+}
+
+// continueLobStream passes the available data of the streamed field to
+// its reader and parses the remaining fields of the row once all data
+// was passed.
+//
+// continueLobStream returns false if more data is required.
+func (tdsChan *Channel) continueLobStream() bool {
+	stream := tdsChan.lobStream
+
+	if stream.remaining > 0 {
+		length := stream.remaining
+		if length > lobReaderChunkSize {
+			length = lobReaderChunkSize
+		}
+
+		chunk := tdsChan.queueRx.AvailableBytes(length)
+		if len(chunk) == 0 {
+			return false
+		}
+		stream.remaining -= len(chunk)
+
+		stream.reader.feed(chunk)
+		return true
+	}
+
+	reader := stream.reader
+	err := stream.fields.ReadFrom(tdsChan.queueRx)
+	if errors.Is(err, ErrNotEnoughBytes) {
+		return false
+	}
+
+	// The reader is only finished once the following fields are read,
+	// which are handed to it to be set when the reader returns io.EOF.
+	if errors.Is(err, errLobStream) {
+		next := stream.fields.fieldIndex
+		reader.finish(stream.fields.DataFields[reader.offset:next])
+		stream.start(stream.fields.streamed, next)
+		return true
+	}
+
+	tdsChan.lobStream = nil
+
+	if err != nil {
+		reader.finish(nil)
+		tdsChan.errCh <- fmt.Errorf("error parsing package %T: %w", stream.row, err)
+		return false
+	}
+
+	reader.finish(stream.fields.DataFields[reader.offset:])
+	return true
+}
@@ -0,0 +1,343 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tds_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SAP/go-dblib/asetypes"
+	"github.com/SAP/go-dblib/tds"
+	"github.com/SAP/go-dblib/tds/tdstest"
+)
+
+func TestLobReader(t *testing.T) {
+	server, err := tdstest.NewServer("user", "pass")
+	if err != nil {
+		t.Fatalf("error creating server: %v", err)
+	}
+	defer server.Close()
+
+	text := strings.Repeat("0123456789", 2000)
+	image := bytes.Repeat([]byte{0xde, 0xad, 0xbe, 0xef}, 3000)
+
+	columns := []tdstest.Column{
+		{Name: "id", DataType: asetypes.INT4},
+		{Name: "text", DataType: asetypes.TEXT},
+		{Name: "image", DataType: asetypes.IMAGE},
+		{Name: "flag", DataType: asetypes.INT4},
+	}
+	response, err := tdstest.Result(columns,
+		[]interface{}{int32(1), text, image, int32(2)},
+		[]interface{}{int32(3), "short", []byte{0x01}, int32(4)},
+	)
+	if err != nil {
+		t.Fatalf("error creating result: %v", err)
+	}
+	server.HandleLanguage("select * from lobs", response...)
+
+	cases := map[string]struct {
+		// read is the number of streamed fields read per row
+		read     int
+		expected [][]interface{}
+	}{
+		"read": {
+			read: 2,
+			expected: [][]interface{}{
+				{int32(1), []byte(text), image, int32(2)},
+				{int32(3), []byte("short"), []byte{0x01}, int32(4)},
+			},
+		},
+		"partially read": {
+			read: 1,
+			expected: [][]interface{}{
+				{int32(1), []byte(text)},
+				{int32(3), []byte("short")},
+			},
+		},
+		"discarded": {
+			expected: [][]interface{}{
+				{int32(1)},
+				{int32(3)},
+			},
+		},
+	}
+
+	for title, cas := range cases {
+		t.Run(title, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			info, err := server.Info()
+			if err != nil {
+				t.Fatalf("error getting info: %v", err)
+			}
+			info.StreamLobs = true
+
+			conn, err := tds.NewConn(ctx, info)
+			if err != nil {
+				t.Fatalf("error opening connection: %v", err)
+			}
+			defer conn.Close()
+
+			ch, err := conn.NewChannel()
+			if err != nil {
+				t.Fatalf("error opening channel: %v", err)
+			}
+
+			config, err := tds.NewLoginConfig(info)
+			if err != nil {
+				t.Fatalf("error creating login config: %v", err)
+			}
+
+			if err := ch.Login(ctx, config); err != nil {
+				t.Fatalf("error logging in: %v", err)
+			}
+
+			if err := ch.SendPackage(ctx, &tds.LanguagePackage{Cmd: "select * from lobs"}); err != nil {
+				t.Fatalf("error sending query: %v", err)
+			}
+
+			received := [][]interface{}{}
+			_, err = ch.NextPackageUntil(ctx, true, func(pkg tds.Package) (bool, error) {
+				switch typed := pkg.(type) {
+				case *tds.RowPackage:
+					// Accessing the fields following a streamed field
+					// before its data was read must not race with
+					// reading the row.
+					for _, field := range typed.DataFields[1:] {
+						_ = field.Value()
+					}
+
+					row := []interface{}{typed.DataFields[0].Value()}
+					for i := 1; i <= cas.read; i++ {
+						reader, ok := typed.DataFields[i].Value().(*tds.LobReader)
+						if !ok {
+							t.Fatalf("field %d is of type %T instead of *tds.LobReader", i, typed.DataFields[i].Value())
+						}
+
+						bs, err := io.ReadAll(reader)
+						if err != nil {
+							t.Fatalf("error reading field %d: %v", i, err)
+						}
+						row = append(row, bs)
+					}
+
+					// The fields following a streamed field are set once the
+					// data of the streamed field was read.
+					if cas.read == 2 {
+						row = append(row, typed.DataFields[3].Value())
+					}
+
+					received = append(received, row)
+				case *tds.DonePackage:
+					return typed.Status == tds.TDS_DONE_FINAL, nil
+				}
+				return false, nil
+			})
+			if err != nil {
+				t.Fatalf("error reading result: %v", err)
+			}
+
+			if !reflect.DeepEqual(received, cas.expected) {
+				t.Errorf("received unexpected rows:\nexpected: %v\nreceived: %v", cas.expected, received)
+			}
+		})
+	}
+}
+
+func TestLobReader_SlowReader(t *testing.T) {
+	server, err := tdstest.NewServer("user", "pass")
+	if err != nil {
+		t.Fatalf("error creating server: %v", err)
+	}
+	defer server.Close()
+
+	text := strings.Repeat("0123456789", 20000)
+	response, err := tdstest.Result([]tdstest.Column{{Name: "text", DataType: asetypes.TEXT}}, []interface{}{text})
+	if err != nil {
+		t.Fatalf("error creating result: %v", err)
+	}
+	server.HandleLanguage("select text from lobs", response...)
+
+	single, err := tdstest.Result([]tdstest.Column{{Name: "a", DataType: asetypes.INT4}}, []interface{}{int32(1)})
+	if err != nil {
+		t.Fatalf("error creating result: %v", err)
+	}
+	server.HandleLanguage("select 1 as a", single...)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	info, err := server.Info()
+	if err != nil {
+		t.Fatalf("error getting info: %v", err)
+	}
+	info.StreamLobs = true
+
+	conn, err := tds.NewConn(ctx, info)
+	if err != nil {
+		t.Fatalf("error opening connection: %v", err)
+	}
+	defer conn.Close()
+
+	ch, err := conn.NewChannel()
+	if err != nil {
+		t.Fatalf("error opening channel: %v", err)
+	}
+
+	config, err := tds.NewLoginConfig(info)
+	if err != nil {
+		t.Fatalf("error creating login config: %v", err)
+	}
+
+	if err := ch.Login(ctx, config); err != nil {
+		t.Fatalf("error logging in: %v", err)
+	}
+
+	logical, err := conn.NewChannel()
+	if err != nil {
+		t.Fatalf("error opening logical channel: %v", err)
+	}
+
+	if err := ch.SendPackage(ctx, &tds.LanguagePackage{Cmd: "select text from lobs"}); err != nil {
+		t.Fatalf("error sending query: %v", err)
+	}
+
+	var reader *tds.LobReader
+	_, err = ch.NextPackageUntil(ctx, true, func(pkg tds.Package) (bool, error) {
+		row, ok := pkg.(*tds.RowPackage)
+		if ok {
+			reader, _ = row.DataFields[0].Value().(*tds.LobReader)
+		}
+		return ok, nil
+	})
+	if err != nil || reader == nil {
+		t.Fatalf("error reading row: %v", err)
+	}
+
+	// The unread LOB must not block the responses on other channels.
+	if err := logical.SendPackage(ctx, &tds.LanguagePackage{Cmd: "select 1 as a"}); err != nil {
+		t.Fatalf("error sending query: %v", err)
+	}
+
+	if _, err := logical.NextPackageUntil(ctx, true, func(pkg tds.Package) (bool, error) {
+		done, ok := pkg.(*tds.DonePackage)
+		return ok && done.Status == tds.TDS_DONE_FINAL, nil
+	}); err != nil {
+		t.Fatalf("error reading response on logical channel: %v", err)
+	}
+
+	bs, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("error reading LOB: %v", err)
+	}
+
+	if string(bs) != text {
+		t.Errorf("received unexpected LOB of length %d", len(bs))
+	}
+}
+
+func TestLobReader_Backpressure(t *testing.T) {
+	server, err := tdstest.NewServer("user", "pass")
+	if err != nil {
+		t.Fatalf("error creating server: %v", err)
+	}
+	defer server.Close()
+
+	text := strings.Repeat("0123456789", 20000)
+	response, err := tdstest.Result([]tdstest.Column{
+		{Name: "text", DataType: asetypes.TEXT},
+		{Name: "flag", DataType: asetypes.INT4},
+	}, []interface{}{text, int32(1)})
+	if err != nil {
+		t.Fatalf("error creating result: %v", err)
+	}
+	server.HandleLanguage("select text, flag from lobs", response...)
+
+	single, err := tdstest.Result([]tdstest.Column{{Name: "a", DataType: asetypes.INT4}}, []interface{}{int32(1)})
+	if err != nil {
+		t.Fatalf("error creating result: %v", err)
+	}
+	server.HandleLanguage("select 1 as a", single...)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	info, err := server.Info()
+	if err != nil {
+		t.Fatalf("error getting info: %v", err)
+	}
+	info.StreamLobs = true
+	// Buffer less than the LOB
+	info.ChannelPackageQueueSize = 4
+
+	conn, ch, err := server.ConnectInfo(ctx, info)
+	if err != nil {
+		t.Fatalf("error connecting: %v", err)
+	}
+	defer conn.Close()
+
+	logical, err := conn.NewChannel()
+	if err != nil {
+		t.Fatalf("error opening logical channel: %v", err)
+	}
+
+	if err := ch.SendPackage(ctx, &tds.LanguagePackage{Cmd: "select text, flag from lobs"}); err != nil {
+		t.Fatalf("error sending query: %v", err)
+	}
+
+	var row *tds.RowPackage
+	_, err = ch.NextPackageUntil(ctx, true, func(pkg tds.Package) (bool, error) {
+		var ok bool
+		row, ok = pkg.(*tds.RowPackage)
+		return ok, nil
+	})
+	if err != nil {
+		t.Fatalf("error reading row: %v", err)
+	}
+
+	reader, ok := row.DataFields[0].Value().(*tds.LobReader)
+	if !ok {
+		t.Fatalf("field is of type %T instead of *tds.LobReader", row.DataFields[0].Value())
+	}
+
+	if err := logical.SendPackage(ctx, &tds.LanguagePackage{Cmd: "select 1 as a"}); err != nil {
+		t.Fatalf("error sending query: %v", err)
+	}
+
+	// The full buffer of the reader holds up the response on the
+	// logical channel.
+	time.Sleep(200 * time.Millisecond)
+	if _, err := logical.NextPackage(ctx, false); !errors.Is(err, tds.ErrNoPackageReady) {
+		t.Fatalf("expected response to be held up by the unread LOB, received: %v", err)
+	}
+
+	bs, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("error reading LOB: %v", err)
+	}
+
+	if string(bs) != text {
+		t.Errorf("received unexpected LOB of length %d", len(bs))
+	}
+
+	if value := row.DataFields[1].Value(); value != int32(1) {
+		t.Errorf("expected value 1 of field following the LOB, received %v", value)
+	}
+
+	if _, err := logical.NextPackageUntil(ctx, true, func(pkg tds.Package) (bool, error) {
+		done, ok := pkg.(*tds.DonePackage)
+		return ok && done.Status == tds.TDS_DONE_FINAL, nil
+	}); err != nil {
+		t.Fatalf("error reading response on logical channel: %v", err)
+	}
+}
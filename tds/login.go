@@ -30,7 +30,11 @@ func (e *loginEncryptError) Error() string {
 		return fmt.Sprintf("expected a login encryption message, but received %v", e.msgIdRecv)
 	}
 
-	return fmt.Sprintf("server only supports %s, at least On Demand Command Encryption is required: expected %v, received %v", reason, e.msgIdExpect, e.msgIdRecv)
+	if e.msgIdExpect == TDS_MSG_SEC_ENCRYPT4 {
+		return fmt.Sprintf("server only supports %s, at least On Demand Command Encryption is required: expected %v, received %v", reason, e.msgIdExpect, e.msgIdRecv)
+	}
+
+	return fmt.Sprintf("server only supports %s, the minimum allowed login encryption is %v: received %v", reason, e.msgIdExpect, e.msgIdRecv)
 }
 
 // Login performs the login negotiation with the TDS server.
@@ -39,13 +43,40 @@ func (tdsChan *Channel) Login(ctx context.Context, config *LoginConfig) error {
 		return errors.New("passed config is nil")
 	}
 
+	if tdsChan.channelId == 0 && !tdsChan.resuming {
+		// Store a copy of the configuration to resume the HA session
+		// after a failover.
+		stored := *config
+		tdsChan.tdsConn.haLock.Lock()
+		tdsChan.tdsConn.loginConfig = &stored
+		tdsChan.tdsConn.haLock.Unlock()
+	}
+
+	config, err := config.resolvePassword(ctx)
+	if err != nil {
+		return fmt.Errorf("error resolving password: %w", err)
+	}
+
+	tdsChan.loggingIn = true
+	defer func() { tdsChan.loggingIn = false }()
+	tdsChan.passwordExpired = nil
+	tdsChan.loginAck = nil
+
 	tdsChan.CurrentHeaderType = TDS_BUF_LOGIN
 
+	if config.Authenticator != nil {
+		config.Encrypt = TDS_MSG_SEC_OPAQUE
+	}
+
 	var withoutEncryption bool
 	switch config.Encrypt {
-	case TDS_MSG_SEC_ENCRYPT, TDS_MSG_SEC_ENCRYPT2, TDS_MSG_SEC_ENCRYPT3:
-		return fmt.Errorf("encryption methods below TDS_MSG_SEC_ENCRYPT4 are not supported by go-ase")
-	case TDS_MSG_SEC_ENCRYPT4:
+	case TDS_MSG_SEC_OPAQUE:
+		if config.Authenticator == nil {
+			return errors.New("TDS_MSG_SEC_OPAQUE requires an authenticator")
+		}
+	case TDS_MSG_SEC_ENCRYPT:
+		return fmt.Errorf("encryption methods below TDS_MSG_SEC_ENCRYPT2 are not supported by go-ase")
+	case TDS_MSG_SEC_ENCRYPT2, TDS_MSG_SEC_ENCRYPT3, TDS_MSG_SEC_ENCRYPT4:
 		withoutEncryption = false
 	default:
 		withoutEncryption = true
@@ -54,12 +85,10 @@ func (tdsChan *Channel) Login(ctx context.Context, config *LoginConfig) error {
 	// Add servername/password combination to remote servers
 	// The first 'remote' server is the current server with an empty
 	// server name.
+	// The configuration is not modified as it is reused for logins
+	// resuming the session.
 	firstRemoteServer := LoginConfigRemoteServer{Name: "", Password: config.DSN.Password}
-	if len(config.RemoteServers) == 0 {
-		config.RemoteServers = []LoginConfigRemoteServer{firstRemoteServer}
-	} else {
-		config.RemoteServers = append([]LoginConfigRemoteServer{firstRemoteServer}, config.RemoteServers...)
-	}
+	remoteServers := append([]LoginConfigRemoteServer{firstRemoteServer}, config.RemoteServers...)
 
 	pack, err := config.pack()
 	if err != nil {
@@ -74,11 +103,15 @@ func (tdsChan *Channel) Login(ctx context.Context, config *LoginConfig) error {
 		return fmt.Errorf("error adding login capabilities package: %w", err)
 	}
 
+	if config.Authenticator != nil {
+		return tdsChan.loginAuthenticator(ctx, config)
+	}
+
 	if err := tdsChan.SendRemainingPackets(ctx); err != nil {
 		return fmt.Errorf("error sending packets: %w", err)
 	}
 
-	pkg, err := tdsChan.NextPackage(ctx, true)
+	pkg, err := tdsChan.nextLoginPackage(ctx)
 	if err != nil {
 		return fmt.Errorf("error reading LoginAck package: %w", err)
 	}
@@ -92,10 +125,10 @@ func (tdsChan *Channel) Login(ctx context.Context, config *LoginConfig) error {
 		// no encryption requested, check loginack for validity and
 		// return
 		if loginack.Status != TDS_LOG_SUCCEED {
-			return fmt.Errorf("login failed: %s", loginack.Status)
+			return tdsChan.loginFailed(ctx, loginack.Status)
 		}
 
-		pkg, err = tdsChan.NextPackage(ctx, true)
+		pkg, err = tdsChan.nextLoginPackage(ctx)
 		if err != nil {
 			return fmt.Errorf("error reading Done package: %w", err)
 		}
@@ -109,7 +142,11 @@ func (tdsChan *Channel) Login(ctx context.Context, config *LoginConfig) error {
 			return fmt.Errorf("expected DONE(FINAL), received: %s", done)
 		}
 
-		return nil
+		return tdsChan.finishLogin(ctx, config)
+	}
+
+	if loginack.Status == TDS_LOG_FAIL {
+		return tdsChan.loginFailed(ctx, loginack.Status)
 	}
 
 	if loginack.Status != TDS_LOG_NEGOTIATE {
@@ -126,11 +163,30 @@ func (tdsChan *Channel) Login(ctx context.Context, config *LoginConfig) error {
 		return fmt.Errorf("expected msg package as second response, received: %s", pkg)
 	}
 
-	if negotiationMsg.MsgId != TDS_MSG_SEC_ENCRYPT4 {
-		return &loginEncryptError{msgIdExpect: TDS_MSG_SEC_ENCRYPT4, msgIdRecv: negotiationMsg.MsgId}
+	minEncrypt := config.MinEncrypt
+	if minEncrypt == 0 {
+		minEncrypt = TDS_MSG_SEC_ENCRYPT4
 	}
 
-	pkg, err = tdsChan.NextPackage(ctx, true)
+	// The message IDs of the password encryption methods are ordered by
+	// their strength.
+	encrypt := negotiationMsg.MsgId
+	switch encrypt {
+	case TDS_MSG_SEC_ENCRYPT2, TDS_MSG_SEC_ENCRYPT3, TDS_MSG_SEC_ENCRYPT4:
+		if encrypt < minEncrypt {
+			return &loginEncryptError{msgIdExpect: minEncrypt, msgIdRecv: encrypt}
+		}
+	default:
+		return &loginEncryptError{msgIdExpect: minEncrypt, msgIdRecv: encrypt}
+	}
+
+	// TDS_MSG_SEC_ENCRYPT2 sends no nonce
+	paramCount := 3
+	if encrypt == TDS_MSG_SEC_ENCRYPT2 {
+		paramCount = 2
+	}
+
+	pkg, err = tdsChan.nextLoginPackage(ctx)
 	if err != nil {
 		return fmt.Errorf("error reading ParamFmt package: %w", err)
 	}
@@ -140,12 +196,12 @@ func (tdsChan *Channel) Login(ctx context.Context, config *LoginConfig) error {
 		return fmt.Errorf("expected paramfmt package as third response, recevied: %v", pkg)
 	}
 
-	if len(paramFmt.Fmts) != 3 {
-		return fmt.Errorf("invalid paramfmt package, expected 3 fields, got %d: %v",
-			len(paramFmt.Fmts), paramFmt)
+	if len(paramFmt.Fmts) != paramCount {
+		return fmt.Errorf("invalid paramfmt package, expected %d fields, got %d: %v",
+			paramCount, len(paramFmt.Fmts), paramFmt)
 	}
 
-	pkg, err = tdsChan.NextPackage(ctx, true)
+	pkg, err = tdsChan.nextLoginPackage(ctx)
 	if err != nil {
 		return fmt.Errorf("error reading Params package: %w", err)
 	}
@@ -155,12 +211,12 @@ func (tdsChan *Channel) Login(ctx context.Context, config *LoginConfig) error {
 		return fmt.Errorf("expected params package as fourth response, received: %s", pkg)
 	}
 
-	if len(params.DataFields) != 3 {
-		return fmt.Errorf("invalid params package, expected 3 fields, got %d: %v",
-			len(params.DataFields), params)
+	if len(params.DataFields) != paramCount {
+		return fmt.Errorf("invalid params package, expected %d fields, got %d: %v",
+			paramCount, len(params.DataFields), params)
 	}
 
-	pkg, err = tdsChan.NextPackage(ctx, true)
+	pkg, err = tdsChan.nextLoginPackage(ctx)
 	if err != nil {
 		return fmt.Errorf("error reading Done package: %w", err)
 	}
@@ -191,12 +247,6 @@ func (tdsChan *Channel) Login(ctx context.Context, config *LoginConfig) error {
 		return fmt.Errorf("expected public key as second parameter, got: %#v", params.DataFields[1])
 	}
 
-	// get nonce
-	paramNonce, ok := params.DataFields[2].(*LongBinaryFieldData)
-	if !ok {
-		return fmt.Errorf("expected nonce as third parameter, got: %v", params.DataFields[2])
-	}
-
 	// encrypt password
 	paramPubKeyData, ok := paramPubKey.Value().([]byte)
 	if !ok {
@@ -204,19 +254,41 @@ func (tdsChan *Channel) Login(ctx context.Context, config *LoginConfig) error {
 			paramPubKey.Value())
 	}
 
-	paramNonceData, ok := paramNonce.Value().([]byte)
-	if !ok {
-		return fmt.Errorf("param field for nonce contains value of type %T instead of []byte",
-			paramNonce.Value())
+	// TDS_MSG_SEC_ENCRYPT2 uses PKCS#1 v1.5 padding without a nonce,
+	// later methods use OAEP and prefix the encrypted values with the
+	// nonce.
+	encryptPass := func(password []byte) ([]byte, error) {
+		return rsaEncryptPKCS1v15(paramPubKeyData, password)
 	}
+	logPwdMsg, remPwdMsg := TDS_MSG_SEC_LOGPWD2, TDS_MSG_SEC_REMPWD2
 
-	encryptedPass, err := rsaEncrypt(paramPubKeyData, paramNonceData, []byte(config.DSN.Password))
+	var paramNonceData []byte
+	if encrypt != TDS_MSG_SEC_ENCRYPT2 {
+		// get nonce
+		paramNonce, ok := params.DataFields[2].(*LongBinaryFieldData)
+		if !ok {
+			return fmt.Errorf("expected nonce as third parameter, got: %v", params.DataFields[2])
+		}
+
+		paramNonceData, ok = paramNonce.Value().([]byte)
+		if !ok {
+			return fmt.Errorf("param field for nonce contains value of type %T instead of []byte",
+				paramNonce.Value())
+		}
+
+		encryptPass = func(password []byte) ([]byte, error) {
+			return rsaEncrypt(paramPubKeyData, paramNonceData, password)
+		}
+		logPwdMsg, remPwdMsg = TDS_MSG_SEC_LOGPWD3, TDS_MSG_SEC_REMPWD3
+	}
+
+	encryptedPass, err := encryptPass([]byte(config.DSN.Password))
 	if err != nil {
 		return fmt.Errorf("error encrypting password: %w", err)
 	}
 
 	// Prepare response
-	if err := tdsChan.QueuePackage(ctx, NewMsgPackage(TDS_MSG_HASARGS, TDS_MSG_SEC_LOGPWD3)); err != nil {
+	if err := tdsChan.QueuePackage(ctx, NewMsgPackage(TDS_MSG_HASARGS, logPwdMsg)); err != nil {
 		return fmt.Errorf("error queueing message package for password transmission: %w", err)
 	}
 
@@ -235,16 +307,16 @@ func (tdsChan *Channel) Login(ctx context.Context, config *LoginConfig) error {
 		return fmt.Errorf("error queueing Params password package: %w", err)
 	}
 
-	if len(config.RemoteServers) > 0 {
+	if len(remoteServers) > 0 {
 		// encrypted remote password
-		if err := tdsChan.QueuePackage(ctx, NewMsgPackage(TDS_MSG_HASARGS, TDS_MSG_SEC_REMPWD3)); err != nil {
+		if err := tdsChan.QueuePackage(ctx, NewMsgPackage(TDS_MSG_HASARGS, remPwdMsg)); err != nil {
 			return fmt.Errorf("error queueing message package for remote servers: %w", err)
 		}
 
-		paramFmts := make([]FieldFmt, len(config.RemoteServers)*2)
-		params := make([]FieldData, len(config.RemoteServers)*2)
+		paramFmts := make([]FieldFmt, len(remoteServers)*2)
+		params := make([]FieldData, len(remoteServers)*2)
 		for i := 0; i < len(paramFmts); i += 2 {
-			remoteServer := config.RemoteServers[i/2]
+			remoteServer := remoteServers[i/2]
 
 			remnameFmt, remnameData, err := LookupFieldFmtData(asetypes.VARCHAR)
 			if err != nil {
@@ -255,8 +327,7 @@ func (tdsChan *Channel) Login(ctx context.Context, config *LoginConfig) error {
 			remnameData.SetValue([]byte(remoteServer.Name))
 			params[i] = remnameData
 
-			encryptedServerPass, err := rsaEncrypt(paramPubKeyData, paramNonceData,
-				[]byte(remoteServer.Password))
+			encryptedServerPass, err := encryptPass([]byte(remoteServer.Password))
 			if err != nil {
 				return fmt.Errorf("error encryption remote server password: %w", err)
 			}
@@ -280,58 +351,37 @@ func (tdsChan *Channel) Login(ctx context.Context, config *LoginConfig) error {
 		}
 	}
 
-	symmetricKey, err := generateSymmetricKey(tdsChan.tdsConn.odce)
-	if err != nil {
-		return fmt.Errorf("error generating session key: %w", err)
-	}
-
-	encryptedSymKey, err := rsaEncrypt(paramPubKeyData, paramNonceData, symmetricKey)
-	if err != nil {
-		return fmt.Errorf("error encrypting session key: %w", err)
-	}
-
-	if err := tdsChan.QueuePackage(ctx, NewMsgPackage(TDS_MSG_HASARGS, TDS_MSG_SEC_SYMKEY)); err != nil {
-		return fmt.Errorf("error queueing package Msg for symmetric key: %w", err)
-	}
-
-	symkeyFmt, symkeyData, err := LookupFieldFmtData(asetypes.LONGBINARY)
-	if err != nil {
-		return fmt.Errorf("failed to look up fields for LONGBINARY: %w", err)
-	}
-	symkeyData.SetValue(encryptedSymKey)
-
-	if err := tdsChan.QueuePackage(ctx, NewParamFmtPackage(false, symkeyFmt)); err != nil {
-		return fmt.Errorf("error queueing package ParamFmt for symmetric key: %w", err)
-	}
-
-	if err := tdsChan.QueuePackage(ctx, NewParamsPackage(symkeyData)); err != nil {
-		return fmt.Errorf("error queueing package Params for symmetric key: %w", err)
+	// Only TDS_MSG_SEC_ENCRYPT4 negotiates the symmetric key for On
+	// Demand Command Encryption.
+	if encrypt == TDS_MSG_SEC_ENCRYPT4 {
+		if err := tdsChan.queueSymmetricKey(ctx, paramPubKeyData, paramNonceData); err != nil {
+			return err
+		}
 	}
 
 	if err := tdsChan.SendRemainingPackets(ctx); err != nil {
 		return fmt.Errorf("error sending login payload: %w", err)
 	}
 
-	_, err = tdsChan.NextPackageUntil(ctx, true,
-		func(pkg Package) (bool, error) {
-			loginAck, ok := pkg.(*LoginAckPackage)
-			if !ok {
-				return false, nil
-			}
+	for {
+		pkg, err = tdsChan.nextLoginPackage(ctx)
+		if err != nil {
+			return fmt.Errorf("error reading LoginAck package: %w", err)
+		}
 
-			if loginAck.Status != TDS_LOG_SUCCEED {
-				return false, fmt.Errorf("expected login ack with status TDS_LOG_SUCCEED, received %s",
-					loginAck.Status)
-			}
+		loginAck, ok := pkg.(*LoginAckPackage)
+		if !ok {
+			continue
+		}
 
-			return true, nil
-		},
-	)
-	if err != nil {
-		return fmt.Errorf("error reading LoginAck package: %w", err)
+		if loginAck.Status != TDS_LOG_SUCCEED {
+			return tdsChan.loginFailed(ctx, loginAck.Status)
+		}
+
+		break
 	}
 
-	pkg, err = tdsChan.NextPackage(ctx, true)
+	pkg, err = tdsChan.nextLoginPackage(ctx)
 	if err != nil {
 		return fmt.Errorf("error reading Capability package: %w", err)
 	}
@@ -364,7 +414,7 @@ func (tdsChan *Channel) Login(ctx context.Context, config *LoginConfig) error {
 	// Override requested capabilities with server response
 	tdsChan.tdsConn.Caps = capsResponse
 
-	pkg, err = tdsChan.NextPackage(ctx, true)
+	pkg, err = tdsChan.nextLoginPackage(ctx)
 	if err != nil {
 		return fmt.Errorf("error reading Done package: %w", err)
 	}
@@ -379,7 +429,39 @@ func (tdsChan *Channel) Login(ctx context.Context, config *LoginConfig) error {
 			done.Status)
 	}
 
-	tdsChan.Reset()
+	return tdsChan.finishLogin(ctx, config)
+}
+
+// queueSymmetricKey generates the symmetric key for On Demand Command
+// Encryption and queues it encrypted with the public key of the server.
+func (tdsChan *Channel) queueSymmetricKey(ctx context.Context, pubKey, nonce []byte) error {
+	symmetricKey, err := generateSymmetricKey(tdsChan.tdsConn.odce)
+	if err != nil {
+		return fmt.Errorf("error generating session key: %w", err)
+	}
+
+	encryptedSymKey, err := rsaEncrypt(pubKey, nonce, symmetricKey)
+	if err != nil {
+		return fmt.Errorf("error encrypting session key: %w", err)
+	}
+
+	if err := tdsChan.QueuePackage(ctx, NewMsgPackage(TDS_MSG_HASARGS, TDS_MSG_SEC_SYMKEY)); err != nil {
+		return fmt.Errorf("error queueing package Msg for symmetric key: %w", err)
+	}
+
+	symkeyFmt, symkeyData, err := LookupFieldFmtData(asetypes.LONGBINARY)
+	if err != nil {
+		return fmt.Errorf("failed to look up fields for LONGBINARY: %w", err)
+	}
+	symkeyData.SetValue(encryptedSymKey)
+
+	if err := tdsChan.QueuePackage(ctx, NewParamFmtPackage(false, symkeyFmt)); err != nil {
+		return fmt.Errorf("error queueing package ParamFmt for symmetric key: %w", err)
+	}
+
+	if err := tdsChan.QueuePackage(ctx, NewParamsPackage(symkeyData)); err != nil {
+		return fmt.Errorf("error queueing package Params for symmetric key: %w", err)
+	}
 
 	return nil
 }
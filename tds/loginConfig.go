@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 )
 
 // LoginConfigRemoteServer contains the name and the password to the
@@ -23,23 +24,68 @@ type LoginConfig struct {
 	Hostname string
 
 	// TODO name
-	HostProc string
-	AppName  string
-	ServName string
+	HostProc    string
+	AppName     string
+	ServName    string
+	ProgramName string
 
 	Language string
 	CharSet  string
 
+	// PacketSize is the packet size requested from the server. The
+	// server may negotiate a different size.
+	PacketSize int
+
 	RemoteServers []LoginConfigRemoteServer
 
 	// Encrypt allows any TDSMsgId but only negotiation-relevant security
 	// bits such as TDS_MSG_SEC_ENCRYPT will be recognized.
 	Encrypt TDSMsgId
+	// MinEncrypt is the weakest password encryption accepted when the
+	// server does not offer TDS_MSG_SEC_ENCRYPT4, either
+	// TDS_MSG_SEC_ENCRYPT3 or TDS_MSG_SEC_ENCRYPT2. Defaults to
+	// TDS_MSG_SEC_ENCRYPT4 if unset.
+	MinEncrypt TDSMsgId
+
+	// NewPassword is set as the password of the login if the password
+	// has expired, otherwise Login fails with ErrPasswordExpired.
+	NewPassword string
+
+	// Authenticator authenticates the login with security tokens
+	// instead of the password if set. The login is then negotiated
+	// with TDS_MSG_SEC_OPAQUE, regardless of Encrypt.
+	Authenticator Authenticator
+
+	// HALogin requests an HA session or resumes the HA session
+	// HASessionID after a failover.
+	HALogin     HALoginStatus
+	HASessionID []byte
 }
 
+//go:generate stringer -type=HALoginStatus
+
+// HALoginStatus defines the HA options of a login.
+type HALoginStatus uint8
+
+const (
+	// Request an HA session which can be resumed after a failover
+	TDS_HA_LOG_SESSION HALoginStatus = 0x1
+	// Resume the HA session with the passed session ID
+	TDS_HA_LOG_RESUME HALoginStatus = 0x2
+	// Login to the companion server after a failover
+	TDS_HA_LOG_FAILOVERSRV HALoginStatus = 0x4
+	// Login is redirected from another server
+	TDS_HA_LOG_REDIRECT HALoginStatus = 0x8
+	// Login is part of a migration
+	TDS_HA_LOG_MIGRATE HALoginStatus = 0x10
+)
+
 // NewLoginConfig creates a new login-configuration by using dsn
 // information and setting default configuration-values in regard to the
-// ASE database server (Should be be adjusted by clients).
+// ASE database server.
+//
+// An error is returned if the options of dsn exceed the limits of the
+// login payload.
 func NewLoginConfig(dsn *Info) (*LoginConfig, error) {
 	conf := &LoginConfig{}
 
@@ -65,17 +111,113 @@ func NewLoginConfig(dsn *Info) (*LoginConfig, error) {
 		conf.ServName = conf.ServName[:30]
 	}
 
-	// Should be overwritten by clients
-	conf.AppName = "github.com/SAP/go-dblib/tds"
+	conf.AppName = dsn.AppName
+	if conf.AppName == "" {
+		conf.AppName = "github.com/SAP/go-dblib/tds"
+	}
+
+	conf.ProgramName = dsn.ProgramName
+	if conf.ProgramName == "" {
+		conf.ProgramName = libraryName
+	}
+
+	conf.Language = dsn.Language
+	if conf.Language == "" {
+		conf.Language = "us_english"
+	}
+
+	conf.CharSet = dsn.CharSet
+	if conf.CharSet == "" {
+		conf.CharSet = "utf8"
+	}
+
+	for _, opt := range []struct {
+		name, value string
+		max         int
+	}{
+		{"app-name", conf.AppName, TDS_MAXNAME},
+		{"program-name", conf.ProgramName, TDS_PROGNLEN},
+		{"language", conf.Language, TDS_MAXNAME},
+		{"charset", conf.CharSet, TDS_MAXNAME},
+	} {
+		if len(opt.value) > opt.max {
+			return nil, fmt.Errorf("%s %q exceeds %d bytes", opt.name, opt.value, opt.max)
+		}
+	}
+
+	conf.PacketSize = dsn.PacketSize
+	if conf.PacketSize == 0 {
+		conf.PacketSize = 512
+	}
+	if conf.PacketSize < 512 || conf.PacketSize > 65535 {
+		return nil, fmt.Errorf("invalid packet-size %d, must be between 512 and 65535", conf.PacketSize)
+	}
 
-	conf.CharSet = "utf8"
-	conf.Language = "us_english"
+	remoteServers, err := parseRemoteServers(dsn.RemoteServers)
+	if err != nil {
+		return nil, err
+	}
+	conf.RemoteServers = remoteServers
 
 	conf.Encrypt = TDS_MSG_SEC_ENCRYPT4
 
+	switch strings.ToLower(dsn.LoginEncryptionMin) {
+	case "", "encrypt4":
+		conf.MinEncrypt = TDS_MSG_SEC_ENCRYPT4
+	case "encrypt3":
+		conf.MinEncrypt = TDS_MSG_SEC_ENCRYPT3
+	case "encrypt2":
+		conf.MinEncrypt = TDS_MSG_SEC_ENCRYPT2
+	default:
+		return nil, fmt.Errorf("invalid login-encryption-min %q, expected one of 'encrypt4', 'encrypt3' or 'encrypt2'",
+			dsn.LoginEncryptionMin)
+	}
+
+	if dsn.KerberosServicePrincipal != "" {
+		auth, err := newKerberosAuthenticator(dsn)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set up Kerberos authentication: %w", err)
+		}
+		conf.Authenticator = auth
+	}
+
+	conf.HALogin = TDS_HA_LOG_SESSION
+
 	return conf, nil
 }
 
+// parseRemoteServers parses the remote-servers option, a comma-separated
+// list of name:password pairs.
+func parseRemoteServers(s string) ([]LoginConfigRemoteServer, error) {
+	remoteServers := []LoginConfigRemoteServer{}
+	// rempw holds the length and value of the name and the password of
+	// each remote server.
+	rempw := 0
+	for _, pair := range strings.Split(s, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+
+		name, password, ok := strings.Cut(pair, ":")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid remote server %q in remote-servers, expected name:password", pair)
+		}
+
+		if len(name) > TDS_MAXNAME {
+			return nil, fmt.Errorf("remote server name %q exceeds %d bytes", name, TDS_MAXNAME)
+		}
+
+		rempw += 2 + len(name) + len(password)
+		if rempw > TDS_RPLEN {
+			return nil, fmt.Errorf("remote-servers exceed %d bytes", TDS_RPLEN)
+		}
+
+		remoteServers = append(remoteServers, LoginConfigRemoteServer{Name: name, Password: password})
+	}
+
+	return remoteServers, nil
+}
+
 // These constants define the maximum length for various options in
 // a login payload.
 const (
@@ -107,7 +249,7 @@ func (config *LoginConfig) pack() (Package, error) {
 	// lpw, lpwnlen
 	var err error
 	switch config.Encrypt {
-	case TDS_MSG_SEC_ENCRYPT, TDS_MSG_SEC_ENCRYPT2, TDS_MSG_SEC_ENCRYPT3, TDS_MSG_SEC_ENCRYPT4:
+	case TDS_MSG_SEC_ENCRYPT, TDS_MSG_SEC_ENCRYPT2, TDS_MSG_SEC_ENCRYPT3, TDS_MSG_SEC_ENCRYPT4, TDS_MSG_SEC_OPAQUE:
 		err = writeString(buf, "", TDS_MAXNAME)
 	default:
 		err = writeString(buf, config.DSN.Password, TDS_MAXNAME)
@@ -206,7 +348,7 @@ func (config *LoginConfig) pack() (Package, error) {
 	}
 
 	// lprogname, lprognlen
-	if err := writeString(buf, libraryName, TDS_PROGNLEN); err != nil {
+	if err := writeString(buf, config.ProgramName, TDS_PROGNLEN); err != nil {
 		return nil, fmt.Errorf("error writing progname: %w", err)
 	}
 
@@ -253,6 +395,9 @@ func (config *LoginConfig) pack() (Package, error) {
 		err = buf.WriteByte(0x1 | 0x20)
 	case TDS_MSG_SEC_ENCRYPT3, TDS_MSG_SEC_ENCRYPT4:
 		err = buf.WriteByte(0x1 | 0x20 | 0x80)
+	case TDS_MSG_SEC_OPAQUE:
+		// TDS_SEC_LOG_SECSESS
+		err = buf.WriteByte(0x10)
 	default:
 		err = buf.WriteByte(0x0)
 	}
@@ -266,16 +411,17 @@ func (config *LoginConfig) pack() (Package, error) {
 	}
 
 	// lhalogin
-	// TODO - values need to be determined by config to allow for
-	// failover reconnects in clusters
-	if err := buf.WriteByte(1); err != nil {
-		return nil, fmt.Errorf("error writing ailover: %w", err)
+	if err := buf.WriteByte(byte(config.HALogin)); err != nil {
+		return nil, fmt.Errorf("error writing halogin: %w", err)
 	}
 
 	// lhasessionid
-	// TODO session id for HA failover, find out if this needs to be
-	// user set or retrieved from the server
-	if _, err := buf.Write(make([]byte, TDS_HA)); err != nil {
+	if len(config.HASessionID) > TDS_HA {
+		return nil, fmt.Errorf("HA session ID exceeds %d bytes", TDS_HA)
+	}
+	haSessionID := make([]byte, TDS_HA)
+	copy(haSessionID, config.HASessionID)
+	if _, err := buf.Write(haSessionID); err != nil {
 		return nil, fmt.Errorf("error writing hasessionid: %w", err)
 	}
 
@@ -295,9 +441,9 @@ func (config *LoginConfig) pack() (Package, error) {
 		return nil, fmt.Errorf("error writing setcharset: %w", err)
 	}
 
-	// lpacketsize - 256 to 65535 bytes
-	// Write default packet size - will be renegotiated anyhow.
-	if err := writeString(buf, "512", TDS_PKTLEN); err != nil {
+	// lpacketsize - 512 to 65535 bytes
+	// The server may negotiate a different packet size.
+	if err := writeString(buf, strconv.Itoa(config.PacketSize), TDS_PKTLEN); err != nil {
 		return nil, fmt.Errorf("error writing packetsize: %w", err)
 	}
 
@@ -308,3 +454,108 @@ func (config *LoginConfig) pack() (Package, error) {
 
 	return &TokenlessPackage{Data: buf}, nil
 }
+
+// ParseLoginConfig reads a login payload as written by the client from
+// ch and returns the contained configuration.
+//
+// Only fields that are represented in LoginConfig are parsed, all
+// other fields are skipped.
+// ParseLoginConfig is intended for implementing TDS servers, e.g. for
+// testing.
+func ParseLoginConfig(ch BytesChannel) (*LoginConfig, error) {
+	config := &LoginConfig{DSN: &Info{}}
+
+	var err error
+	skip := func(n int) {
+		if err != nil {
+			return
+		}
+		_, err = ch.Bytes(n)
+	}
+	read := func(s *string, padTo int) {
+		if err != nil {
+			return
+		}
+		*s, err = readString(ch, padTo)
+	}
+
+	// lhostname, lusername, lpw, lhostproc
+	read(&config.Hostname, TDS_MAXNAME)
+	read(&config.DSN.Username, TDS_MAXNAME)
+	read(&config.DSN.Password, TDS_MAXNAME)
+	read(&config.HostProc, TDS_MAXNAME)
+	// lint2, lint4, lchar, lflt, ldate, lusedb, ldmpld,
+	// linterfacespare, ltype, lbufsize, lspare
+	skip(9 + TDS_NETBUF + 3)
+	// lappname, lservname
+	read(&config.AppName, TDS_MAXNAME)
+	read(&config.ServName, TDS_MAXNAME)
+	// lrempw, ltds
+	skip(TDS_RPLEN + 1 + TDS_VERSIZE)
+	// lprogname
+	read(&config.ProgramName, TDS_PROGNLEN)
+	// lprogvers, lnoshort, lflt4, ldate4
+	skip(TDS_VERSIZE + 3)
+	// llanguage
+	read(&config.Language, TDS_MAXNAME)
+	// lsetlang, loldsecure
+	skip(1 + TDS_OLDSECURE)
+	if err != nil {
+		return nil, fmt.Errorf("error reading login payload: %w", err)
+	}
+
+	// lseclogin
+	secLogin, err := ch.Byte()
+	if err != nil {
+		return nil, fmt.Errorf("error reading seclogin: %w", err)
+	}
+	switch secLogin {
+	case 0x1:
+		config.Encrypt = TDS_MSG_SEC_ENCRYPT
+	case 0x1 | 0x20:
+		config.Encrypt = TDS_MSG_SEC_ENCRYPT2
+	case 0x1 | 0x20 | 0x80:
+		config.Encrypt = TDS_MSG_SEC_ENCRYPT4
+	case 0x10:
+		config.Encrypt = TDS_MSG_SEC_OPAQUE
+	}
+
+	// lsecbulk
+	skip(1)
+	if err != nil {
+		return nil, fmt.Errorf("error reading login payload: %w", err)
+	}
+
+	// lhalogin, lhasessionid
+	haLogin, err := ch.Byte()
+	if err != nil {
+		return nil, fmt.Errorf("error reading halogin: %w", err)
+	}
+	config.HALogin = HALoginStatus(haLogin)
+
+	config.HASessionID, err = ch.Bytes(TDS_HA)
+	if err != nil {
+		return nil, fmt.Errorf("error reading hasessionid: %w", err)
+	}
+
+	// lsecspare
+	skip(TDS_SECURE)
+	// lcharset
+	read(&config.CharSet, TDS_MAXNAME)
+	// lsetcharset
+	skip(1)
+	// lpacketsize
+	var packetSize string
+	read(&packetSize, TDS_PKTLEN)
+	// ldummy
+	skip(TDS_DUMMY)
+	if err != nil {
+		return nil, fmt.Errorf("error reading login payload: %w", err)
+	}
+
+	if config.PacketSize, err = strconv.Atoi(packetSize); err != nil {
+		return nil, fmt.Errorf("error parsing packet size %q: %w", packetSize, err)
+	}
+
+	return config, nil
+}
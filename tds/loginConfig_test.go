@@ -0,0 +1,156 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tds
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewLoginConfig(t *testing.T) {
+	cases := map[string]struct {
+		info   func(*Info)
+		expect func(*LoginConfig)
+		err    string
+	}{
+		"defaults": {
+			info: func(*Info) {},
+			expect: func(config *LoginConfig) {
+				config.AppName = "github.com/SAP/go-dblib/tds"
+				config.ProgramName = libraryName
+				config.Language = "us_english"
+				config.CharSet = "utf8"
+				config.PacketSize = 512
+			},
+		},
+		"options": {
+			info: func(info *Info) {
+				info.AppName = "billing"
+				info.ProgramName = "billingsv"
+				info.Language = "german"
+				info.CharSet = "iso_1"
+				info.PacketSize = 8192
+				info.RemoteServers = "remote1:pass1, remote2:pa:ss2"
+			},
+			expect: func(config *LoginConfig) {
+				config.AppName = "billing"
+				config.ProgramName = "billingsv"
+				config.Language = "german"
+				config.CharSet = "iso_1"
+				config.PacketSize = 8192
+				config.RemoteServers = []LoginConfigRemoteServer{
+					{Name: "remote1", Password: "pass1"},
+					{Name: "remote2", Password: "pa:ss2"},
+				}
+			},
+		},
+		"app name too long": {
+			info: func(info *Info) {
+				info.AppName = strings.Repeat("a", TDS_MAXNAME+1)
+			},
+			err: "app-name",
+		},
+		"program name too long": {
+			info: func(info *Info) {
+				info.ProgramName = "programname"
+			},
+			err: "program-name",
+		},
+		"language too long": {
+			info: func(info *Info) {
+				info.Language = strings.Repeat("a", TDS_MAXNAME+1)
+			},
+			err: "language",
+		},
+		"charset too long": {
+			info: func(info *Info) {
+				info.CharSet = strings.Repeat("a", TDS_MAXNAME+1)
+			},
+			err: "charset",
+		},
+		"packet size too small": {
+			info: func(info *Info) {
+				info.PacketSize = 256
+			},
+			err: "packet-size",
+		},
+		"packet size too large": {
+			info: func(info *Info) {
+				info.PacketSize = 65536
+			},
+			err: "packet-size",
+		},
+		"remote server without password": {
+			info: func(info *Info) {
+				info.RemoteServers = "remote1"
+			},
+			err: "expected name:password",
+		},
+		"remote server name too long": {
+			info: func(info *Info) {
+				info.RemoteServers = strings.Repeat("a", TDS_MAXNAME+1) + ":pass"
+			},
+			err: "remote server name",
+		},
+		"remote servers too long": {
+			info: func(info *Info) {
+				info.RemoteServers = strings.Repeat("remote:"+strings.Repeat("p", 30)+",", 8)
+			},
+			err: "remote-servers exceed",
+		},
+	}
+
+	for title, cas := range cases {
+		t.Run(title, func(t *testing.T) {
+			info := &Info{ClientHostname: "client"}
+			cas.info(info)
+
+			config, err := NewLoginConfig(info)
+			if cas.err != "" {
+				if err == nil || !strings.Contains(err.Error(), cas.err) {
+					t.Fatalf("expected error containing %q, received: %v", cas.err, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("error creating login config: %v", err)
+			}
+
+			expected := &LoginConfig{RemoteServers: []LoginConfigRemoteServer{}}
+			cas.expect(expected)
+
+			if config.AppName != expected.AppName || config.ProgramName != expected.ProgramName ||
+				config.Language != expected.Language || config.CharSet != expected.CharSet ||
+				config.PacketSize != expected.PacketSize ||
+				!reflect.DeepEqual(config.RemoteServers, expected.RemoteServers) {
+				t.Errorf("expected options %#v, received %#v", expected, config)
+			}
+
+			pkg, err := config.pack()
+			if err != nil {
+				t.Fatalf("error packing login config: %v", err)
+			}
+
+			queue := NewPacketQueue(func() int { return 512 })
+			if err := pkg.WriteTo(queue); err != nil {
+				t.Fatalf("error writing login payload: %v", err)
+			}
+			queue.SetPosition(0, 0)
+
+			parsed, err := ParseLoginConfig(queue)
+			if err != nil {
+				t.Fatalf("error parsing login payload: %v", err)
+			}
+
+			if parsed.AppName != config.AppName || parsed.ProgramName != config.ProgramName ||
+				parsed.Language != config.Language || parsed.CharSet != config.CharSet ||
+				parsed.PacketSize != config.PacketSize {
+				t.Errorf("expected payload with options %#v, received %#v", config, parsed)
+			}
+		})
+	}
+}
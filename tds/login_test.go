@@ -0,0 +1,85 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tds_test
+
+import (
+	"testing"
+
+	"github.com/SAP/go-dblib/tds"
+	"github.com/SAP/go-dblib/tds/tdstest"
+)
+
+func TestChannel_LoginEncryption(t *testing.T) {
+	cases := map[string]struct {
+		offered  tds.TDSMsgId
+		min      string
+		password string
+		err      bool
+	}{
+		"encrypt4": {
+			offered: tds.TDS_MSG_SEC_ENCRYPT4,
+		},
+		"encrypt3 below default minimum": {
+			offered: tds.TDS_MSG_SEC_ENCRYPT3,
+			err:     true,
+		},
+		"encrypt3": {
+			offered: tds.TDS_MSG_SEC_ENCRYPT3,
+			min:     "encrypt3",
+		},
+		"encrypt2 below minimum": {
+			offered: tds.TDS_MSG_SEC_ENCRYPT2,
+			min:     "encrypt3",
+			err:     true,
+		},
+		"encrypt2": {
+			offered: tds.TDS_MSG_SEC_ENCRYPT2,
+			min:     "encrypt2",
+		},
+		"encrypt2 wrong password": {
+			offered:  tds.TDS_MSG_SEC_ENCRYPT2,
+			min:      "encrypt2",
+			password: "wrong",
+			err:      true,
+		},
+		"encrypt4 with lower minimum": {
+			offered: tds.TDS_MSG_SEC_ENCRYPT4,
+			min:     "encrypt2",
+		},
+	}
+
+	for title, cas := range cases {
+		t.Run(title, func(t *testing.T) {
+			server, err := tdstest.NewServer("user", "pass")
+			if err != nil {
+				t.Fatalf("error creating server: %v", err)
+			}
+			defer server.Close()
+
+			server.OfferLoginEncryption(cas.offered)
+
+			info, err := server.Info()
+			if err != nil {
+				t.Fatalf("error getting info: %v", err)
+			}
+			info.LoginEncryptionMin = cas.min
+			if cas.password != "" {
+				info.Password = cas.password
+			}
+
+			err = login(info)
+			if cas.err {
+				if err == nil {
+					t.Fatalf("expected login to fail")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("error logging in: %v", err)
+			}
+		})
+	}
+}
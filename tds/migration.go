@@ -0,0 +1,360 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// quiesceInterval is the interval in which the channels are checked for
+// requests in progress before a migration.
+const quiesceInterval = 10 * time.Millisecond
+
+// ErrMigrationIgnored is passed to MigrationHooks if the server
+// cancelled a migration. The connection stays on the server.
+var ErrMigrationIgnored = errors.New("server cancelled the migration")
+
+// MigrationHook defines the signature of functions called by a Conn
+// after a migration requested by the server.
+//
+// from and to are the addresses of the previous and the target server.
+// err is the error if the migration failed, in which case the
+// connection stays on the previous server unless the connection to it
+// was already closed.
+type MigrationHook func(from, to string, err error)
+
+// migration is a migration waiting for the reply of the server to
+// TDS_MSG_MIG_SYNC.
+type migration struct {
+	conn  net.Conn
+	addr  string
+	reply chan TDSMsgId
+}
+
+// RegisterMigrationHooks registers a function to be called when the
+// connection was migrated to another server.
+//
+// Note that all registered hooks are called in sequence of being
+// registered. Hooks with a longer run time or waiting on locks should
+// utilize goroutines or use other means to prevent blocking other
+// hooks.
+func (tds *Conn) RegisterMigrationHooks(fns ...MigrationHook) error {
+	tds.migrationHooksLock.Lock()
+	defer tds.migrationHooksLock.Unlock()
+
+	for i, fn := range fns {
+		if fn == nil {
+			return fmt.Errorf("tds: received nil function as hook at index %d", i)
+		}
+	}
+
+	tds.migrationHooks = append(tds.migrationHooks, fns...)
+	return nil
+}
+
+func (tds *Conn) callMigrationHooks(from, to string, err error) {
+	tds.migrationHooksLock.Lock()
+	defer tds.migrationHooksLock.Unlock()
+
+	for _, fn := range tds.migrationHooks {
+		fn(from, to, err)
+	}
+}
+
+// handleMigrationPackage handles the messages of a migration.
+//
+// A TDS_MSG_MIG_REQ message is followed by parameters with the
+// addresses of the target servers. TDS_MSG_MIG_CONT and
+// TDS_MSG_MIG_IGN are the replies of the server to TDS_MSG_MIG_SYNC.
+func (tdsChan *Channel) handleMigrationPackage(pkg Package) bool {
+	if msg, ok := pkg.(*MsgPackage); ok {
+		switch msg.MsgId {
+		case TDS_MSG_MIG_REQ:
+			// Migration requests may arrive while no response is
+			// expected, the last package is restored after the
+			// request.
+			tdsChan.migrationMsg = true
+			tdsChan.migrationLastPkgRx = tdsChan.lastPkgRx
+			tdsChan.lastPkgRx = pkg
+			return true
+		case TDS_MSG_MIG_CONT, TDS_MSG_MIG_IGN:
+			tdsChan.tdsConn.migrationReply(msg.MsgId)
+			tdsChan.rxEventOnly = true
+			return true
+		case TDS_MSG_MIG_RESUME:
+			// Sent by the target server in the response to the login
+			// of a migrated session.
+			return true
+		}
+		return false
+	}
+
+	if !tdsChan.migrationMsg {
+		return false
+	}
+
+	switch typed := pkg.(type) {
+	case *ParamFmtPackage:
+		tdsChan.lastPkgRx = pkg
+		return true
+	case *ParamsPackage:
+		tdsChan.migrationMsg = false
+		tdsChan.lastPkgRx = tdsChan.migrationLastPkgRx
+		tdsChan.migrationLastPkgRx = nil
+		tdsChan.rxEventOnly = true
+
+		addresses := []string{}
+		for _, field := range typed.DataFields {
+			if address, ok := field.Value().(string); ok {
+				addresses = append(addresses, address)
+			}
+		}
+
+		// The migration waits for responses, which are received by
+		// the goroutine calling handleMigrationPackage.
+		go tdsChan.tdsConn.migrate(addresses)
+		return true
+	}
+
+	tdsChan.migrationMsg = false
+	tdsChan.lastPkgRx = tdsChan.migrationLastPkgRx
+	tdsChan.migrationLastPkgRx = nil
+	return false
+}
+
+// migrationReply passes the reply of the server to the migration in
+// progress.
+//
+// If the server continues the migration the connection is replaced by
+// the connection to the target server before the next packet is read.
+func (tds *Conn) migrationReply(msgId TDSMsgId) {
+	tds.migrationLock.Lock()
+	defer tds.migrationLock.Unlock()
+
+	if tds.migration == nil {
+		return
+	}
+
+	if msgId == TDS_MSG_MIG_CONT {
+		tds.connLock.Lock()
+		c := tds.conn
+		tds.conn = tds.migration.conn
+		tds.addr = tds.migration.addr
+		tds.connLock.Unlock()
+
+		c.Close()
+	} else {
+		tds.migration.conn.Close()
+	}
+
+	tds.migration.reply <- msgId
+	tds.migration = nil
+}
+
+// migrate migrates the connection to the first reachable server of
+// addresses.
+//
+// New requests are blocked until the migration is completed.
+func (tds *Conn) migrate(addresses []string) {
+	done := make(chan struct{})
+
+	tds.connLock.Lock()
+	if tds.reconnectDone != nil {
+		// A failover or migration is already in progress.
+		tds.connLock.Unlock()
+		return
+	}
+	from := tds.addr
+	tds.reconnectDone = done
+	tds.connLock.Unlock()
+
+	to, err := tds.runMigration(addresses)
+
+	tds.connLock.Lock()
+	tds.reconnectDone = nil
+	tds.connLock.Unlock()
+	close(done)
+
+	tds.callMigrationHooks(from, to, err)
+}
+
+func (tds *Conn) runMigration(addresses []string) (string, error) {
+	ctx, cancel := tds.reconnectContext()
+	defer cancel()
+
+	if err := tds.quiesce(ctx); err != nil {
+		return "", err
+	}
+
+	c, to, err := dialAddresses(ctx, tds.info, addresses)
+	if err != nil {
+		err = fmt.Errorf("error connecting to target server: %w", err)
+		if msgErr := tds.sendMigrationMsg(ctx, TDS_MSG_MIG_FAIL); msgErr != nil {
+			return "", fmt.Errorf("%w; error reporting failed migration: %v", err, msgErr)
+		}
+		return "", err
+	}
+
+	reply := make(chan TDSMsgId, 1)
+	tds.migrationLock.Lock()
+	tds.migration = &migration{conn: c, addr: to, reply: reply}
+	tds.migrationLock.Unlock()
+
+	var msgId TDSMsgId
+	if err = tds.sendMigrationMsg(ctx, TDS_MSG_MIG_SYNC); err == nil {
+		select {
+		case <-ctx.Done():
+			err = fmt.Errorf("error waiting for migration reply: %w", ctx.Err())
+		case msgId = <-reply:
+		}
+	}
+
+	tds.migrationLock.Lock()
+	if tds.migration != nil {
+		tds.migration = nil
+		c.Close()
+	}
+	tds.migrationLock.Unlock()
+
+	switch msgId {
+	case TDS_MSG_MIG_CONT:
+	case TDS_MSG_MIG_IGN:
+		return "", ErrMigrationIgnored
+	default:
+		return "", err
+	}
+
+	if err := tds.resumeMigratedSession(); err != nil {
+		// The connection to the source server is closed, requests on
+		// the target server would fail with arbitrary errors.
+		tds.loseChannels(err)
+		return to, err
+	}
+
+	return to, nil
+}
+
+// quiesce waits until no channel has a request in progress.
+func (tds *Conn) quiesce(ctx context.Context) error {
+	ticker := time.NewTicker(quiesceInterval)
+	defer ticker.Stop()
+
+	for {
+		pending := false
+		tds.tdsChannelsLock.RLock()
+		for _, tdsChan := range tds.tdsChannels {
+			if tdsChan.requestPending.Load() {
+				pending = true
+				break
+			}
+		}
+		tds.tdsChannelsLock.RUnlock()
+
+		if !pending {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("error waiting for requests in progress: %w", ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// sendMigrationMsg sends a message of the migration protocol to the
+// server.
+func (tds *Conn) sendMigrationMsg(ctx context.Context, msgId TDSMsgId) error {
+	tdsChan := tds.newChannel(0)
+	tdsChan.resuming = true
+	tdsChan.CurrentHeaderType = TDS_BUF_MIGRATE
+
+	if err := tdsChan.SendPackage(ctx, NewMsgPackage(TDS_MSG_HASNOARGS, msgId)); err != nil {
+		return fmt.Errorf("error sending %s: %w", msgId, err)
+	}
+
+	return nil
+}
+
+// resumeMigratedSession logs in on the target server and restores the
+// current database, the LOB locator setting and the event
+// subscriptions of the session before setting up the logical channels
+// again.
+func (tds *Conn) resumeMigratedSession() error {
+	tds.haLock.Lock()
+	if tds.loginConfig == nil {
+		tds.haLock.Unlock()
+		return errors.New("connection was not logged in")
+	}
+	config := *tds.loginConfig
+	config.HASessionID = tds.haSessionID
+	tds.haLock.Unlock()
+
+	config.HALogin = TDS_HA_LOG_SESSION | TDS_HA_LOG_MIGRATE
+
+	// The state is recorded before the login as the target server
+	// announces the default database of the login.
+	tds.sessionLock.Lock()
+	database, sendLocator := tds.database, tds.sendLocator
+	tds.sessionLock.Unlock()
+
+	tds.eventSubsLock.Lock()
+	events := make([]string, 0, len(tds.eventSubs))
+	for name := range tds.eventSubs {
+		events = append(events, name)
+	}
+	tds.eventSubsLock.Unlock()
+
+	err := tds.loginSession(config, func(ctx context.Context, tdsChan *Channel) error {
+		if database != "" {
+			if err := tdsChan.execLanguage(ctx, "use "+database, nil); err != nil {
+				return fmt.Errorf("error restoring database: %w", err)
+			}
+		}
+
+		if sendLocator {
+			if err := tdsChan.SetSendLocator(ctx, true); err != nil {
+				return fmt.Errorf("error restoring LOB locators: %w", err)
+			}
+		}
+
+		// eventRPC executes the registration on the channel
+		// replacing the main channel.
+		for _, name := range events {
+			if err := tds.eventRPC(ctx, "sp_regwatch", name, "nowait"); err != nil {
+				return fmt.Errorf("error restoring subscription of event %s: %w", name, err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error resuming migrated session: %w", err)
+	}
+
+	return nil
+}
+
+// loseChannels marks all channels as lost after the session could not
+// be resumed on the target server of a migration.
+func (tds *Conn) loseChannels(err error) {
+	tds.tdsChannelsLock.RLock()
+	defer tds.tdsChannelsLock.RUnlock()
+
+	for id, tdsChan := range tds.tdsChannels {
+		tdsChan.lost.CompareAndSwap(nil, &ChannelLostError{ChannelID: id, Err: err})
+	}
+}
+
+func (tds *Conn) setDatabase(database string) {
+	tds.sessionLock.Lock()
+	defer tds.sessionLock.Unlock()
+
+	tds.database = database
+}
@@ -0,0 +1,213 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tds_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SAP/go-dblib/asetypes"
+	"github.com/SAP/go-dblib/tds"
+	"github.com/SAP/go-dblib/tds/tdstest"
+)
+
+type migrationRecorder struct {
+	sync.Mutex
+	cmds []string
+}
+
+func (rec *migrationRecorder) handle(request []tds.Package) ([]tds.Package, bool) {
+	lang, ok := request[0].(*tds.LanguagePackage)
+	if !ok {
+		return nil, false
+	}
+
+	rec.Lock()
+	defer rec.Unlock()
+
+	rec.cmds = append(rec.cmds, lang.Cmd)
+	return []tds.Package{&tds.DonePackage{Status: tds.TDS_DONE_FINAL}}, true
+}
+
+func TestConn_Migration(t *testing.T) {
+	source, err := tdstest.NewServer("user", "pass")
+	if err != nil {
+		t.Fatalf("error creating source server: %v", err)
+	}
+	defer source.Close()
+
+	target, err := tdstest.NewServer("user", "pass")
+	if err != nil {
+		t.Fatalf("error creating target server: %v", err)
+	}
+	defer target.Close()
+
+	// The version of the target is reported after the migration.
+	source.SetVersion("Adaptive Server Enterprise/15.7/EBF 25127 SMP SP136 /P/x86_64")
+
+	source.HandleLanguage("use testdb",
+		tds.NewEnvChangePackage(tds.EnvChangePackageField{Type: tds.TDS_ENV_DB, OldValue: "master", NewValue: "testdb"}),
+		&tds.DonePackage{Status: tds.TDS_DONE_FINAL},
+	)
+	source.HandleLanguage("set send_locator on", &tds.DonePackage{Status: tds.TDS_DONE_FINAL})
+
+	single, err := tdstest.Result([]tdstest.Column{{Name: "a", DataType: asetypes.INT4}}, []interface{}{int32(1)})
+	if err != nil {
+		t.Fatalf("error creating result: %v", err)
+	}
+	target.HandleLanguage("select 1 as a", single...)
+
+	rec := &migrationRecorder{}
+	target.HandleFunc(rec.handle)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	conn, ch, err := source.Connect(ctx)
+	if err != nil {
+		t.Fatalf("error connecting: %v", err)
+	}
+	defer conn.Close()
+
+	if err := ch.SendPackage(ctx, &tds.LanguagePackage{Cmd: "use testdb"}); err != nil {
+		t.Fatalf("error sending request: %v", err)
+	}
+	if _, err := ch.NextPackageUntil(ctx, true, func(pkg tds.Package) (bool, error) {
+		done, ok := pkg.(*tds.DonePackage)
+		return ok && done.Status == tds.TDS_DONE_FINAL, nil
+	}); err != nil {
+		t.Fatalf("error changing database: %v", err)
+	}
+
+	if err := ch.SetSendLocator(ctx, true); err != nil {
+		t.Fatalf("error enabling LOB locators: %v", err)
+	}
+
+	if _, err := conn.SubscribeEvent(ctx, "evt"); err != nil {
+		t.Fatalf("error subscribing to event: %v", err)
+	}
+
+	logical, err := conn.NewChannel()
+	if err != nil {
+		t.Fatalf("error opening logical channel: %v", err)
+	}
+
+	type migration struct {
+		from, to string
+		err      error
+	}
+	migrations := make(chan migration, 1)
+	if err := conn.RegisterMigrationHooks(func(from, to string, err error) {
+		migrations <- migration{from: from, to: to, err: err}
+	}); err != nil {
+		t.Fatalf("error registering hook: %v", err)
+	}
+
+	if v := conn.ServerVersion(); v.Major != 15 || v.Minor != 7 {
+		t.Fatalf("expected server version 15.7, received %s", v)
+	}
+
+	if n, err := source.Migrate(target.Addr().String()); err != nil || n != 1 {
+		t.Fatalf("error requesting migration of %d clients: %v", n, err)
+	}
+
+	select {
+	case <-ctx.Done():
+		t.Fatalf("migration hook was not called: %v", ctx.Err())
+	case mig := <-migrations:
+		expected := migration{from: source.Addr().String(), to: target.Addr().String()}
+		if mig != expected {
+			t.Fatalf("received unexpected migration:\nexpected: %v\nreceived: %v", expected, mig)
+		}
+	}
+
+	rec.Lock()
+	cmds := rec.cmds
+	rec.Unlock()
+	if expected := []string{"use testdb", "set send_locator on"}; !reflect.DeepEqual(cmds, expected) {
+		t.Errorf("received unexpected replayed commands:\nexpected: %v\nreceived: %v", expected, cmds)
+	}
+
+	if v := conn.ServerVersion(); v.Major != 16 || v.Minor != 0 {
+		t.Errorf("expected server version to be refreshed to 16.0, received %s", v)
+	}
+
+	if n, err := target.Notify("evt"); err != nil || n != 1 {
+		t.Errorf("event subscription was not restored, notified %d clients: %v", n, err)
+	}
+
+	// The target server only accepts requests on logical channels
+	// that were set up again.
+	for _, ch := range []*tds.Channel{ch, logical} {
+		values, err := selectOne(ctx, ch)
+		if err != nil {
+			t.Fatalf("error querying channel: %v", err)
+		}
+
+		if expected := [][]interface{}{{int32(1)}}; !reflect.DeepEqual(values, expected) {
+			t.Errorf("received unexpected rows:\nexpected: %v\nreceived: %v", expected, values)
+		}
+	}
+}
+
+func TestConn_MigrationLoginFailed(t *testing.T) {
+	source, err := tdstest.NewServer("user", "pass")
+	if err != nil {
+		t.Fatalf("error creating source server: %v", err)
+	}
+	defer source.Close()
+
+	// The target server rejects the login of the session.
+	target, err := tdstest.NewServer("user", "other")
+	if err != nil {
+		t.Fatalf("error creating target server: %v", err)
+	}
+	defer target.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	conn, ch, err := source.Connect(ctx)
+	if err != nil {
+		t.Fatalf("error connecting: %v", err)
+	}
+	defer conn.Close()
+
+	logical, err := conn.NewChannel()
+	if err != nil {
+		t.Fatalf("error opening logical channel: %v", err)
+	}
+
+	migrations := make(chan error, 1)
+	if err := conn.RegisterMigrationHooks(func(from, to string, err error) {
+		migrations <- err
+	}); err != nil {
+		t.Fatalf("error registering hook: %v", err)
+	}
+
+	if n, err := source.Migrate(target.Addr().String()); err != nil || n != 1 {
+		t.Fatalf("error requesting migration of %d clients: %v", n, err)
+	}
+
+	select {
+	case <-ctx.Done():
+		t.Fatalf("migration hook was not called: %v", ctx.Err())
+	case err := <-migrations:
+		if err == nil {
+			t.Fatalf("expected migration to fail")
+		}
+	}
+
+	for _, ch := range []*tds.Channel{ch, logical} {
+		var lost *tds.ChannelLostError
+		if _, err := selectOne(ctx, ch); !errors.As(err, &lost) {
+			t.Errorf("expected ChannelLostError, received: %v", err)
+		}
+	}
+}
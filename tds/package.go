@@ -70,6 +70,12 @@ func LookupPackage(token Token) (Package, error) {
 		return &OrderBy2Package{}, nil
 	case TDS_RETURNSTATUS:
 		return &ReturnStatusPackage{}, nil
+	case TDS_RETURNVALUE:
+		return &ReturnValuePackage{}, nil
+	case TDS_DBRPC:
+		return &RPCPackage{}, nil
+	case TDS_DBRPC2:
+		return &RPCPackage{wide: true}, nil
 	case TDS_LOGOUT:
 		return &LogoutPackage{}, nil
 	case TDS_DYNAMIC:
@@ -92,6 +98,18 @@ func LookupPackage(token Token) (Package, error) {
 		return &CurUpdatePackage{}, nil
 	case TDS_CURDELETE:
 		return &CurDeletePackage{}, nil
+	case TDS_CURCLOSE:
+		return &CurClosePackage{}, nil
+	case TDS_EVENTNOTICE:
+		return &EventNoticePackage{}, nil
+	case TDS_ALTNAME:
+		return &AltNamePackage{}, nil
+	case TDS_ALTFMT:
+		return &AltFmtPackage{}, nil
+	case TDS_ALTROW:
+		return &AltRowPackage{}, nil
+	case TDS_ALTCONTROL:
+		return &AltControlPackage{}, nil
 	default:
 		return NewTokenlessPackage(), nil
 	}
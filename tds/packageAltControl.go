@@ -0,0 +1,63 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tds
+
+import "fmt"
+
+var _ Package = (*AltControlPackage)(nil)
+var _ LastPkgAcceptor = (*AltControlPackage)(nil)
+
+// AltControlPackage communicates control information of compute
+// clauses. The information is not interpreted by clients.
+type AltControlPackage struct {
+	Data []byte
+
+	rowFmt  *RowFmtPackage
+	altFmts []*AltFmtPackage
+}
+
+// LastPkg implements the tds.LastPkgAcceptor interface.
+//
+// AltControlPackage only passes on the formats of the result set.
+func (pkg *AltControlPackage) LastPkg(other Package) error {
+	pkg.rowFmt, pkg.altFmts, _ = resultFmts(other)
+	return nil
+}
+
+// ReadFrom implements the tds.Package interface.
+func (pkg *AltControlPackage) ReadFrom(ch BytesChannel) error {
+	length, err := ch.Uint16()
+	if err != nil {
+		return ErrNotEnoughBytes
+	}
+
+	pkg.Data, err = ch.Bytes(int(length))
+	if err != nil {
+		return ErrNotEnoughBytes
+	}
+
+	return nil
+}
+
+// WriteTo implements the tds.Package interface.
+func (pkg AltControlPackage) WriteTo(ch BytesChannel) error {
+	if err := ch.WriteByte(byte(TDS_ALTCONTROL)); err != nil {
+		return fmt.Errorf("error writing TDS token %s: %w", TDS_ALTCONTROL, err)
+	}
+
+	if err := ch.WriteUint16(uint16(len(pkg.Data))); err != nil {
+		return fmt.Errorf("error writing length: %w", err)
+	}
+
+	if err := ch.WriteBytes(pkg.Data); err != nil {
+		return fmt.Errorf("error writing data: %w", err)
+	}
+
+	return nil
+}
+
+func (pkg AltControlPackage) String() string {
+	return fmt.Sprintf("%T(%x)", pkg, pkg.Data)
+}
@@ -0,0 +1,304 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tds
+
+import (
+	"fmt"
+
+	"github.com/SAP/go-dblib/asetypes"
+)
+
+//go:generate stringer -type=AltOperator
+
+// AltOperator is the aggregate operator of a compute column.
+type AltOperator uint8
+
+const (
+	TDS_ALT_COUNT AltOperator = 0x4b
+	TDS_ALT_SUM   AltOperator = 0x4d
+	TDS_ALT_AVG   AltOperator = 0x4f
+	TDS_ALT_MIN   AltOperator = 0x51
+	TDS_ALT_MAX   AltOperator = 0x52
+)
+
+// AltColumn is a compute column of an AltFmtPackage.
+type AltColumn struct {
+	Operator AltOperator
+	// Operand is the number of the column in the select list the
+	// operator is applied to, starting at 1.
+	Operand uint8
+	Fmt     FieldFmt
+}
+
+var _ Package = (*AltFmtPackage)(nil)
+var _ LastPkgAcceptor = (*AltFmtPackage)(nil)
+
+// AltFmtPackage communicates the format of compute rows, which are
+// generated by `compute ... by ...` clauses.
+type AltFmtPackage struct {
+	// ID identifies the compute clause. It is referenced by
+	// AltRowPackages.
+	ID      uint16
+	Columns []AltColumn
+	// ByColumns are the numbers of the columns in the select list the
+	// compute clause is grouped by, starting at 1.
+	ByColumns []uint8
+
+	rowFmt  *RowFmtPackage
+	altFmts []*AltFmtPackage
+}
+
+// NewAltFmtPackage returns an AltFmtPackage.
+func NewAltFmtPackage(id uint16, byColumns []uint8, columns ...AltColumn) *AltFmtPackage {
+	return &AltFmtPackage{
+		ID:        id,
+		Columns:   columns,
+		ByColumns: byColumns,
+	}
+}
+
+// LastPkg implements the tds.LastPkgAcceptor interface.
+//
+// The formats of compute clauses follow the format of the result set.
+func (pkg *AltFmtPackage) LastPkg(other Package) error {
+	rowFmt, altFmts, ok := resultFmts(other)
+	if !ok || rowFmt == nil {
+		return fmt.Errorf("TDS_ALTFMT received without preceding TDS_ROWFMT")
+	}
+
+	pkg.rowFmt = rowFmt
+	pkg.altFmts = append(append([]*AltFmtPackage{}, altFmts...), pkg)
+	return nil
+}
+
+// Fmts returns the formats of the compute columns.
+func (pkg AltFmtPackage) Fmts() []FieldFmt {
+	fmts := make([]FieldFmt, len(pkg.Columns))
+	for i, column := range pkg.Columns {
+		fmts[i] = column.Fmt
+	}
+	return fmts
+}
+
+// ReadFrom implements the tds.Package interface.
+func (pkg *AltFmtPackage) ReadFrom(ch BytesChannel) error {
+	totalLength, err := ch.Uint16()
+	if err != nil {
+		return ErrNotEnoughBytes
+	}
+
+	pkg.ID, err = ch.Uint16()
+	if err != nil {
+		return ErrNotEnoughBytes
+	}
+	n := 2
+
+	columnCount, err := ch.Uint8()
+	if err != nil {
+		return ErrNotEnoughBytes
+	}
+	n++
+
+	pkg.Columns = make([]AltColumn, int(columnCount))
+	for i := range pkg.Columns {
+		column, readBytes, err := pkg.readColumn(ch)
+		if err != nil {
+			return err
+		}
+		n += readBytes
+		pkg.Columns[i] = column
+	}
+
+	byColumnCount, err := ch.Uint8()
+	if err != nil {
+		return ErrNotEnoughBytes
+	}
+	n++
+
+	pkg.ByColumns, err = ch.Bytes(int(byColumnCount))
+	if err != nil {
+		return ErrNotEnoughBytes
+	}
+	n += int(byColumnCount)
+
+	if n != int(totalLength) {
+		return fmt.Errorf("expected to read %d bytes, read %d bytes instead", totalLength, n)
+	}
+
+	return nil
+}
+
+func (pkg AltFmtPackage) readColumn(ch BytesChannel) (AltColumn, int, error) {
+	column := AltColumn{}
+
+	operator, err := ch.Uint8()
+	if err != nil {
+		return column, 0, ErrNotEnoughBytes
+	}
+	column.Operator = AltOperator(operator)
+	n := 1
+
+	column.Operand, err = ch.Uint8()
+	if err != nil {
+		return column, n, ErrNotEnoughBytes
+	}
+	n++
+
+	userType, err := ch.Int32()
+	if err != nil {
+		return column, n, ErrNotEnoughBytes
+	}
+	n += 4
+
+	token, err := ch.Uint8()
+	if err != nil {
+		return column, n, ErrNotEnoughBytes
+	}
+	n++
+
+	column.Fmt, err = LookupFieldFmt(asetypes.DataType(token))
+	if err != nil {
+		return column, n, fmt.Errorf("error preparing field format for token %s: %w", asetypes.DataType(token), err)
+	}
+	column.Fmt.SetUserType(userType)
+
+	readBytes, err := column.Fmt.ReadFrom(ch)
+	if err != nil {
+		return column, n, fmt.Errorf("error reading compute column format: %w", err)
+	}
+	n += readBytes
+
+	localeLen, err := ch.Uint8()
+	if err != nil {
+		return column, n, ErrNotEnoughBytes
+	}
+	n++
+
+	localeInfo, err := ch.String(int(localeLen))
+	if err != nil {
+		return column, n, ErrNotEnoughBytes
+	}
+	column.Fmt.SetLocaleInfo(localeInfo)
+	n += int(localeLen)
+
+	return column, n, nil
+}
+
+// WriteTo implements the tds.Package interface.
+func (pkg AltFmtPackage) WriteTo(ch BytesChannel) error {
+	if err := ch.WriteByte(byte(TDS_ALTFMT)); err != nil {
+		return fmt.Errorf("error writing TDS token %s: %w", TDS_ALTFMT, err)
+	}
+
+	// 2 id
+	// 1 column count
+	// per column:
+	//   1 operator
+	//   1 operand
+	//   4 usertype
+	//   1 token
+	//   x FormatByteLength
+	//   1 locale len
+	//   x locale
+	// 1 by column count
+	// x by columns
+	length := 2 + 1 + 1 + len(pkg.ByColumns)
+	for _, column := range pkg.Columns {
+		length += 1 + 1 + 4 + 1 + column.Fmt.FormatByteLength() + 1 + len(column.Fmt.LocaleInfo())
+	}
+
+	if err := ch.WriteUint16(uint16(length)); err != nil {
+		return fmt.Errorf("error writing length: %w", err)
+	}
+
+	if err := ch.WriteUint16(pkg.ID); err != nil {
+		return fmt.Errorf("error writing ID: %w", err)
+	}
+
+	if err := ch.WriteUint8(uint8(len(pkg.Columns))); err != nil {
+		return fmt.Errorf("error writing column count: %w", err)
+	}
+
+	for i, column := range pkg.Columns {
+		if err := pkg.writeColumn(ch, column); err != nil {
+			return fmt.Errorf("error writing compute column %d: %w", i, err)
+		}
+	}
+
+	if err := ch.WriteUint8(uint8(len(pkg.ByColumns))); err != nil {
+		return fmt.Errorf("error writing by column count: %w", err)
+	}
+
+	if err := ch.WriteBytes(pkg.ByColumns); err != nil {
+		return fmt.Errorf("error writing by columns: %w", err)
+	}
+
+	return nil
+}
+
+func (pkg AltFmtPackage) writeColumn(ch BytesChannel, column AltColumn) error {
+	if err := ch.WriteUint8(uint8(column.Operator)); err != nil {
+		return fmt.Errorf("failed to write operator: %w", err)
+	}
+
+	if err := ch.WriteUint8(column.Operand); err != nil {
+		return fmt.Errorf("failed to write operand: %w", err)
+	}
+
+	if err := ch.WriteInt32(column.Fmt.UserType()); err != nil {
+		return fmt.Errorf("failed to write usertype: %w", err)
+	}
+
+	if err := ch.WriteByte(byte(column.Fmt.DataType())); err != nil {
+		return fmt.Errorf("failed to write token: %w", err)
+	}
+
+	if _, err := column.Fmt.WriteTo(ch); err != nil {
+		return fmt.Errorf("failed to write format: %w", err)
+	}
+
+	if err := ch.WriteUint8(uint8(len(column.Fmt.LocaleInfo()))); err != nil {
+		return fmt.Errorf("failed to write locale info length: %w", err)
+	}
+
+	if err := ch.WriteString(column.Fmt.LocaleInfo()); err != nil {
+		return fmt.Errorf("failed to write locale info: %w", err)
+	}
+
+	return nil
+}
+
+func (pkg AltFmtPackage) String() string {
+	s := fmt.Sprintf("%T(%d, by %v): |", pkg, pkg.ID, pkg.ByColumns)
+	for _, column := range pkg.Columns {
+		s += fmt.Sprintf(" %s(%d) %s |", column.Operator, column.Operand, column.Fmt.DataType())
+	}
+	return s
+}
+
+// resultFmts returns the format of the result set and the formats of
+// its compute clauses communicated by or preceding pkg.
+func resultFmts(pkg Package) (*RowFmtPackage, []*AltFmtPackage, bool) {
+	switch typed := pkg.(type) {
+	case *RowFmtPackage:
+		return typed, nil, true
+	case *RowPackage:
+		return typed.rowFmt, typed.altFmts, true
+	case *OrderByPackage:
+		return typed.rowFmt, nil, true
+	case *OrderBy2Package:
+		return typed.rowFmt, nil, true
+	case *AltNamePackage:
+		return typed.rowFmt, typed.altFmts, true
+	case *AltFmtPackage:
+		return typed.rowFmt, typed.altFmts, true
+	case *AltRowPackage:
+		return typed.rowFmt, typed.altFmts, true
+	case *AltControlPackage:
+		return typed.rowFmt, typed.altFmts, true
+	}
+
+	return nil, nil, false
+}
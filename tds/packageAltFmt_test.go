@@ -0,0 +1,106 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tds_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/SAP/go-dblib/asetypes"
+	"github.com/SAP/go-dblib/tds"
+	"github.com/SAP/go-dblib/tds/tdstest"
+)
+
+func TestAltRowPackage(t *testing.T) {
+	server, err := tdstest.NewServer("user", "pass")
+	if err != nil {
+		t.Fatalf("error creating server: %v", err)
+	}
+	defer server.Close()
+
+	// select grp, val from t order by grp compute sum(val) by grp
+	response, err := tdstest.Result([]tdstest.Column{
+		{Name: "grp", DataType: asetypes.INT4},
+		{Name: "val", DataType: asetypes.INT4},
+	},
+		[]interface{}{int32(1), int32(2)},
+		[]interface{}{int32(1), int32(3)},
+	)
+	if err != nil {
+		t.Fatalf("error creating result: %v", err)
+	}
+
+	sumFmt, sumData, err := tds.LookupFieldFmtData(asetypes.INT4)
+	if err != nil {
+		t.Fatalf("error looking up INT4: %v", err)
+	}
+	sumData.SetValue(int32(5))
+
+	altFmt := tds.NewAltFmtPackage(1, []uint8{1}, tds.AltColumn{Operator: tds.TDS_ALT_SUM, Operand: 2, Fmt: sumFmt})
+
+	// RowFmt, AltName, AltFmt, Row, Row, AltRow, Done
+	script := []tds.Package{response[0],
+		&tds.AltNamePackage{ID: 1, Names: []string{"sum"}},
+		altFmt,
+	}
+	script = append(script, response[1:3]...)
+	script = append(script, tds.NewAltRowPackage(1, sumData), response[3])
+	server.HandleLanguage("select grp, val from t order by grp compute sum(val) by grp", script...)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	conn, ch, err := server.Connect(ctx)
+	if err != nil {
+		t.Fatalf("error connecting: %v", err)
+	}
+	defer conn.Close()
+
+	if err := ch.SendPackage(ctx, &tds.LanguagePackage{Cmd: "select grp, val from t order by grp compute sum(val) by grp"}); err != nil {
+		t.Fatalf("error sending request: %v", err)
+	}
+
+	rows := [][]interface{}{}
+	computeRows := [][]interface{}{}
+	var receivedAltFmt *tds.AltFmtPackage
+
+	_, err = ch.NextPackageUntil(ctx, true, func(pkg tds.Package) (bool, error) {
+		switch typed := pkg.(type) {
+		case *tds.RowPackage:
+			rows = append(rows, []interface{}{typed.DataFields[0].Value(), typed.DataFields[1].Value()})
+		case *tds.AltRowPackage:
+			receivedAltFmt = typed.AltFmt()
+			computeRows = append(computeRows, []interface{}{typed.DataFields[0].Value()})
+		case *tds.DonePackage:
+			return typed.Status == tds.TDS_DONE_FINAL, nil
+		}
+		return false, nil
+	})
+	if err != nil {
+		t.Fatalf("error reading response: %v", err)
+	}
+
+	if expected := [][]interface{}{{int32(1), int32(2)}, {int32(1), int32(3)}}; !reflect.DeepEqual(rows, expected) {
+		t.Errorf("received unexpected rows:\nexpected: %v\nreceived: %v", expected, rows)
+	}
+
+	if expected := [][]interface{}{{int32(5)}}; !reflect.DeepEqual(computeRows, expected) {
+		t.Errorf("received unexpected compute rows:\nexpected: %v\nreceived: %v", expected, computeRows)
+	}
+
+	if receivedAltFmt == nil {
+		t.Fatalf("compute row has no format")
+	}
+
+	if receivedAltFmt.ID != 1 || !reflect.DeepEqual(receivedAltFmt.ByColumns, []uint8{1}) {
+		t.Errorf("received unexpected compute format: %s", receivedAltFmt)
+	}
+
+	if column := receivedAltFmt.Columns[0]; column.Operator != tds.TDS_ALT_SUM || column.Operand != 2 {
+		t.Errorf("received unexpected compute column: %s(%d)", column.Operator, column.Operand)
+	}
+}
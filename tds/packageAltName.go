@@ -0,0 +1,105 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tds
+
+import "fmt"
+
+var _ Package = (*AltNamePackage)(nil)
+var _ LastPkgAcceptor = (*AltNamePackage)(nil)
+
+// AltNamePackage communicates the names of the compute columns of
+// a compute clause.
+type AltNamePackage struct {
+	ID    uint16
+	Names []string
+
+	rowFmt  *RowFmtPackage
+	altFmts []*AltFmtPackage
+}
+
+// LastPkg implements the tds.LastPkgAcceptor interface.
+//
+// AltNamePackage only passes on the formats of the result set.
+func (pkg *AltNamePackage) LastPkg(other Package) error {
+	pkg.rowFmt, pkg.altFmts, _ = resultFmts(other)
+	return nil
+}
+
+// ReadFrom implements the tds.Package interface.
+func (pkg *AltNamePackage) ReadFrom(ch BytesChannel) error {
+	totalLength, err := ch.Uint16()
+	if err != nil {
+		return ErrNotEnoughBytes
+	}
+
+	pkg.ID, err = ch.Uint16()
+	if err != nil {
+		return ErrNotEnoughBytes
+	}
+	n := 2
+
+	pkg.Names = []string{}
+	for n < int(totalLength) {
+		nameLength, err := ch.Uint8()
+		if err != nil {
+			return ErrNotEnoughBytes
+		}
+		n++
+
+		name, err := ch.String(int(nameLength))
+		if err != nil {
+			return ErrNotEnoughBytes
+		}
+		n += int(nameLength)
+
+		pkg.Names = append(pkg.Names, name)
+	}
+
+	if n != int(totalLength) {
+		return fmt.Errorf("expected to read %d bytes, read %d bytes instead", totalLength, n)
+	}
+
+	return nil
+}
+
+// WriteTo implements the tds.Package interface.
+func (pkg AltNamePackage) WriteTo(ch BytesChannel) error {
+	if err := ch.WriteByte(byte(TDS_ALTNAME)); err != nil {
+		return fmt.Errorf("error writing TDS token %s: %w", TDS_ALTNAME, err)
+	}
+
+	// 2 id
+	// per name:
+	//   1 name len
+	//   x name
+	length := 2
+	for _, name := range pkg.Names {
+		length += 1 + len(name)
+	}
+
+	if err := ch.WriteUint16(uint16(length)); err != nil {
+		return fmt.Errorf("error writing length: %w", err)
+	}
+
+	if err := ch.WriteUint16(pkg.ID); err != nil {
+		return fmt.Errorf("error writing ID: %w", err)
+	}
+
+	for _, name := range pkg.Names {
+		if err := ch.WriteUint8(uint8(len(name))); err != nil {
+			return fmt.Errorf("error writing name length: %w", err)
+		}
+
+		if err := ch.WriteString(name); err != nil {
+			return fmt.Errorf("error writing name: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (pkg AltNamePackage) String() string {
+	return fmt.Sprintf("%T(%d, %v)", pkg, pkg.ID, pkg.Names)
+}
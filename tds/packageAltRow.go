@@ -0,0 +1,122 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tds
+
+import "fmt"
+
+var _ Package = (*AltRowPackage)(nil)
+var _ LastPkgAcceptor = (*AltRowPackage)(nil)
+
+// AltRowPackage communicates a compute row.
+//
+// Compute rows are interleaved with the RowPackages of a result set and
+// form a separate result set with the format of the AltFmtPackage
+// referenced by ID.
+type AltRowPackage struct {
+	ID         uint16
+	DataFields []FieldData
+
+	altFmt  *AltFmtPackage
+	rowFmt  *RowFmtPackage
+	altFmts []*AltFmtPackage
+}
+
+// NewAltRowPackage returns an AltRowPackage for the compute clause with
+// the passed ID.
+func NewAltRowPackage(id uint16, data ...FieldData) *AltRowPackage {
+	return &AltRowPackage{
+		ID:         id,
+		DataFields: data,
+	}
+}
+
+// AltFmt returns the format of the compute row.
+func (pkg AltRowPackage) AltFmt() *AltFmtPackage {
+	return pkg.altFmt
+}
+
+// LastPkg implements the tds.LastPkgAcceptor interface.
+func (pkg *AltRowPackage) LastPkg(other Package) error {
+	rowFmt, altFmts, ok := resultFmts(other)
+	if !ok || len(altFmts) == 0 {
+		return fmt.Errorf("TDS_ALTROW received without preceding TDS_ALTFMT")
+	}
+
+	pkg.rowFmt = rowFmt
+	pkg.altFmts = altFmts
+
+	// Packages created by the client already have their format.
+	if pkg.DataFields != nil {
+		return pkg.setAltFmt()
+	}
+
+	return nil
+}
+
+func (pkg *AltRowPackage) setAltFmt() error {
+	for _, altFmt := range pkg.altFmts {
+		if altFmt.ID == pkg.ID {
+			pkg.altFmt = altFmt
+			return nil
+		}
+	}
+
+	return fmt.Errorf("no TDS_ALTFMT received for compute ID %d", pkg.ID)
+}
+
+// ReadFrom implements the tds.Package interface.
+func (pkg *AltRowPackage) ReadFrom(ch BytesChannel) error {
+	var err error
+	pkg.ID, err = ch.Uint16()
+	if err != nil {
+		return ErrNotEnoughBytes
+	}
+
+	if err := pkg.setAltFmt(); err != nil {
+		return err
+	}
+
+	pkg.DataFields = make([]FieldData, len(pkg.altFmt.Columns))
+	for i, column := range pkg.altFmt.Columns {
+		pkg.DataFields[i], err = LookupFieldData(column.Fmt)
+		if err != nil {
+			return fmt.Errorf("error copying field: %w", err)
+		}
+
+		if _, err := pkg.DataFields[i].ReadFrom(ch); err != nil {
+			return fmt.Errorf("error occurred reading compute field %d data (%s): %w",
+				i, column.Fmt.DataType(), err)
+		}
+	}
+
+	return nil
+}
+
+// WriteTo implements the tds.Package interface.
+func (pkg AltRowPackage) WriteTo(ch BytesChannel) error {
+	if err := ch.WriteByte(byte(TDS_ALTROW)); err != nil {
+		return fmt.Errorf("error occurred writing TDS token %s: %w", TDS_ALTROW, err)
+	}
+
+	if err := ch.WriteUint16(pkg.ID); err != nil {
+		return fmt.Errorf("error occurred writing compute ID: %w", err)
+	}
+
+	for i, field := range pkg.DataFields {
+		if _, err := field.WriteTo(ch); err != nil {
+			return fmt.Errorf("error occurred writing compute field %d data: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+func (pkg AltRowPackage) String() string {
+	s := make([]string, len(pkg.DataFields))
+	for i, field := range pkg.DataFields {
+		s[i] = fmt.Sprintf("%v", field.Value())
+	}
+	return fmt.Sprintf("%T(%d, %d): %s", pkg, pkg.ID, len(pkg.DataFields), s)
+}
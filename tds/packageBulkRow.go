@@ -0,0 +1,550 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tds
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/SAP/go-dblib/asetypes"
+)
+
+var _ Package = (*BulkRowPackage)(nil)
+
+// bulkTextPtrSize is the size of the text pointer reserved in a bulk
+// row for the value of a text or image column.
+const bulkTextPtrSize = 16
+
+// BulkRowPackage communicates a row of a bulk copy in a TDS_BUF_BULK
+// message.
+//
+// Unlike RowPackage it has no token and is not preceded by a format.
+// The row is encoded in the native row format of ASE, with the layout
+// FreeTDS uses for TDS 5.0 bulk copies:
+//
+//	2 bytes   length of the following record
+//	1 byte    number of variable columns
+//	1 byte    row number, assigned by the server
+//	...       fixed columns in column order, bit columns are
+//	          collapsed into bytes
+//	2 bytes   length of the record
+//	...       variable columns in column order
+//	...       adjust table and offset table of the variable columns
+//
+// The variable columns, their length and the tables are omitted if the
+// table has only fixed columns. Columns are variable if they allow
+// NULL or their data type has no fixed length, except for char, binary,
+// decimal and numeric columns. NULL values are empty and text and image
+// columns hold a text pointer, their values are sent after the row.
+//
+// To read a row DataFields must be set to fields with the formats of
+// the columns of the table.
+type BulkRowPackage struct {
+	DataFields []FieldData
+
+	record []byte
+	blobs  []bulkBlob
+}
+
+// bulkBlob is the value of a text or image column, which is sent after
+// the row and refers to the position of its text pointer in the row.
+type bulkBlob struct {
+	dataType asetypes.DataType
+	textPos  int
+	data     []byte
+}
+
+// NewBulkRowPackage returns an initialized BulkRowPackage.
+func NewBulkRowPackage(data ...FieldData) *BulkRowPackage {
+	return &BulkRowPackage{
+		DataFields: data,
+	}
+}
+
+// isBulkVariable returns true if the values of fieldFmt are stored as
+// variable columns.
+func isBulkVariable(fieldFmt FieldFmt) bool {
+	if RowFmtStatus(fieldFmt.Status())&TDS_ROW_NULLALLOWED == TDS_ROW_NULLALLOWED {
+		return true
+	}
+
+	switch fieldFmt.DataType() {
+	case asetypes.CHAR, asetypes.BINARY, asetypes.DECN, asetypes.NUMN:
+		return false
+	}
+
+	return !fieldFmt.IsFixedLength()
+}
+
+// isBulkBlob returns true if the values of dataType are sent after the
+// row.
+func isBulkBlob(dataType asetypes.DataType) bool {
+	switch dataType {
+	case asetypes.TEXT, asetypes.IMAGE, asetypes.UNITEXT:
+		return true
+	}
+	return false
+}
+
+// bulkFixedLength returns the number of bytes of a fixed column.
+func bulkFixedLength(fieldFmt FieldFmt) int {
+	switch fieldFmt.DataType() {
+	case asetypes.CHAR, asetypes.BINARY, asetypes.DECN, asetypes.NUMN:
+		return int(fieldFmt.MaxLength())
+	}
+	return fieldFmt.DataType().ByteSize()
+}
+
+// bulkFixedBytes returns the bytes of value in a fixed column. char and
+// binary values are padded to the length of the column with spaces
+// respectively zeroes, decimals are padded after their sign byte.
+func bulkFixedBytes(fieldFmt FieldFmt, value interface{}) ([]byte, error) {
+	dataType := fieldFmt.DataType()
+
+	bs, err := dataType.Bytes(endian, value, fieldFmt.MaxLength())
+	if err != nil {
+		return nil, err
+	}
+
+	length := bulkFixedLength(fieldFmt)
+	if len(bs) > length {
+		return nil, fmt.Errorf("value of %d bytes exceeds column length %d", len(bs), length)
+	}
+
+	if len(bs) == length {
+		return bs, nil
+	}
+
+	padded := make([]byte, length)
+	switch dataType {
+	case asetypes.CHAR:
+		copy(padded, bs)
+		for i := len(bs); i < length; i++ {
+			padded[i] = ' '
+		}
+	case asetypes.BINARY:
+		copy(padded, bs)
+	default:
+		if len(bs) == 0 {
+			return nil, fmt.Errorf("received empty value for %s", dataType)
+		}
+		padded[0] = bs[0]
+		copy(padded[length-len(bs)+1:], bs[1:])
+	}
+
+	return padded, nil
+}
+
+// bulkValue returns the Go value of the bytes of a column.
+func bulkValue(fieldFmt FieldFmt, bs []byte) (interface{}, error) {
+	if len(bs) == 0 {
+		return nil, nil
+	}
+
+	value, err := fieldFmt.DataType().GoValue(endian, bs)
+	if err != nil {
+		return nil, err
+	}
+
+	if dec, ok := value.(*asetypes.Decimal); ok {
+		switch typed := fieldFmt.(type) {
+		case *DecNFieldFmt:
+			dec.Precision = int(typed.precision)
+			dec.Scale = int(typed.scale)
+		case *NumNFieldFmt:
+			dec.Precision = int(typed.precision)
+			dec.Scale = int(typed.scale)
+		}
+	}
+
+	return value, nil
+}
+
+// encode encodes the values of DataFields in the native row format.
+func (pkg *BulkRowPackage) encode() error {
+	// The number of variable columns and the row number.
+	record := []byte{0, 0}
+
+	bitPos, bitCount := 0, 8
+	for i, field := range pkg.DataFields {
+		fieldFmt := field.Format()
+		if isBulkVariable(fieldFmt) {
+			continue
+		}
+
+		if field.Value() == nil {
+			return fmt.Errorf("column %d (%s) does not allow NULL", i, fieldFmt.DataType())
+		}
+
+		bs, err := bulkFixedBytes(fieldFmt, field.Value())
+		if err != nil {
+			return fmt.Errorf("error converting column %d (%s): %w", i, fieldFmt.DataType(), err)
+		}
+
+		if fieldFmt.DataType() == asetypes.BIT {
+			if bitCount == 8 {
+				bitPos, bitCount = len(record), 0
+				record = append(record, 0)
+			}
+			if bs[0] != 0 {
+				record[bitPos] |= 1 << bitCount
+			}
+			bitCount++
+			continue
+		}
+
+		record = append(record, bs...)
+	}
+
+	lengthPos := len(record)
+	record = append(record, 0, 0)
+	offsets := []int{len(record)}
+
+	pkg.blobs = nil
+	for i, field := range pkg.DataFields {
+		fieldFmt := field.Format()
+		if !isBulkVariable(fieldFmt) {
+			continue
+		}
+
+		dataType := fieldFmt.DataType()
+		value := field.Value()
+		if value == nil && RowFmtStatus(fieldFmt.Status())&TDS_ROW_NULLALLOWED != TDS_ROW_NULLALLOWED {
+			return fmt.Errorf("column %d (%s) does not allow NULL", i, dataType)
+		}
+
+		bs, err := dataType.Bytes(endian, value, fieldFmt.MaxLength())
+		if err != nil {
+			return fmt.Errorf("error converting column %d (%s): %w", i, dataType, err)
+		}
+
+		switch {
+		case isBulkBlob(dataType):
+			pkg.blobs = append(pkg.blobs, bulkBlob{dataType: dataType, textPos: len(record), data: bs})
+			// The text pointer is assigned by the server.
+			if value != nil {
+				record = append(record, make([]byte, bulkTextPtrSize)...)
+			}
+		case value != nil:
+			if maxLength := fieldFmt.MaxLength(); maxLength > 0 && int64(len(bs)) > maxLength {
+				return fmt.Errorf("value of column %d (%s) with %d bytes exceeds column length %d",
+					i, dataType, len(bs), maxLength)
+			}
+
+			// Empty strings are stored as a single space.
+			if len(bs) == 0 && (dataType == asetypes.CHAR || dataType == asetypes.VARCHAR || dataType == asetypes.LONGCHAR) {
+				bs = []byte{' '}
+			}
+			record = append(record, bs...)
+		}
+
+		offsets = append(offsets, len(record))
+	}
+
+	if len(offsets) > math.MaxUint8+1 {
+		return fmt.Errorf("bulk rows support at most %d variable columns, received %d",
+			math.MaxUint8, len(offsets)-1)
+	}
+
+	if len(offsets) == 1 {
+		record = record[:lengthPos]
+	} else {
+		record = appendBulkOffsets(record, offsets)
+		record[0] = byte(len(offsets) - 1)
+	}
+
+	if len(record) > math.MaxUint16 {
+		return fmt.Errorf("row of %d bytes exceeds maximum of %d bytes", len(record), math.MaxUint16)
+	}
+
+	if len(offsets) > 1 {
+		binary.LittleEndian.PutUint16(record[lengthPos:], uint16(len(record)))
+	}
+
+	pkg.record = record
+	return nil
+}
+
+// appendBulkOffsets appends the adjust and offset tables of the
+// variable columns starting at offsets to record.
+//
+// Both tables are written in reverse. The offset table holds the number
+// of offsets followed by the low bytes of the offsets, from the end of
+// the last column down to the start of the first column. If the offsets
+// exceed a byte the adjust table encodes their high bytes: for each
+// high byte from the largest down to 1 it holds one more than the
+// number of offsets below it.
+func appendBulkOffsets(record []byte, offsets []int) []byte {
+	n := len(offsets) - 1
+
+	// As in FreeTDS the number of offsets is only written if the last
+	// column does not cross a high byte.
+	if offsets[n]>>8 == offsets[n-1]>>8 {
+		record = append(record, byte(n+1))
+	}
+
+	for high := offsets[n] >> 8; high > 0; high-- {
+		count := 1
+		for _, offset := range offsets {
+			if offset>>8 < high {
+				count++
+			}
+		}
+		record = append(record, byte(count))
+	}
+
+	for i := n; i >= 0; i-- {
+		record = append(record, byte(offsets[i]))
+	}
+
+	return record
+}
+
+// readBulkOffsets reads the n+1 offsets of n variable columns from the
+// tables at the end of record. start is the offset of the first column.
+func readBulkOffsets(record []byte, start, n int) ([]int, error) {
+	lows := len(record) - (n + 1)
+
+	// The number of entries in the adjust table is the high byte of
+	// the last offset, which is not known before decoding the tables.
+	for top := 0; lows-top >= start; top++ {
+		offsets := make([]int, n+1)
+		for i := range offsets {
+			offsets[i] = int(record[len(record)-1-i])
+		}
+
+		for high := 1; high <= top; high++ {
+			count := int(record[lows-high])
+			if count < 1 {
+				break
+			}
+			for i := count - 1; i <= n; i++ {
+				offsets[i] += 1 << 8
+			}
+		}
+
+		if offsets[n]>>8 != top {
+			continue
+		}
+
+		end := lows - top
+		if offsets[n]>>8 == offsets[n-1]>>8 {
+			end--
+			if end < start || record[end] != byte(n+1) {
+				continue
+			}
+		}
+
+		if offsets[0] != start || offsets[n] != end {
+			continue
+		}
+
+		sorted := true
+		for i := 1; i <= n; i++ {
+			if offsets[i] < offsets[i-1] {
+				sorted = false
+				break
+			}
+		}
+
+		if sorted {
+			return offsets, nil
+		}
+	}
+
+	return nil, errors.New("invalid offset table")
+}
+
+// ReadFrom implements the tds.Package interface.
+func (pkg *BulkRowPackage) ReadFrom(ch BytesChannel) error {
+	length, err := ch.Uint16()
+	if err != nil {
+		return ErrNotEnoughBytes
+	}
+
+	record, err := ch.Bytes(int(length))
+	if err != nil {
+		return ErrNotEnoughBytes
+	}
+
+	if len(record) < 2 {
+		return fmt.Errorf("bulk row of %d bytes is too short", len(record))
+	}
+
+	pos := 2
+	bitPos, bitCount := 0, 8
+	variable := 0
+	for i, field := range pkg.DataFields {
+		fieldFmt := field.Format()
+		if isBulkVariable(fieldFmt) {
+			variable++
+			continue
+		}
+
+		if fieldFmt.DataType() == asetypes.BIT {
+			if bitCount == 8 {
+				if pos >= len(record) {
+					return fmt.Errorf("bulk row ends before column %d", i)
+				}
+				bitPos, bitCount = pos, 0
+				pos++
+			}
+			field.SetValue(record[bitPos]&(1<<bitCount) != 0)
+			bitCount++
+			continue
+		}
+
+		size := bulkFixedLength(fieldFmt)
+		if pos+size > len(record) {
+			return fmt.Errorf("bulk row ends before column %d", i)
+		}
+
+		value, err := bulkValue(fieldFmt, record[pos:pos+size])
+		if err != nil {
+			return fmt.Errorf("error reading column %d (%s): %w", i, fieldFmt.DataType(), err)
+		}
+		field.SetValue(value)
+		pos += size
+	}
+
+	if int(record[0]) != variable {
+		return fmt.Errorf("bulk row has %d variable columns, expected %d", record[0], variable)
+	}
+
+	if variable == 0 {
+		if pos != len(record) {
+			return fmt.Errorf("bulk row has %d trailing bytes", len(record)-pos)
+		}
+		return nil
+	}
+
+	if pos+2 > len(record) || int(binary.LittleEndian.Uint16(record[pos:])) != len(record) {
+		return fmt.Errorf("bulk row does not contain its length %d", len(record))
+	}
+
+	offsets, err := readBulkOffsets(record, pos+2, variable)
+	if err != nil {
+		return err
+	}
+
+	blobs := []int{}
+	column := 0
+	for i, field := range pkg.DataFields {
+		fieldFmt := field.Format()
+		if !isBulkVariable(fieldFmt) {
+			continue
+		}
+
+		if isBulkBlob(fieldFmt.DataType()) {
+			blobs = append(blobs, i)
+			column++
+			continue
+		}
+
+		value, err := bulkValue(fieldFmt, record[offsets[column]:offsets[column+1]])
+		if err != nil {
+			return fmt.Errorf("error reading column %d (%s): %w", i, fieldFmt.DataType(), err)
+		}
+		field.SetValue(value)
+		column++
+	}
+
+	for i, index := range blobs {
+		if _, err := ch.Uint16(); err != nil {
+			return ErrNotEnoughBytes
+		}
+
+		if _, err := ch.Uint8(); err != nil {
+			return ErrNotEnoughBytes
+		}
+
+		blobIndex, err := ch.Uint8()
+		if err != nil {
+			return ErrNotEnoughBytes
+		}
+		if int(blobIndex) != math.MaxUint8-i {
+			return fmt.Errorf("received blob index %d for column %d, expected %d", blobIndex, index, math.MaxUint8-i)
+		}
+
+		if _, err := ch.Uint16(); err != nil {
+			return ErrNotEnoughBytes
+		}
+
+		blobLength, err := ch.Uint32()
+		if err != nil {
+			return ErrNotEnoughBytes
+		}
+
+		bs, err := ch.Bytes(int(blobLength))
+		if err != nil {
+			return ErrNotEnoughBytes
+		}
+
+		field := pkg.DataFields[index]
+		value, err := bulkValue(field.Format(), bs)
+		if err != nil {
+			return fmt.Errorf("error reading column %d (%s): %w", index, field.Format().DataType(), err)
+		}
+		field.SetValue(value)
+	}
+
+	return nil
+}
+
+// WriteTo implements the tds.Package interface.
+func (pkg BulkRowPackage) WriteTo(ch BytesChannel) error {
+	if pkg.record == nil {
+		if err := pkg.encode(); err != nil {
+			return err
+		}
+	}
+
+	if err := ch.WriteUint16(uint16(len(pkg.record))); err != nil {
+		return fmt.Errorf("error occurred writing row length: %w", err)
+	}
+
+	if err := ch.WriteBytes(pkg.record); err != nil {
+		return fmt.Errorf("error occurred writing row: %w", err)
+	}
+
+	for i, blob := range pkg.blobs {
+		// Unknown, always zero.
+		if err := ch.WriteUint16(0); err != nil {
+			return fmt.Errorf("error occurred writing blob %d: %w", i, err)
+		}
+
+		if err := ch.WriteUint8(uint8(blob.dataType)); err != nil {
+			return fmt.Errorf("error occurred writing blob %d data type: %w", i, err)
+		}
+
+		if err := ch.WriteUint8(uint8(math.MaxUint8 - i)); err != nil {
+			return fmt.Errorf("error occurred writing blob %d index: %w", i, err)
+		}
+
+		if err := ch.WriteUint16(uint16(blob.textPos)); err != nil {
+			return fmt.Errorf("error occurred writing blob %d text pointer position: %w", i, err)
+		}
+
+		if err := ch.WriteUint32(uint32(len(blob.data))); err != nil {
+			return fmt.Errorf("error occurred writing blob %d length: %w", i, err)
+		}
+
+		if err := ch.WriteBytes(blob.data); err != nil {
+			return fmt.Errorf("error occurred writing blob %d data: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+func (pkg BulkRowPackage) String() string {
+	s := make([]string, len(pkg.DataFields))
+	for i, field := range pkg.DataFields {
+		s[i] = fmt.Sprintf("%v", field.Value())
+	}
+	return fmt.Sprintf("%T(%d): %s", pkg, len(pkg.DataFields), s)
+}
@@ -0,0 +1,207 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tds
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/SAP/go-dblib/asetypes"
+)
+
+func TestBulkRowPackage(t *testing.T) {
+	type column struct {
+		dataType  asetypes.DataType
+		maxLength int64
+		nullable  bool
+		value     interface{}
+	}
+
+	long := func(b byte) string {
+		return string(bytes.Repeat([]byte{b}, 200))
+	}
+
+	cases := map[string]struct {
+		columns []column
+		// expected is the row as sent, including the length prefix
+		// and blobs.
+		expected []byte
+		// read are the values read from the row, if they differ from
+		// the values of the columns.
+		read []interface{}
+	}{
+		"fixed columns": {
+			columns: []column{
+				{dataType: asetypes.INT4, value: int32(1)},
+				{dataType: asetypes.CHAR, maxLength: 4, value: "ab"},
+				{dataType: asetypes.BIT, value: true},
+				{dataType: asetypes.BIT, value: false},
+				{dataType: asetypes.BIT, value: true},
+			},
+			expected: []byte{
+				11, 0,
+				0, 0,
+				1, 0, 0, 0,
+				'a', 'b', ' ', ' ',
+				0x5,
+			},
+			read: []interface{}{int32(1), "ab  ", true, false, true},
+		},
+		"variable columns": {
+			columns: []column{
+				{dataType: asetypes.INT4, value: int32(7)},
+				{dataType: asetypes.VARCHAR, maxLength: 10, value: "abc"},
+				{dataType: asetypes.INTN, nullable: true},
+			},
+			expected: []byte{
+				15, 0,
+				2, 0,
+				7, 0, 0, 0,
+				15, 0,
+				'a', 'b', 'c',
+				3, 11, 11, 8,
+			},
+		},
+		"empty string": {
+			columns: []column{
+				{dataType: asetypes.VARCHAR, maxLength: 10, value: ""},
+			},
+			expected: []byte{
+				8, 0,
+				1, 0,
+				8, 0,
+				' ',
+				2, 5, 4,
+			},
+			read: []interface{}{" "},
+		},
+		"adjust table": {
+			columns: []column{
+				{dataType: asetypes.INT4, value: int32(7)},
+				{dataType: asetypes.VARCHAR, maxLength: 255, value: long('a')},
+				{dataType: asetypes.VARCHAR, maxLength: 255, value: long('b')},
+			},
+			expected: append(append(append([]byte{
+				0x9c, 0x01,
+				2, 0,
+				7, 0, 0, 0,
+				0x9c, 0x01,
+			}, long('a')...), long('b')...),
+				3, 0x98, 0xd0, 8,
+			),
+		},
+		"text": {
+			columns: []column{
+				{dataType: asetypes.INT4, value: int32(7)},
+				{dataType: asetypes.TEXT, value: "hello"},
+				{dataType: asetypes.IMAGE, nullable: true},
+			},
+			expected: []byte{
+				28, 0,
+				2, 0,
+				7, 0, 0, 0,
+				28, 0,
+				0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+				3, 24, 24, 8,
+				0, 0, byte(asetypes.TEXT), 0xff, 8, 0, 5, 0, 0, 0, 'h', 'e', 'l', 'l', 'o',
+				0, 0, byte(asetypes.IMAGE), 0xfe, 24, 0, 0, 0, 0, 0,
+			},
+		},
+	}
+
+	for title, cas := range cases {
+		t.Run(title, func(t *testing.T) {
+			data := make([]FieldData, len(cas.columns))
+			read := make([]FieldData, len(cas.columns))
+			values := make([]interface{}, len(cas.columns))
+			for i, col := range cas.columns {
+				fieldFmt, err := LookupFieldFmt(col.dataType)
+				if err != nil {
+					t.Fatalf("error looking up format: %v", err)
+				}
+				if col.maxLength > 0 {
+					fieldFmt.setMaxLength(col.maxLength)
+				}
+				if col.nullable {
+					fieldFmt.SetStatus(uint(TDS_ROW_NULLALLOWED))
+				}
+
+				data[i], err = LookupFieldData(fieldFmt)
+				if err != nil {
+					t.Fatalf("error looking up data: %v", err)
+				}
+				data[i].SetValue(col.value)
+
+				read[i], err = LookupFieldData(fieldFmt)
+				if err != nil {
+					t.Fatalf("error looking up data: %v", err)
+				}
+
+				values[i] = col.value
+			}
+
+			queue := NewPacketQueue(func() int { return 512 })
+			if err := NewBulkRowPackage(data...).WriteTo(queue); err != nil {
+				t.Fatalf("error writing row: %v", err)
+			}
+
+			received := []byte{}
+			for _, packet := range queue.Packets() {
+				received = append(received, packet.Data[:int(packet.Header.Length)-PacketHeaderSize]...)
+			}
+
+			if !bytes.Equal(received, cas.expected) {
+				t.Fatalf("received unexpected row:\nexpected: %v\nreceived: %v", cas.expected, received)
+			}
+
+			readQueue := NewPacketQueue(func() int { return 512 })
+			for _, packet := range queue.Packets() {
+				readQueue.AddPacket(packet)
+			}
+
+			if err := NewBulkRowPackage(read...).ReadFrom(readQueue); err != nil {
+				t.Fatalf("error reading row: %v", err)
+			}
+
+			if cas.read != nil {
+				values = cas.read
+			}
+			for i, field := range read {
+				if !reflect.DeepEqual(field.Value(), values[i]) {
+					t.Errorf("expected value %#v in column %d, received %#v", values[i], i, field.Value())
+				}
+			}
+		})
+	}
+}
+
+func TestBulkRowPackage_Invalid(t *testing.T) {
+	cases := map[string]struct {
+		dataType  asetypes.DataType
+		maxLength int64
+		value     interface{}
+	}{
+		"fixed NULL":    {dataType: asetypes.INT4},
+		"variable NULL": {dataType: asetypes.VARCHAR, maxLength: 10},
+		"char too long": {dataType: asetypes.CHAR, maxLength: 2, value: "abc"},
+		"too long":      {dataType: asetypes.VARCHAR, maxLength: 2, value: "abc"},
+	}
+
+	for title, cas := range cases {
+		t.Run(title, func(t *testing.T) {
+			fieldFmt, data, err := LookupFieldFmtData(cas.dataType)
+			if err != nil {
+				t.Fatalf("error looking up field: %v", err)
+			}
+			fieldFmt.setMaxLength(cas.maxLength)
+			data.SetValue(cas.value)
+
+			if err := NewBulkRowPackage(data).encode(); err == nil {
+				t.Errorf("expected error encoding %v", cas.value)
+			}
+		})
+	}
+}
@@ -16,6 +16,8 @@ const (
 	TDS_CUR_COPT_DEALLOC CursorCloseOption = 0x1
 )
 
+var _ Package = (*CurClosePackage)(nil)
+
 // CurClosePackage is used to close a cursor.
 type CurClosePackage struct {
 	CursorID int32
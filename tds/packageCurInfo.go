@@ -60,6 +60,11 @@ type CurInfoPackage struct {
 	wide bool
 }
 
+// NewCurInfoPackage returns a CurInfoPackage.
+func NewCurInfoPackage(wide bool) *CurInfoPackage {
+	return &CurInfoPackage{wide: wide}
+}
+
 // ReadFrom implements the tds.Package interface.
 func (pkg *CurInfoPackage) ReadFrom(ch BytesChannel) error {
 	totalLength, err := ch.Uint16()
@@ -144,14 +144,18 @@ func (pkg EEDPackage) WriteTo(ch BytesChannel) error {
 	// 4 msgnumber
 	// 1 state
 	// 1 class
+	// 1 len(sqlstate)
 	// x sqlstate
 	// 1 status
 	// 2 transtate
+	// 2 len(msg)
 	// x msg
+	// 1 len(servername)
 	// x servername
+	// 1 len(procname)
 	// x procname
 	// 2 linenr
-	length := 11 + len(pkg.SQLState) + len(pkg.Msg) + len(pkg.ServerName) + len(pkg.ProcName)
+	length := 16 + len(pkg.SQLState) + len(pkg.Msg) + len(pkg.ServerName) + len(pkg.ProcName)
 
 	if err := ch.WriteUint16(uint16(length)); err != nil {
 		return fmt.Errorf("failed to write length: %w", err)
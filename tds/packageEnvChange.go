@@ -23,6 +23,12 @@ type EnvChangePackage struct {
 	members []EnvChangePackageField
 }
 
+// NewEnvChangePackage returns an EnvChangePackage with the passed
+// changes.
+func NewEnvChangePackage(members ...EnvChangePackageField) *EnvChangePackage {
+	return &EnvChangePackage{members: members}
+}
+
 // ReadFrom implements the tds.Package interface.
 func (pkg *EnvChangePackage) ReadFrom(ch BytesChannel) error {
 	length, err := ch.Uint16()
@@ -0,0 +1,72 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tds
+
+import "fmt"
+
+var _ Package = (*EventNoticePackage)(nil)
+
+// EventNoticePackage communicates the occurrence of an event the client
+// registered for.
+//
+// The parameters of the event follow in a ParamFmtPackage and
+// a ParamsPackage, the notification is terminated by a DonePackage with
+// TDS_DONE_EVENT.
+type EventNoticePackage struct {
+	Name string
+}
+
+// ReadFrom implements the tds.Package interface.
+func (pkg *EventNoticePackage) ReadFrom(ch BytesChannel) error {
+	totalLength, err := ch.Uint16()
+	if err != nil {
+		return ErrNotEnoughBytes
+	}
+
+	nameLength, err := ch.Uint8()
+	if err != nil {
+		return ErrNotEnoughBytes
+	}
+	n := 1
+
+	pkg.Name, err = ch.String(int(nameLength))
+	if err != nil {
+		return ErrNotEnoughBytes
+	}
+	n += int(nameLength)
+
+	if n != int(totalLength) {
+		return fmt.Errorf("expected to read %d bytes, read %d bytes instead", totalLength, n)
+	}
+
+	return nil
+}
+
+// WriteTo implements the tds.Package interface.
+func (pkg EventNoticePackage) WriteTo(ch BytesChannel) error {
+	if err := ch.WriteByte(byte(TDS_EVENTNOTICE)); err != nil {
+		return fmt.Errorf("error writing token: %w", err)
+	}
+
+	// 1 name length
+	// x name
+	if err := ch.WriteUint16(uint16(1 + len(pkg.Name))); err != nil {
+		return fmt.Errorf("error writing length: %w", err)
+	}
+
+	if err := ch.WriteUint8(uint8(len(pkg.Name))); err != nil {
+		return fmt.Errorf("error writing name length: %w", err)
+	}
+
+	if err := ch.WriteString(pkg.Name); err != nil {
+		return fmt.Errorf("error writing name: %w", err)
+	}
+
+	return nil
+}
+
+func (pkg EventNoticePackage) String() string {
+	return fmt.Sprintf("%T(%s)", pkg, pkg.Name)
+}
@@ -78,7 +78,14 @@ func (pkg LoginAckPackage) WriteTo(ch BytesChannel) error {
 		return fmt.Errorf("failed to write TDS Token %s: %w", TDS_LOGINACK, err)
 	}
 
-	if err := ch.WriteUint16(pkg.Length); err != nil {
+	// 1 status
+	// 4 version
+	// 1 len(programname)
+	// x programname
+	// 4 programversion
+	length := 10 + len(pkg.ProgramName)
+
+	if err := ch.WriteUint16(uint16(length)); err != nil {
 		return fmt.Errorf("failed to write length: %w", err)
 	}
 
@@ -90,7 +97,7 @@ func (pkg LoginAckPackage) WriteTo(ch BytesChannel) error {
 		return fmt.Errorf("failed to write : %w", err)
 	}
 
-	if err := ch.WriteUint8(pkg.NameLength); err != nil {
+	if err := ch.WriteUint8(uint8(len(pkg.ProgramName))); err != nil {
 		return fmt.Errorf("failed to write : %w", err)
 	}
 
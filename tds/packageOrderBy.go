@@ -51,7 +51,21 @@ func (pkg *OrderByPackage) ReadFrom(ch BytesChannel) error {
 
 // WriteTo implements the tds.Package interface.
 func (pkg OrderByPackage) WriteTo(ch BytesChannel) error {
-	return fmt.Errorf("not implemented")
+	if err := ch.WriteByte(byte(TDS_ORDERBY)); err != nil {
+		return fmt.Errorf("error writing token: %w", err)
+	}
+
+	if err := ch.WriteUint16(uint16(len(pkg.ColumnOrder))); err != nil {
+		return fmt.Errorf("error writing column count: %w", err)
+	}
+
+	for i, colNum := range pkg.ColumnOrder {
+		if err := ch.WriteUint8(uint8(colNum)); err != nil {
+			return fmt.Errorf("error writing column order for %d: %w", i, err)
+		}
+	}
+
+	return nil
 }
 
 func (pkg OrderByPackage) String() string {
@@ -98,9 +112,28 @@ func (pkg *OrderBy2Package) ReadFrom(ch BytesChannel) error {
 	return nil
 }
 
-// WriteTo is not implemented.
+// WriteTo implements the tds.Package interface.
 func (pkg OrderBy2Package) WriteTo(ch BytesChannel) error {
-	return fmt.Errorf("not implemented")
+	if err := ch.WriteByte(byte(TDS_ORDERBY2)); err != nil {
+		return fmt.Errorf("error writing token: %w", err)
+	}
+
+	// 2 bytes column count, 2 bytes per column
+	if err := ch.WriteUint32(uint32(2 + 2*len(pkg.ColumnOrder))); err != nil {
+		return fmt.Errorf("error writing byte length: %w", err)
+	}
+
+	if err := ch.WriteUint16(uint16(len(pkg.ColumnOrder))); err != nil {
+		return fmt.Errorf("error writing column count: %w", err)
+	}
+
+	for i, colNum := range pkg.ColumnOrder {
+		if err := ch.WriteUint16(uint16(colNum)); err != nil {
+			return fmt.Errorf("error writing column order for %d: %w", i, err)
+		}
+	}
+
+	return nil
 }
 
 func (pkg OrderBy2Package) String() string {
@@ -16,6 +16,22 @@ type ParamsPackage struct {
 	paramFmt   *ParamFmtPackage
 	rowFmt     *RowFmtPackage
 	DataFields []FieldData
+
+	// altFmts are the formats of the compute clauses of the result set
+	altFmts []*AltFmtPackage
+
+	// lobStream is set if the data of TEXT, IMAGE, UNITEXT and XML
+	// fields is streamed. The reader of the last streamed field is
+	// stored in streamed and fieldIndex is the index of the field
+	// following it.
+	lobStream  *lobStream
+	streamed   *LobReader
+	fieldIndex int
+}
+
+// lobStreamer is implemented by FieldData whose data can be streamed.
+type lobStreamer interface {
+	readHeaderFrom(ch BytesChannel) (int, int, error)
 }
 
 // RowPackage is used to communicate a row.
@@ -30,6 +46,15 @@ func NewParamsPackage(data ...FieldData) *ParamsPackage {
 	}
 }
 
+// NewRowPackage returns an initialized RowPackage.
+func NewRowPackage(data ...FieldData) *RowPackage {
+	return &RowPackage{
+		ParamsPackage: ParamsPackage{
+			DataFields: data,
+		},
+	}
+}
+
 // LastPkg implements the tds.LastPkgAcceptor interface.
 func (pkg *ParamsPackage) LastPkg(other Package) error {
 	switch otherPkg := other.(type) {
@@ -41,6 +66,9 @@ func (pkg *ParamsPackage) LastPkg(other Package) error {
 		pkg.paramFmt = otherPkg.paramFmt
 	case *RowPackage:
 		pkg.rowFmt = otherPkg.rowFmt
+		pkg.altFmts = otherPkg.altFmts
+	case *AltNamePackage, *AltFmtPackage, *AltRowPackage, *AltControlPackage:
+		pkg.rowFmt, pkg.altFmts, _ = resultFmts(otherPkg)
 	case *OrderByPackage:
 		pkg.rowFmt = otherPkg.rowFmt
 	case *OrderBy2Package:
@@ -80,7 +108,22 @@ func (pkg *ParamsPackage) LastPkg(other Package) error {
 
 // ReadFrom implements the tds.Package interface.
 func (pkg *ParamsPackage) ReadFrom(ch BytesChannel) error {
-	for i, field := range pkg.DataFields {
+	for i := pkg.fieldIndex; i < len(pkg.DataFields); i++ {
+		field := pkg.DataFields[i]
+
+		if streamer, ok := field.(lobStreamer); ok && pkg.lobStream != nil {
+			_, dataLen, err := streamer.readHeaderFrom(ch)
+			if err != nil {
+				return fmt.Errorf("error occurred reading param field %d header (%s): %w",
+					i, field.Format().DataType(), err)
+			}
+
+			pkg.streamed = newLobReader(dataLen, pkg.lobStream)
+			field.SetValue(pkg.streamed)
+			pkg.fieldIndex = i + 1
+			return errLobStream
+		}
+
 		// TODO can the written byte count be validated?
 		if _, err := field.ReadFrom(ch); err != nil {
 			return fmt.Errorf("error occurred reading param field %d data (%s): %w",
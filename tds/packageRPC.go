@@ -0,0 +1,133 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tds
+
+import "fmt"
+
+//go:generate stringer -type=RPCOption
+
+// RPCOption is the type of options of RPC packages.
+type RPCOption uint16
+
+const (
+	TDS_RPC_UNUSED    RPCOption = 0x0
+	TDS_RPC_RECOMPILE RPCOption = 0x1
+	TDS_RPC_PARAMS    RPCOption = 0x2
+)
+
+var _ Package = (*RPCPackage)(nil)
+
+// RPCPackage is used to call a stored procedure by name.
+//
+// If the stored procedure is called with parameters the package must
+// have TDS_RPC_PARAMS set and be followed by a ParamFmtPackage and
+// a ParamsPackage.
+type RPCPackage struct {
+	Name    string
+	Options RPCOption
+
+	// wide differentiates TDS_DBRPC from TDS_DBRPC2 and considers the
+	// name length to be 2 bytes.
+	wide bool
+}
+
+// NewRPCPackage returns a new RPCPackage.
+func NewRPCPackage(wide bool, name string, options RPCOption) *RPCPackage {
+	return &RPCPackage{wide: wide, Name: name, Options: options}
+}
+
+// ReadFrom implements the tds.Package interface.
+func (pkg *RPCPackage) ReadFrom(ch BytesChannel) error {
+	totalLength, err := ch.Uint16()
+	if err != nil {
+		return ErrNotEnoughBytes
+	}
+
+	var nameLength, n int
+	if pkg.wide {
+		length, err := ch.Uint16()
+		if err != nil {
+			return ErrNotEnoughBytes
+		}
+		nameLength = int(length)
+		n += 2
+	} else {
+		length, err := ch.Uint8()
+		if err != nil {
+			return ErrNotEnoughBytes
+		}
+		nameLength = int(length)
+		n++
+	}
+
+	pkg.Name, err = ch.String(nameLength)
+	if err != nil {
+		return ErrNotEnoughBytes
+	}
+	n += nameLength
+
+	options, err := ch.Uint16()
+	if err != nil {
+		return ErrNotEnoughBytes
+	}
+	pkg.Options = RPCOption(options)
+	n += 2
+
+	if n != int(totalLength) {
+		return fmt.Errorf("expected to read %d bytes, read %d bytes instead", totalLength, n)
+	}
+
+	return nil
+}
+
+// WriteTo implements the tds.Package interface.
+func (pkg RPCPackage) WriteTo(ch BytesChannel) error {
+	token := TDS_DBRPC
+	// 1 or 2 name length (wide)
+	// x name
+	// 2 options
+	totalLength := 1 + len(pkg.Name) + 2
+	if pkg.wide {
+		token = TDS_DBRPC2
+		totalLength++
+	}
+
+	if err := ch.WriteByte(byte(token)); err != nil {
+		return fmt.Errorf("error writing token: %w", err)
+	}
+
+	if err := ch.WriteUint16(uint16(totalLength)); err != nil {
+		return fmt.Errorf("error writing length: %w", err)
+	}
+
+	var err error
+	if pkg.wide {
+		err = ch.WriteUint16(uint16(len(pkg.Name)))
+	} else {
+		err = ch.WriteUint8(uint8(len(pkg.Name)))
+	}
+	if err != nil {
+		return fmt.Errorf("error writing name length: %w", err)
+	}
+
+	if err := ch.WriteString(pkg.Name); err != nil {
+		return fmt.Errorf("error writing name: %w", err)
+	}
+
+	if err := ch.WriteUint16(uint16(pkg.Options)); err != nil {
+		return fmt.Errorf("error writing options: %w", err)
+	}
+
+	return nil
+}
+
+func (pkg RPCPackage) String() string {
+	strOpts := deBitmaskString(int(pkg.Options), int(TDS_RPC_PARAMS),
+		func(i int) string { return RPCOption(i).String() },
+		TDS_RPC_UNUSED.String(),
+	)
+
+	return fmt.Sprintf("%T(%s, %s)", pkg, pkg.Name, strOpts)
+}
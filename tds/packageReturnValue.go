@@ -0,0 +1,111 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tds
+
+import "fmt"
+
+var _ Package = (*ReturnValuePackage)(nil)
+
+// ReturnValuePackage communicates the value of an output parameter of
+// a stored procedure.
+//
+// Newer servers communicate output parameters using ParamFmtPackage
+// and ParamsPackage instead.
+type ReturnValuePackage struct {
+	Fmt  FieldFmt
+	Data FieldData
+}
+
+// NewReturnValuePackage returns a ReturnValuePackage for the passed
+// data.
+func NewReturnValuePackage(data FieldData) *ReturnValuePackage {
+	return &ReturnValuePackage{Fmt: data.Format(), Data: data}
+}
+
+// ReadFrom implements the tds.Package interface.
+func (pkg *ReturnValuePackage) ReadFrom(ch BytesChannel) error {
+	totalLength, err := ch.Uint16()
+	if err != nil {
+		return ErrNotEnoughBytes
+	}
+
+	// The format of the return value is laid out exactly like a field
+	// of a TDS_PARAMFMT.
+	fieldFmt, n, err := (&ParamFmtPackage{}).ReadFromField(ch)
+	if err != nil {
+		return err
+	}
+	pkg.Fmt = fieldFmt
+
+	pkg.Data, err = LookupFieldData(fieldFmt)
+	if err != nil {
+		return fmt.Errorf("error looking up field data for %s: %w", fieldFmt.DataType(), err)
+	}
+
+	n2, err := pkg.Data.ReadFrom(ch)
+	if err != nil {
+		return fmt.Errorf("error reading return value data: %w", err)
+	}
+	n += n2
+
+	if n != int(totalLength) {
+		return fmt.Errorf("expected to read %d bytes, read %d bytes instead", totalLength, n)
+	}
+
+	return nil
+}
+
+// WriteTo implements the tds.Package interface.
+func (pkg ReturnValuePackage) WriteTo(ch BytesChannel) error {
+	if pkg.Fmt == nil || pkg.Data == nil {
+		return fmt.Errorf("return value format or data is nil")
+	}
+
+	// The length depends on the data, which is written to a separate
+	// queue first.
+	dataQueue := NewPacketQueue(func() int { return 512 })
+	dataLength, err := pkg.Data.WriteTo(dataQueue)
+	if err != nil {
+		return fmt.Errorf("error writing return value data: %w", err)
+	}
+
+	// 1 namelength
+	// x name
+	// 1 status
+	// 4 usertype
+	// 1 token
+	// x FormatByteLength
+	// 1 locale len
+	// x locale
+	// x data
+	totalLength := 1 + len(pkg.Fmt.Name()) + 1 + 4 + 1 + pkg.Fmt.FormatByteLength() + 1 + len(pkg.Fmt.LocaleInfo()) + dataLength
+
+	if err := ch.WriteByte(byte(TDS_RETURNVALUE)); err != nil {
+		return fmt.Errorf("error writing token: %w", err)
+	}
+
+	if err := ch.WriteUint16(uint16(totalLength)); err != nil {
+		return fmt.Errorf("error writing length: %w", err)
+	}
+
+	if _, err := (ParamFmtPackage{}).WriteToField(ch, pkg.Fmt); err != nil {
+		return fmt.Errorf("error writing return value format: %w", err)
+	}
+
+	for _, packet := range dataQueue.Packets() {
+		if err := ch.WriteBytes(packet.Data); err != nil {
+			return fmt.Errorf("error writing return value data: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (pkg ReturnValuePackage) String() string {
+	if pkg.Fmt == nil || pkg.Data == nil {
+		return fmt.Sprintf("%T()", pkg)
+	}
+	return fmt.Sprintf("%T(%s %s: %v)", pkg, pkg.Fmt.Name(), pkg.Fmt.DataType(), pkg.Data.Value())
+}
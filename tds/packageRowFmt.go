@@ -21,11 +21,26 @@ type RowFmtPackage struct {
 	wide bool
 }
 
+// NewRowFmtPackage returns a new RowFmtPackage.
+func NewRowFmtPackage(wide bool, fmts ...FieldFmt) *RowFmtPackage {
+	return &RowFmtPackage{wide: wide, Fmts: fmts}
+}
+
 // ReadFrom implements the tds.Package interface.
 func (pkg *RowFmtPackage) ReadFrom(ch BytesChannel) error {
-	totalLength, err := ch.Uint32()
-	if err != nil {
-		return ErrNotEnoughBytes
+	var totalLength int
+	if pkg.wide {
+		length, err := ch.Uint32()
+		if err != nil {
+			return ErrNotEnoughBytes
+		}
+		totalLength = int(length)
+	} else {
+		length, err := ch.Uint16()
+		if err != nil {
+			return ErrNotEnoughBytes
+		}
+		totalLength = int(length)
 	}
 
 	colCount, err := ch.Uint16()
@@ -44,7 +59,7 @@ func (pkg *RowFmtPackage) ReadFrom(ch BytesChannel) error {
 		readBytes += n
 	}
 
-	if readBytes != int(totalLength) {
+	if readBytes != totalLength {
 		return fmt.Errorf("expected to read %d bytes, read %d bytes instead",
 			totalLength, readBytes)
 	}
@@ -186,9 +201,144 @@ func (pkg *RowFmtPackage) ReadFromField(ch BytesChannel) (FieldFmt, int, error)
 	return fieldFmt, n, nil
 }
 
-// WriteTo is not implemented.
-func (pkg *RowFmtPackage) WriteTo(ch BytesChannel) error {
-	return fmt.Errorf("not implemented")
+// WriteTo implements the tds.Package interface.
+func (pkg RowFmtPackage) WriteTo(ch BytesChannel) error {
+	var err error
+	if pkg.wide {
+		err = ch.WriteByte(byte(TDS_ROWFMT2))
+	} else {
+		err = ch.WriteByte(byte(TDS_ROWFMT))
+	}
+	if err != nil {
+		return fmt.Errorf("error occurred writing TDS Token %s: %w", TDS_ROWFMT, err)
+	}
+
+	// 2 bytes column count, x bytes for columns
+	length := 2
+	for _, field := range pkg.Fmts {
+		length += pkg.fieldLength(field)
+	}
+
+	if pkg.wide {
+		err = ch.WriteUint32(uint32(length))
+	} else {
+		err = ch.WriteUint16(uint16(length))
+	}
+	if err != nil {
+		return fmt.Errorf("error occurred writing package length: %w", err)
+	}
+
+	if err := ch.WriteUint16(uint16(len(pkg.Fmts))); err != nil {
+		return fmt.Errorf("error occurred writing column count: %w", err)
+	}
+	n := 2
+
+	for i, field := range pkg.Fmts {
+		writtenBytes, err := pkg.WriteToField(ch, field)
+		if err != nil {
+			return fmt.Errorf("error writing column %d: %w", i, err)
+		}
+		n += writtenBytes
+	}
+
+	if n != length {
+		return fmt.Errorf("expected to write %d bytes, wrote %d bytes instead",
+			length, n)
+	}
+
+	return nil
+}
+
+// fieldLength returns the number of bytes required to write the
+// format of the passed field.
+func (pkg RowFmtPackage) fieldLength(field FieldFmt) int {
+	// 1 namelength
+	// x name
+	// 4 or 1 status (wide)
+	// 4 usertype
+	// 1 token
+	// x FormatByteLength
+	// 1 locale len
+	// x locale
+	length := 1 + len(field.Name()) + 1 + 4 + 1 + field.FormatByteLength() + 1 + len(field.LocaleInfo())
+	if pkg.wide {
+		// 1 label length, x label
+		// 1 catalogue length, x catalogue
+		// 1 schema length, x schema
+		// 1 table length, x table
+		length += 3 + 4 + len(field.ColumnLabel()) + len(field.Catalogue()) + len(field.Schema()) + len(field.Table())
+	}
+	return length
+}
+
+// WriteToField writes bytes to the passed channel until either the
+// channel is closed or the package has written all required information.
+func (pkg RowFmtPackage) WriteToField(ch BytesChannel, field FieldFmt) (int, error) {
+	n := 0
+
+	if pkg.wide {
+		for _, s := range []string{field.ColumnLabel(), field.Catalogue(), field.Schema(), field.Table()} {
+			if err := ch.WriteUint8(uint8(len(s))); err != nil {
+				return n, fmt.Errorf("failed to write length of %q: %w", s, err)
+			}
+			n++
+
+			if err := ch.WriteString(s); err != nil {
+				return n, fmt.Errorf("failed to write %q: %w", s, err)
+			}
+			n += len(s)
+		}
+	}
+
+	if err := ch.WriteUint8(uint8(len(field.Name()))); err != nil {
+		return n, fmt.Errorf("failed to write name length: %w", err)
+	}
+	n++
+
+	if err := ch.WriteString(field.Name()); err != nil {
+		return n, fmt.Errorf("failed to write name: %w", err)
+	}
+	n += len(field.Name())
+
+	if pkg.wide {
+		if err := ch.WriteUint32(uint32(field.Status())); err != nil {
+			return n, fmt.Errorf("failed to write status: %w", err)
+		}
+		n += 4
+	} else {
+		if err := ch.WriteUint8(uint8(field.Status())); err != nil {
+			return n, fmt.Errorf("failed to write status: %w", err)
+		}
+		n++
+	}
+
+	if err := ch.WriteInt32(field.UserType()); err != nil {
+		return n, fmt.Errorf("failed to write usertype: %w", err)
+	}
+	n += 4
+
+	if err := ch.WriteByte(byte(field.DataType())); err != nil {
+		return n, fmt.Errorf("failed to write token: %w", err)
+	}
+	n++
+
+	n2, err := field.WriteTo(ch)
+	if err != nil {
+		return n, fmt.Errorf("error writing row format field: %w", err)
+	}
+	n += n2
+
+	if err := ch.WriteUint8(uint8(len(field.LocaleInfo()))); err != nil {
+		return n, fmt.Errorf("failed to write locale info length: %w", err)
+	}
+	n++
+
+	if err := ch.WriteString(field.LocaleInfo()); err != nil {
+		return n, fmt.Errorf("failed to write locale info: %w", err)
+	}
+	n += len(field.LocaleInfo())
+
+	return n, nil
 }
 
 func (pkg RowFmtPackage) String() string {
@@ -130,6 +130,26 @@ func (queue *PacketQueue) IsEOM() bool {
 	return queue.AllPacketsConsumed() && queue.recvEOM
 }
 
+// Packets returns the packets holding the bytes written to the queue.
+//
+// The last packet is truncated to the written bytes, hence Packets
+// should only be called once all bytes have been written.
+func (queue *PacketQueue) Packets() []*Packet {
+	queue.Lock()
+	defer queue.Unlock()
+
+	if queue.indexPacket >= len(queue.queue) {
+		return queue.queue
+	}
+
+	packets := queue.queue[:queue.indexPacket+1]
+	last := packets[len(packets)-1]
+	last.Header.Length = uint16(PacketHeaderSize + queue.indexData)
+	last.Data = last.Data[:queue.indexData]
+
+	return packets
+}
+
 // Read satisfies the io.Reader interface.
 func (queue *PacketQueue) Read(p []byte) (int, error) {
 	var err error
@@ -197,6 +217,36 @@ func (queue *PacketQueue) Bytes(n int) ([]byte, error) {
 	return bs, nil
 }
 
+// AvailableBytes returns up to n bytes from the queue.
+//
+// Unlike Bytes AvailableBytes does not fail if less than n bytes are
+// available, instead it returns the available bytes. The returned byte
+// slice is empty if all packets have been consumed.
+func (queue *PacketQueue) AvailableBytes(n int) []byte {
+	queue.Lock()
+	defer queue.Unlock()
+
+	bs := []byte{}
+	for len(bs) < n && !queue.AllPacketsConsumed() {
+		data := queue.queue[queue.indexPacket].Data
+
+		endIndex := queue.indexData + (n - len(bs))
+		if endIndex > len(data) {
+			endIndex = len(data)
+		}
+
+		bs = append(bs, data[queue.indexData:endIndex]...)
+
+		queue.indexData = endIndex
+		if queue.indexData == len(data) {
+			queue.indexPacket += 1
+			queue.indexData = 0
+		}
+	}
+
+	return bs
+}
+
 // Byte implements the tds.BytesChannel interface.
 func (queue *PacketQueue) Byte() (byte, error) {
 	bs, err := queue.Bytes(1)
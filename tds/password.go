@@ -0,0 +1,158 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/SAP/go-dblib/asetypes"
+)
+
+// ErrPasswordExpired is returned by Login if the password of the login
+// has expired and LoginConfig.NewPassword is not set.
+//
+// The server only permits changing the password in such a session.
+var ErrPasswordExpired = errors.New("password has expired")
+
+// passwordExpiredMsgNumber is the number of the message sent by the
+// server in the response to a login whose password has expired.
+const passwordExpiredMsgNumber = 4022
+
+// nextLoginPackage returns the next package of a login response. EEDs
+// are skipped, an EED reporting an expired password is recorded.
+func (tdsChan *Channel) nextLoginPackage(ctx context.Context) (Package, error) {
+	for {
+		pkg, err := tdsChan.NextPackage(ctx, true)
+		if err != nil {
+			return nil, err
+		}
+
+		if ack, ok := pkg.(*LoginAckPackage); ok && ack.Status == TDS_LOG_SUCCEED {
+			tdsChan.loginAck = ack
+		}
+
+		eed, ok := pkg.(*EEDPackage)
+		if !ok {
+			return pkg, nil
+		}
+
+		if eed.MsgNumber == passwordExpiredMsgNumber {
+			tdsChan.passwordExpired = eed
+		}
+	}
+}
+
+// loginFailed reads the remaining response to a failed login and
+// returns an error for status.
+func (tdsChan *Channel) loginFailed(ctx context.Context, status LoginAckStatus) error {
+	for {
+		pkg, err := tdsChan.nextLoginPackage(ctx)
+		if err != nil {
+			break
+		}
+
+		if done, ok := pkg.(*DonePackage); ok && done.Status&TDS_DONE_MORE != TDS_DONE_MORE {
+			break
+		}
+	}
+
+	if tdsChan.passwordExpired != nil {
+		return fmt.Errorf("login failed: %w: %s", ErrPasswordExpired, tdsChan.passwordExpired.Msg)
+	}
+
+	return fmt.Errorf("login failed: %s", status)
+}
+
+// finishLogin completes a successful login. If the password has expired
+// it is changed to config.NewPassword.
+//
+// The version of the server is set from the LoginAck on the main
+// channel, including when resuming the session after a failover or
+// migration.
+func (tdsChan *Channel) finishLogin(ctx context.Context, config *LoginConfig) error {
+	tdsChan.Reset()
+	tdsChan.loggingIn = false
+
+	if tdsChan.passwordExpired != nil {
+		if config.NewPassword == "" {
+			return fmt.Errorf("%w: %s", ErrPasswordExpired, tdsChan.passwordExpired.Msg)
+		}
+
+		if err := tdsChan.changePassword(ctx, config); err != nil {
+			return err
+		}
+	}
+
+	if err := tdsChan.checkReadOnly(); err != nil {
+		return err
+	}
+
+	if tdsChan.channelId == 0 {
+		return tdsChan.setServerVersion()
+	}
+
+	return nil
+}
+
+// changePassword changes the expired password of the session to
+// config.NewPassword.
+func (tdsChan *Channel) changePassword(ctx context.Context, config *LoginConfig) error {
+	result, err := tdsChan.RPC(ctx, "sp_password",
+		RPCParam{Name: "@caller_pwd", DataType: asetypes.VARCHAR, Value: config.DSN.Password},
+		RPCParam{Name: "@new_pwd", DataType: asetypes.VARCHAR, Value: config.NewPassword},
+	)
+	if err != nil {
+		return fmt.Errorf("error changing expired password: %w", err)
+	}
+
+	if result.HasReturnStatus && result.ReturnStatus != 0 {
+		return fmt.Errorf("error changing expired password: sp_password returned status %d",
+			result.ReturnStatus)
+	}
+
+	tdsChan.passwordExpired = nil
+
+	if tdsChan.channelId == 0 && !tdsChan.resuming {
+		// Logins resuming the session after a failover or migration
+		// must use the new password.
+		tdsChan.tdsConn.haLock.Lock()
+		if stored := tdsChan.tdsConn.loginConfig; stored != nil {
+			dsn := *stored.DSN
+			dsn.Password = config.NewPassword
+			dsn.PasswordSource, dsn.PasswordRef = "", ""
+			stored.DSN = &dsn
+			stored.NewPassword = ""
+		}
+		tdsChan.tdsConn.haLock.Unlock()
+	}
+
+	return nil
+}
+
+// resolvePassword returns config with the password referenced in the
+// DSN resolved.
+//
+// The returned config is a copy, config itself retains the reference so
+// that logins resuming the session resolve it again.
+func (config *LoginConfig) resolvePassword(ctx context.Context) (*LoginConfig, error) {
+	if config.DSN.PasswordSource == "" {
+		return config, nil
+	}
+
+	password, err := config.DSN.ResolvePassword(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	dsn := *config.DSN
+	dsn.Password = password
+	dsn.PasswordSource, dsn.PasswordRef = "", ""
+
+	resolved := *config
+	resolved.DSN = &dsn
+	return &resolved, nil
+}
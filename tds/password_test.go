@@ -0,0 +1,172 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tds_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SAP/go-dblib/dsn"
+	"github.com/SAP/go-dblib/tds"
+	"github.com/SAP/go-dblib/tds/tdstest"
+)
+
+func TestChannel_LoginPasswordExpired(t *testing.T) {
+	cases := map[string]struct {
+		expired      bool
+		newPassword  string
+		err          error
+		nextPassword string
+		nextErr      error
+	}{
+		"not expired": {
+			nextPassword: "pass",
+		},
+		"not expired with new password": {
+			newPassword:  "newpass",
+			nextPassword: "pass",
+		},
+		"expired": {
+			expired:      true,
+			err:          tds.ErrPasswordExpired,
+			nextPassword: "pass",
+			nextErr:      tds.ErrPasswordExpired,
+		},
+		"expired with new password": {
+			expired:      true,
+			newPassword:  "newpass",
+			nextPassword: "newpass",
+		},
+	}
+
+	for title, cas := range cases {
+		t.Run(title, func(t *testing.T) {
+			server, err := tdstest.NewServer("user", "pass")
+			if err != nil {
+				t.Fatalf("error creating server: %v", err)
+			}
+			defer server.Close()
+
+			if cas.expired {
+				server.ExpirePassword()
+			}
+
+			info, err := server.Info()
+			if err != nil {
+				t.Fatalf("error getting info: %v", err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			config, err := tds.NewLoginConfig(info)
+			if err != nil {
+				t.Fatalf("error creating login config: %v", err)
+			}
+			config.NewPassword = cas.newPassword
+
+			conn, err := tds.NewConn(ctx, info)
+			if err != nil {
+				t.Fatalf("error opening connection: %v", err)
+			}
+			defer conn.Close()
+
+			ch, err := conn.NewChannel()
+			if err != nil {
+				t.Fatalf("error opening channel: %v", err)
+			}
+
+			if err := ch.Login(ctx, config); !errors.Is(err, cas.err) {
+				t.Fatalf("expected error %v, received: %v", cas.err, err)
+			}
+
+			// The next login uses the current password.
+			info.Password = cas.nextPassword
+			if err := login(info); !errors.Is(err, cas.nextErr) {
+				t.Errorf("expected error %v logging in with password %q, received: %v",
+					cas.nextErr, cas.nextPassword, err)
+			}
+		})
+	}
+}
+
+func TestChannel_LoginPasswordRef(t *testing.T) {
+	server, err := tdstest.NewServer("user", "pass")
+	if err != nil {
+		t.Fatalf("error creating server: %v", err)
+	}
+	defer server.Close()
+
+	cases := map[string]struct {
+		ref  string
+		file string
+		env  string
+		err  bool
+	}{
+		"file": {
+			ref:  "password-file=%s",
+			file: "pass\n",
+		},
+		"env": {
+			ref: "password-env=TDS_TEST_PASSWORD",
+			env: "pass",
+		},
+		"wrong password": {
+			ref:  "password-file=%s",
+			file: "wrong",
+			err:  true,
+		},
+		"unresolvable": {
+			ref: "password-env=TDS_TEST_PASSWORD",
+			err: true,
+		},
+	}
+
+	for title, cas := range cases {
+		t.Run(title, func(t *testing.T) {
+			info, err := server.Info()
+			if err != nil {
+				t.Fatalf("error getting info: %v", err)
+			}
+			info.Password = ""
+
+			path := filepath.Join(t.TempDir(), "password")
+			if err := dsn.ParseSimple(strings.ReplaceAll(cas.ref, "%s", path), info); err != nil {
+				t.Fatalf("error parsing DSN: %v", err)
+			}
+
+			// References are resolved on login, not when parsing.
+			if cas.file != "" {
+				if err := os.WriteFile(path, []byte(cas.file), 0o600); err != nil {
+					t.Fatalf("error writing password file: %v", err)
+				}
+			}
+			if cas.env != "" {
+				t.Setenv("TDS_TEST_PASSWORD", cas.env)
+			}
+
+			err = login(info)
+			if cas.err {
+				if err == nil {
+					t.Fatalf("expected error logging in")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("error logging in: %v", err)
+			}
+
+			if info.Password != "" {
+				t.Errorf("expected resolved password not to be stored, received %q", info.Password)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tds
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// ErrReadOnlyNotGranted is returned by Login if Info.ReadOnly is set
+// and the server did not grant a read-only session.
+var ErrReadOnlyNotGranted = errors.New("server did not grant a read-only session")
+
+// ReadOnlyError is returned for writes recognized on a connection with
+// Info.ReadOnly set. The write is refused before it is sent to the
+// server.
+//
+// Only bulk copies, positioned updates and deletes of cursors and
+// statements starting with a writing keyword are recognized. All other
+// writes, e.g. in later statements of a batch or in called procedures,
+// are refused by the server, which enforces the read-only session
+// granted with TDS_REQ_READONLY at login.
+type ReadOnlyError struct {
+	// Request is the refused statement or the description of the
+	// refused request.
+	Request string
+}
+
+func (err ReadOnlyError) Error() string {
+	return fmt.Sprintf("connection is read-only, refusing %s", err.Request)
+}
+
+// writeKeywords are the keywords starting statements which modify data
+// or schema.
+var writeKeywords = map[string]struct{}{
+	"alter":     {},
+	"create":    {},
+	"delete":    {},
+	"drop":      {},
+	"dump":      {},
+	"grant":     {},
+	"insert":    {},
+	"load":      {},
+	"merge":     {},
+	"reorg":     {},
+	"revoke":    {},
+	"truncate":  {},
+	"update":    {},
+	"writetext": {},
+}
+
+// isWriteStatement returns true if stmt starts with one of
+// writeKeywords.
+func isWriteStatement(stmt string) bool {
+	_, ok := writeKeywords[leadingKeyword(stmt)]
+	return ok
+}
+
+// leadingKeyword returns the lowercased first word of stmt, skipping
+// whitespace and comments.
+func leadingKeyword(stmt string) string {
+	for {
+		stmt = strings.TrimLeftFunc(stmt, unicode.IsSpace)
+
+		switch {
+		case strings.HasPrefix(stmt, "--"):
+			end := strings.IndexByte(stmt, '\n')
+			if end < 0 {
+				return ""
+			}
+			stmt = stmt[end+1:]
+		case strings.HasPrefix(stmt, "/*"):
+			end := strings.Index(stmt[2:], "*/")
+			if end < 0 {
+				return ""
+			}
+			stmt = stmt[2+end+2:]
+		default:
+			end := strings.IndexFunc(stmt, func(r rune) bool {
+				return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("_@#$", r)
+			})
+			if end < 0 {
+				end = len(stmt)
+			}
+			return strings.ToLower(stmt[:end])
+		}
+	}
+}
+
+// checkWrite returns a ReadOnlyError if pkg is a write and the
+// connection is read-only.
+func (tdsChan *Channel) checkWrite(pkg Package) error {
+	if !tdsChan.tdsConn.info.ReadOnly {
+		return nil
+	}
+
+	if tdsChan.CurrentHeaderType == TDS_BUF_BULK {
+		return ReadOnlyError{Request: "bulk copy"}
+	}
+
+	switch typed := pkg.(type) {
+	case *LanguagePackage:
+		if isWriteStatement(typed.Cmd) {
+			return ReadOnlyError{Request: fmt.Sprintf("%q", typed.Cmd)}
+		}
+	case *DynamicPackage:
+		if (typed.Type == TDS_DYN_PREPARE || typed.Type == TDS_DYN_EXEC_IMMED) && isWriteStatement(typed.Stmt) {
+			return ReadOnlyError{Request: fmt.Sprintf("%q", typed.Stmt)}
+		}
+	case *CurUpdatePackage:
+		return ReadOnlyError{Request: "positioned update of cursor " + typed.Name}
+	case *CurDeletePackage:
+		return ReadOnlyError{Request: "positioned delete of cursor " + typed.Name}
+	}
+
+	return nil
+}
+
+// checkReadOnly returns ErrReadOnlyNotGranted if the connection is
+// read-only and the server did not grant the capability.
+func (tdsChan *Channel) checkReadOnly() error {
+	if tdsChan.tdsConn.info.ReadOnly && !tdsChan.tdsConn.Caps.HasRequestCapability(TDS_REQ_READONLY) {
+		return ErrReadOnlyNotGranted
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tds_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/SAP/go-dblib/asetypes"
+	"github.com/SAP/go-dblib/tds"
+	"github.com/SAP/go-dblib/tds/tdstest"
+)
+
+func TestChannel_ReadOnly(t *testing.T) {
+	server, err := tdstest.NewServer("user", "pass")
+	if err != nil {
+		t.Fatalf("error creating server: %v", err)
+	}
+	defer server.Close()
+
+	single, err := tdstest.Result([]tdstest.Column{{Name: "a", DataType: asetypes.INT4}}, []interface{}{int32(1)})
+	if err != nil {
+		t.Fatalf("error creating result: %v", err)
+	}
+	server.HandleLanguage("select 1 as a", single...)
+	server.HandleLanguage("select 'delete' as a", single...)
+	server.HandleLanguage("select update_date from t", single...)
+	server.HandleLanguage("select load from t", single...)
+	server.HandleLanguage("update_pending", single...)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	info, err := server.Info()
+	if err != nil {
+		t.Fatalf("error getting info: %v", err)
+	}
+	info.ReadOnly = true
+
+	conn, err := tds.NewConn(ctx, info)
+	if err != nil {
+		t.Fatalf("error opening connection: %v", err)
+	}
+	defer conn.Close()
+
+	ch, err := conn.NewChannel()
+	if err != nil {
+		t.Fatalf("error opening channel: %v", err)
+	}
+
+	config, err := tds.NewLoginConfig(info)
+	if err != nil {
+		t.Fatalf("error creating login config: %v", err)
+	}
+
+	if err := ch.Login(ctx, config); err != nil {
+		t.Fatalf("error logging in: %v", err)
+	}
+
+	cases := map[string]struct {
+		pkg     tds.Package
+		refused bool
+	}{
+		"select": {
+			pkg: &tds.LanguagePackage{Cmd: "select 1 as a"},
+		},
+		"insert": {
+			pkg:     &tds.LanguagePackage{Cmd: "insert into t values (1)"},
+			refused: true,
+		},
+		"update after comments": {
+			pkg:     &tds.LanguagePackage{Cmd: "-- comment\n /* comment */ UPDATE t set a = 1"},
+			refused: true,
+		},
+		"keyword in string": {
+			pkg: &tds.LanguagePackage{Cmd: "select 'delete' as a"},
+		},
+		"keyword in identifier": {
+			pkg: &tds.LanguagePackage{Cmd: "select update_date from t"},
+		},
+		"keyword as column": {
+			pkg: &tds.LanguagePackage{Cmd: "select load from t"},
+		},
+		"leading keyword in identifier": {
+			pkg: &tds.LanguagePackage{Cmd: "update_pending"},
+		},
+		"dynamic delete": {
+			pkg:     &tds.DynamicPackage{Type: tds.TDS_DYN_EXEC_IMMED, Stmt: "delete from t"},
+			refused: true,
+		},
+		"positioned update": {
+			pkg:     &tds.CurUpdatePackage{Name: "cur", TableName: "t", Stmt: "update t set a = 1"},
+			refused: true,
+		},
+	}
+
+	for title, cas := range cases {
+		t.Run(title, func(t *testing.T) {
+			err := ch.SendPackage(ctx, cas.pkg)
+
+			var roErr tds.ReadOnlyError
+			if refused := errors.As(err, &roErr); refused != cas.refused {
+				t.Fatalf("expected refused %t, received error: %v", cas.refused, err)
+			}
+
+			if cas.refused {
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("error sending request: %v", err)
+			}
+
+			if _, err := ch.NextPackageUntil(ctx, true, func(pkg tds.Package) (bool, error) {
+				done, ok := pkg.(*tds.DonePackage)
+				return ok && done.Status == tds.TDS_DONE_FINAL, nil
+			}); err != nil {
+				t.Fatalf("error reading response: %v", err)
+			}
+		})
+	}
+
+	// Refused writes must not have been sent, otherwise the server's
+	// responses would precede the result.
+	if err := ch.SendPackage(ctx, &tds.LanguagePackage{Cmd: "select 1 as a"}); err != nil {
+		t.Fatalf("error sending request: %v", err)
+	}
+
+	pkg, err := ch.NextPackage(ctx, true)
+	if err != nil {
+		t.Fatalf("error reading response: %v", err)
+	}
+	if _, ok := pkg.(*tds.RowFmtPackage); !ok {
+		t.Errorf("expected RowFmtPackage as first response, received: %v", pkg)
+	}
+}
@@ -0,0 +1,62 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tds
+
+import (
+	"fmt"
+	"strings"
+)
+
+// packageRedactor formats packages for debug logs without the
+// credentials they may contain.
+//
+// Packages are passed in the order they are sent or received, as the
+// parameters of a security message or RPC are transmitted in packages
+// following the message or RPC.
+type packageRedactor struct {
+	// redactParams is set if the next parameters belong to a security
+	// message or to sp_password.
+	redactParams bool
+}
+
+// isSecurityMsg returns true if messages of id carry credentials or
+// keys as parameters.
+func isSecurityMsg(id TDSMsgId) bool {
+	return strings.HasPrefix(id.String(), "TDS_MSG_SEC_")
+}
+
+// isPasswordProc returns true if name is sp_password, optionally
+// qualified by owner and database, e.g. "sybsystemprocs..sp_password".
+func isPasswordProc(name string) bool {
+	parts := strings.Split(name, ".")
+	return strings.EqualFold(strings.Trim(parts[len(parts)-1], "[]\" "), "sp_password")
+}
+
+// format returns the representation of pkg for debug logs.
+//
+// Login payloads and the parameters of security messages, of messages
+// sent while loggingIn and of sp_password are redacted.
+func (r *packageRedactor) format(pkg Package, loggingIn bool) string {
+	switch typed := pkg.(type) {
+	case *TokenlessPackage:
+		if loggingIn {
+			return fmt.Sprintf("%T(redacted %d bytes)", typed, typed.Data.Len())
+		}
+	case *MsgPackage:
+		r.redactParams = loggingIn || isSecurityMsg(typed.MsgId)
+	case *RPCPackage:
+		r.redactParams = isPasswordProc(typed.Name)
+	case *ParamFmtPackage:
+		// Formats only contain the names and types of the parameters.
+	case *ParamsPackage:
+		if r.redactParams {
+			return fmt.Sprintf("%T(%d): redacted", typed, len(typed.DataFields))
+		}
+	default:
+		r.redactParams = false
+	}
+
+	return fmt.Sprintf("%s", pkg)
+}
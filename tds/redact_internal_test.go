@@ -0,0 +1,45 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tds
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/SAP/go-dblib/asetypes"
+)
+
+func TestPackageRedactor_RPC(t *testing.T) {
+	cases := map[string]struct {
+		name   string
+		redact bool
+	}{
+		"sp_password":                   {name: "sp_password", redact: true},
+		"owner":                         {name: "dbo.sp_password", redact: true},
+		"database":                      {name: "sybsystemprocs..sp_password", redact: true},
+		"quoted":                        {name: "sybsystemprocs.dbo.[sp_password]", redact: true},
+		"upper case":                    {name: "SP_PASSWORD", redact: true},
+		"other procedure":               {name: "sp_who", redact: false},
+		"procedure with password infix": {name: "sp_password_history", redact: false},
+	}
+
+	for title, cas := range cases {
+		t.Run(title, func(t *testing.T) {
+			_, data, err := LookupFieldFmtData(asetypes.VARCHAR)
+			if err != nil {
+				t.Fatalf("error looking up field: %v", err)
+			}
+			data.SetValue("s3cr3t")
+
+			r := &packageRedactor{}
+			r.format(NewRPCPackage(false, cas.name, TDS_RPC_PARAMS), false)
+			s := r.format(NewParamsPackage(data), false)
+
+			if redacted := !strings.Contains(s, "s3cr3t"); redacted != cas.redact {
+				t.Errorf("expected parameters of %q to be redacted: %t, logged: %s", cas.name, cas.redact, s)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tds_test
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SAP/go-dblib/tds"
+	"github.com/SAP/go-dblib/tds/tdstest"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent use.
+type syncBuffer struct {
+	sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.Lock()
+	defer b.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.Lock()
+	defer b.Unlock()
+	return b.buf.String()
+}
+
+func TestInfo_String(t *testing.T) {
+	info := &tds.Info{}
+	if err := tds.SetInfo(info); err != nil {
+		t.Fatalf("error setting info defaults: %v", err)
+	}
+	info.Host = "host"
+	info.Password = "s3cr3t-login"
+	info.RemoteServers = "remote:s3cr3t-remote"
+
+	s := info.String()
+	for _, secret := range []string{"s3cr3t-login", "s3cr3t-remote"} {
+		if strings.Contains(s, secret) {
+			t.Errorf("expected %q to not contain %q", s, secret)
+		}
+	}
+
+	if !strings.Contains(s, `host="host"`) {
+		t.Errorf("expected %q to contain host", s)
+	}
+}
+
+func TestChannel_DebugLogPackages(t *testing.T) {
+	server, err := tdstest.NewServer("user", "s3cr3t-login")
+	if err != nil {
+		t.Fatalf("error creating server: %v", err)
+	}
+	defer server.Close()
+
+	// The expired password is changed with sp_password.
+	server.ExpirePassword()
+
+	info, err := server.Info()
+	if err != nil {
+		t.Fatalf("error getting info: %v", err)
+	}
+	info.DebugLogPackages = true
+	info.RemoteServers = "remote:s3cr3t-remote"
+
+	logs := &syncBuffer{}
+	log.SetOutput(logs)
+	defer log.SetOutput(os.Stderr)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	config, err := tds.NewLoginConfig(info)
+	if err != nil {
+		t.Fatalf("error creating login config: %v", err)
+	}
+	config.NewPassword = "s3cr3t-new"
+
+	conn, err := tds.NewConn(ctx, info)
+	if err != nil {
+		t.Fatalf("error opening connection: %v", err)
+	}
+	defer conn.Close()
+
+	ch, err := conn.NewChannel()
+	if err != nil {
+		t.Fatalf("error opening channel: %v", err)
+	}
+
+	if err := ch.Login(ctx, config); err != nil {
+		t.Fatalf("error logging in: %v", err)
+	}
+
+	s := logs.String()
+	if !strings.Contains(s, "TX: ") || !strings.Contains(s, "redacted") {
+		t.Errorf("expected logs to contain redacted packages, received:\n%s", s)
+	}
+
+	for _, secret := range []string{"s3cr3t-login", "s3cr3t-remote", "s3cr3t-new"} {
+		if strings.Contains(s, secret) {
+			t.Errorf("expected logs to not contain %q:\n%s", secret, s)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tds
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/SAP/go-dblib/asetypes"
+)
+
+// RPCParam is a parameter of a stored procedure call.
+type RPCParam struct {
+	// Name is the name of the parameter including the leading '@'.
+	// Parameters without a name are matched by position.
+	Name     string
+	DataType asetypes.DataType
+	Value    interface{}
+	// Output marks the parameter as an output parameter. The value is
+	// sent to the server and the value set by the procedure is
+	// returned in RPCResult.OutputParams.
+	Output bool
+}
+
+// RPCOutputParam is the value of an output parameter returned by
+// a stored procedure.
+type RPCOutputParam struct {
+	Name  string
+	Fmt   FieldFmt
+	Value interface{}
+}
+
+// RPCResult contains the return status and the output parameters of
+// a stored procedure call.
+type RPCResult struct {
+	// ReturnStatus is the return status of the procedure. It is only
+	// valid if HasReturnStatus is true.
+	ReturnStatus    int32
+	HasReturnStatus bool
+
+	// OutputParams are the output parameters in the order they were
+	// received.
+	OutputParams []RPCOutputParam
+}
+
+// Add records the return status or output parameters communicated by
+// pkg.
+//
+// Add returns true if pkg was recorded or is a format package for
+// output parameters.
+func (result *RPCResult) Add(pkg Package) bool {
+	switch typed := pkg.(type) {
+	case *ReturnStatusPackage:
+		result.ReturnStatus = typed.ReturnValue
+		result.HasReturnStatus = true
+	case *ReturnValuePackage:
+		result.OutputParams = append(result.OutputParams, RPCOutputParam{
+			Name:  typed.Fmt.Name(),
+			Fmt:   typed.Fmt,
+			Value: typed.Data.Value(),
+		})
+	case *ParamFmtPackage:
+		// Format of the following ParamsPackage
+	case *ParamsPackage:
+		for _, field := range typed.DataFields {
+			result.OutputParams = append(result.OutputParams, RPCOutputParam{
+				Name:  field.Format().Name(),
+				Fmt:   field.Format(),
+				Value: field.Value(),
+			})
+		}
+	default:
+		return false
+	}
+
+	return true
+}
+
+// SendRPC sends a call of the stored procedure name with the passed
+// parameters.
+//
+// The response can be processed with NextPackage and RPCResult.Add.
+func (tdsChan *Channel) SendRPC(ctx context.Context, name string, params ...RPCParam) error {
+	options := TDS_RPC_UNUSED
+	if len(params) > 0 {
+		options |= TDS_RPC_PARAMS
+	}
+
+	rpc := NewRPCPackage(tdsChan.tdsConn.Caps.HasRequestCapability(TDS_REQ_DBRPC2), name, options)
+	if err := tdsChan.QueuePackage(ctx, rpc); err != nil {
+		return fmt.Errorf("error queueing RPC package: %w", err)
+	}
+
+	if len(params) > 0 {
+		fmts := make([]FieldFmt, len(params))
+		data := make([]FieldData, len(params))
+		for i, param := range params {
+			var err error
+			fmts[i], data[i], err = LookupFieldFmtData(param.DataType)
+			if err != nil {
+				return fmt.Errorf("error looking up fields for parameter %d (%s): %w", i, param.DataType, err)
+			}
+
+			fmts[i].SetName(param.Name)
+			if param.Output {
+				fmts[i].SetStatus(uint(TDS_PARAM_RETURN))
+			}
+			data[i].SetValue(param.Value)
+		}
+
+		wide := tdsChan.tdsConn.Caps.HasRequestCapability(TDS_WIDETABLES)
+		if err := tdsChan.QueuePackage(ctx, NewParamFmtPackage(wide, fmts...)); err != nil {
+			return fmt.Errorf("error queueing ParamFmt package: %w", err)
+		}
+
+		if err := tdsChan.QueuePackage(ctx, NewParamsPackage(data...)); err != nil {
+			return fmt.Errorf("error queueing Params package: %w", err)
+		}
+	}
+
+	if err := tdsChan.SendRemainingPackets(ctx); err != nil {
+		return fmt.Errorf("error sending RPC: %w", err)
+	}
+
+	return nil
+}
+
+// RPC calls the stored procedure name with the passed parameters and
+// returns its return status and output parameters.
+//
+// Result sets returned by the procedure are discarded - use SendRPC to
+// process them.
+func (tdsChan *Channel) RPC(ctx context.Context, name string, params ...RPCParam) (*RPCResult, error) {
+	if err := tdsChan.SendRPC(ctx, name, params...); err != nil {
+		return nil, err
+	}
+
+	// An error in a statement of the procedure is signalled with
+	// TDS_DONE_MORE, so the remaining response is read up to the last
+	// DonePackage before the error is returned.
+	result := &RPCResult{}
+	failed := false
+	_, err := tdsChan.NextPackageUntil(ctx, true, func(pkg Package) (bool, error) {
+		result.Add(pkg)
+
+		done, ok := pkg.(*DonePackage)
+		if !ok {
+			return false, nil
+		}
+
+		if done.Status&TDS_DONE_ERROR == TDS_DONE_ERROR {
+			failed = true
+		}
+
+		if failed && done.Status&TDS_DONE_MORE != TDS_DONE_MORE {
+			return false, fmt.Errorf("error executing stored procedure %s: %w", name, errDoneError)
+		}
+
+		return isDoneFinal(done)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,166 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tds_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/SAP/go-dblib/asetypes"
+	"github.com/SAP/go-dblib/tds"
+	"github.com/SAP/go-dblib/tds/tdstest"
+)
+
+// addProc responds to calls of sp_add with the sum of @a and @b as
+// the output parameter @sum.
+func addProc(request []tds.Package) ([]tds.Package, bool) {
+	if len(request) != 3 {
+		return nil, false
+	}
+
+	rpc, ok := request[0].(*tds.RPCPackage)
+	if !ok || rpc.Name != "sp_add" || rpc.Options&tds.TDS_RPC_PARAMS != tds.TDS_RPC_PARAMS {
+		return nil, false
+	}
+
+	params, ok := request[2].(*tds.ParamsPackage)
+	if !ok || len(params.DataFields) != 3 {
+		return nil, false
+	}
+
+	var sum int32
+	for _, field := range params.DataFields {
+		if field.Format().Status()&uint(tds.TDS_PARAM_RETURN) == uint(tds.TDS_PARAM_RETURN) {
+			continue
+		}
+		sum += field.Value().(int32)
+	}
+
+	fieldFmt, fieldData, err := tds.LookupFieldFmtData(asetypes.INT4)
+	if err != nil {
+		return nil, false
+	}
+	fieldFmt.SetName("@sum")
+	fieldData.SetValue(sum)
+
+	return []tds.Package{
+		&tds.ReturnStatusPackage{ReturnValue: 0},
+		tds.NewParamFmtPackage(true, fieldFmt),
+		tds.NewParamsPackage(fieldData),
+		&tds.DonePackage{Status: tds.TDS_DONE_FINAL},
+	}, true
+}
+
+func TestChannel_RPC(t *testing.T) {
+	server, err := tdstest.NewServer("user", "pass")
+	if err != nil {
+		t.Fatalf("error creating server: %v", err)
+	}
+	defer server.Close()
+
+	server.HandleFunc(addProc)
+
+	retValFmt, retValData, err := tds.LookupFieldFmtData(asetypes.VARCHAR)
+	if err != nil {
+		t.Fatalf("error looking up VARCHAR: %v", err)
+	}
+	retValFmt.SetName("@out")
+	retValFmt.SetStatus(uint(tds.TDS_PARAM_RETURN))
+	retValData.SetValue("value")
+
+	server.HandleRPC("sp_retval",
+		&tds.ReturnStatusPackage{ReturnValue: 1},
+		tds.NewReturnValuePackage(retValData),
+		&tds.DonePackage{Status: tds.TDS_DONE_FINAL},
+	)
+
+	server.HandleRPC("sp_fail", tdstest.ErrorResponse(2812, "Stored procedure 'sp_fail' not found.")...)
+
+	server.HandleRPC("sp_partial",
+		&tds.EEDPackage{MsgNumber: 515, State: 1, Class: 16, Status: tds.TDS_NO_EED, Msg: "Attempt to insert NULL value."},
+		&tds.DonePackage{Status: tds.TDS_DONE_ERROR | tds.TDS_DONE_MORE},
+		&tds.ReturnStatusPackage{ReturnValue: 1},
+		tds.NewReturnValuePackage(retValData),
+		&tds.DonePackage{Status: tds.TDS_DONE_FINAL},
+	)
+
+	cases := map[string]struct {
+		name   string
+		params []tds.RPCParam
+		result *tds.RPCResult
+		err    bool
+	}{
+		"output params": {
+			name: "sp_add",
+			params: []tds.RPCParam{
+				{Name: "@a", DataType: asetypes.INT4, Value: int32(1)},
+				{Name: "@b", DataType: asetypes.INT4, Value: int32(2)},
+				{Name: "@sum", DataType: asetypes.INT4, Value: int32(0), Output: true},
+			},
+			result: &tds.RPCResult{
+				HasReturnStatus: true,
+				OutputParams:    []tds.RPCOutputParam{{Name: "@sum", Value: int32(3)}},
+			},
+		},
+		"return value": {
+			name: "sp_retval",
+			result: &tds.RPCResult{
+				ReturnStatus:    1,
+				HasReturnStatus: true,
+				OutputParams:    []tds.RPCOutputParam{{Name: "@out", Value: "value"}},
+			},
+		},
+		"error": {
+			name: "sp_fail",
+			err:  true,
+		},
+		"error in statement": {
+			name: "sp_partial",
+			err:  true,
+		},
+	}
+
+	for title, cas := range cases {
+		t.Run(title, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			conn, ch, err := server.Connect(ctx)
+			if err != nil {
+				t.Fatalf("error connecting: %v", err)
+			}
+			defer conn.Close()
+
+			result, err := ch.RPC(ctx, cas.name, cas.params...)
+			if err != nil {
+				if !cas.err {
+					t.Errorf("received unexpected error: %v", err)
+				}
+
+				// The response must be consumed completely
+				if _, err := ch.RPC(ctx, "sp_retval"); err != nil {
+					t.Errorf("error calling procedure after error: %v", err)
+				}
+				return
+			}
+
+			if cas.err {
+				t.Errorf("expected error, received result: %v", result)
+				return
+			}
+
+			// Formats are compared by name and value only
+			for i := range result.OutputParams {
+				result.OutputParams[i].Fmt = nil
+			}
+
+			if !reflect.DeepEqual(result, cas.result) {
+				t.Errorf("received unexpected result:\nexpected: %#v\nreceived: %#v", cas.result, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/SAP/go-dblib/capability"
+)
+
+// Features of ASE servers, available from the listed version on.
+var (
+	CapWideTables        = capability.NewCapability("wide tables with more than 255 columns", "12.5.0")
+	CapUnsignedTypes     = capability.NewCapability("unsigned integer datatypes", "15.0.0")
+	CapScrollableCursors = capability.NewCapability("scrollable cursors", "15.0.0")
+	CapBigDateTime       = capability.NewCapability("bigdatetime and bigtime datatypes", "15.5.0")
+
+	// ServerTarget registers the features of ASE servers whose
+	// availability is set on a ServerVersion.
+	ServerTarget = capability.Target{
+		Capabilities: []*capability.Capability{
+			CapWideTables,
+			CapUnsignedTypes,
+			CapScrollableCursors,
+			CapBigDateTime,
+		},
+	}
+)
+
+// ServerVersion is the version of the server a connection is logged in
+// to. The features in ServerTarget can be checked with Has.
+type ServerVersion struct {
+	capability.Version
+
+	// Major, Minor, SP and PL are the release, service pack and patch
+	// level of the server, e.g. 16, 0, 4 and 5 for ASE 16.0 SP04 PL05.
+	//
+	// The LoginAck only reports the release, SP and PL are zero unless
+	// queried with Channel.QueryServerVersion.
+	Major, Minor, SP, PL int
+	// Raw is the @@version of the server, empty unless queried with
+	// Channel.QueryServerVersion.
+	Raw string
+}
+
+// NewServerVersion returns a ServerVersion with the features of
+// ServerTarget set for the passed version.
+func NewServerVersion(major, minor, sp, pl int) (*ServerVersion, error) {
+	v, err := ServerTarget.Version(fmt.Sprintf("%d.%d.%d.%d", major, minor, sp, pl))
+	if err != nil {
+		return nil, fmt.Errorf("error setting capabilities of server version: %w", err)
+	}
+
+	return &ServerVersion{
+		Version: v,
+		Major:   major,
+		Minor:   minor,
+		SP:      sp,
+		PL:      pl,
+	}, nil
+}
+
+var (
+	serverVersionNumberRe = regexp.MustCompile(`^(\d+)\.(\d+)(?:\.(\d+))?`)
+	serverVersionSPRe     = regexp.MustCompile(`\bSP(\d+)\b`)
+	serverVersionPLRe     = regexp.MustCompile(`\b(?:PL|ESD\s*#)(\d+)\b`)
+)
+
+// ParseServerVersion parses the @@version of an ASE server, e.g.
+// "Adaptive Server Enterprise/16.0 SP04 PL05/EBF 30399 SMP/P/...".
+//
+// Service packs and patch levels are read from SP and PL markers.
+// Older releases report the service pack as third part of the version
+// number, e.g. 15.0.3, and the patch level as ESD#.
+func ParseServerVersion(s string) (*ServerVersion, error) {
+	parts := strings.Split(s, "/")
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("invalid server version %q, expected product and version separated by '/'", s)
+	}
+
+	match := serverVersionNumberRe.FindStringSubmatch(strings.TrimSpace(parts[1]))
+	if match == nil {
+		return nil, fmt.Errorf("invalid server version %q, expected version number after product", s)
+	}
+
+	numbers := make([]int, 4)
+	for i, m := range match[1:] {
+		if m == "" {
+			continue
+		}
+
+		n, err := strconv.Atoi(m)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing server version %q: %w", s, err)
+		}
+		numbers[i] = n
+	}
+
+	details := strings.Join(parts[1:], "/")
+	for i, re := range []*regexp.Regexp{serverVersionSPRe, serverVersionPLRe} {
+		if m := re.FindStringSubmatch(details); m != nil {
+			n, err := strconv.Atoi(m[1])
+			if err != nil {
+				return nil, fmt.Errorf("error parsing server version %q: %w", s, err)
+			}
+			numbers[2+i] = n
+		}
+	}
+
+	v, err := NewServerVersion(numbers[0], numbers[1], numbers[2], numbers[3])
+	if err != nil {
+		return nil, err
+	}
+
+	v.Raw = s
+	return v, nil
+}
+
+func (v ServerVersion) String() string {
+	if v.Raw != "" {
+		return v.Raw
+	}
+
+	return fmt.Sprintf("%d.%d SP%02d PL%02d", v.Major, v.Minor, v.SP, v.PL)
+}
+
+// ServerVersion returns the version of the server the connection is
+// logged in to, as reported in the LoginAck of the login on the main
+// channel or queried with Channel.QueryServerVersion.
+//
+// The program version of the LoginAck carries no service pack or patch
+// level, so unless the version was queried Has checks the features of
+// ServerTarget against the major and minor version only.
+//
+// The version is refreshed from the LoginAck when the session is
+// resumed after a failover or migration.
+//
+// Before the login a version without any features is returned.
+func (tds *Conn) ServerVersion() *ServerVersion {
+	tds.sessionLock.Lock()
+	defer tds.sessionLock.Unlock()
+
+	if tds.serverVersion == nil {
+		v, _ := NewServerVersion(0, 0, 0, 0)
+		return v
+	}
+
+	return tds.serverVersion
+}
+
+// setServerVersion sets the major and minor version of the server from
+// the LoginAck of the last login.
+func (tdsChan *Channel) setServerVersion() error {
+	ack := tdsChan.loginAck
+	if ack == nil || ack.ProgramVersion == nil {
+		return errors.New("server did not report its version")
+	}
+
+	pv := ack.ProgramVersion
+	v, err := NewServerVersion(int(pv.major), int(pv.minor), 0, 0)
+	if err != nil {
+		return err
+	}
+
+	tdsChan.tdsConn.sessionLock.Lock()
+	defer tdsChan.tdsConn.sessionLock.Unlock()
+
+	tdsChan.tdsConn.serverVersion = v
+	return nil
+}
+
+// QueryServerVersion queries the @@version of the server, which
+// contains details such as the service pack and patch level beyond the
+// version reported in the LoginAck, and sets it as the version of the
+// connection.
+//
+// As the session may be resumed on a different server after a failover
+// or migration, the query must be repeated afterwards, e.g. in
+// a FailoverHook or MigrationHook, to retain the details.
+func (tdsChan *Channel) QueryServerVersion(ctx context.Context) (*ServerVersion, error) {
+	// The query contains no data of the client and is not encrypted,
+	// as that would fail sessions without a symmetric key.
+	var raw string
+	err := tdsChan.execLanguage(ctx, "select @@version", func(pkg Package) error {
+		row, ok := pkg.(*RowPackage)
+		if !ok || len(row.DataFields) != 1 {
+			return nil
+		}
+
+		switch value := row.DataFields[0].Value().(type) {
+		case string:
+			raw = value
+		case []byte:
+			raw = string(value)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error querying server version: %w", err)
+	}
+
+	v, err := ParseServerVersion(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	tdsChan.tdsConn.sessionLock.Lock()
+	defer tdsChan.tdsConn.sessionLock.Unlock()
+
+	tdsChan.tdsConn.serverVersion = v
+	return v, nil
+}
@@ -0,0 +1,154 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tds_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/SAP/go-dblib/capability"
+	"github.com/SAP/go-dblib/tds"
+	"github.com/SAP/go-dblib/tds/tdstest"
+)
+
+func TestParseServerVersion(t *testing.T) {
+	cases := map[string]struct {
+		version              string
+		major, minor, sp, pl int
+		caps                 []*capability.Capability
+		err                  bool
+	}{
+		"16.0": {
+			version: "Adaptive Server Enterprise/16.0 SP04 PL05/EBF 30399 SMP/P/x86_64/SLES 12.4/ase160sp04pl05x/3716/64-bit/FBO/Wed Jan 18 15:35:02 2023",
+			major:   16, sp: 4, pl: 5,
+			caps: []*capability.Capability{tds.CapWideTables, tds.CapUnsignedTypes, tds.CapScrollableCursors, tds.CapBigDateTime},
+		},
+		"15.7": {
+			version: "Adaptive Server Enterprise/15.7/EBF 25127 SMP SP136 /P/x86_64/Enterprise Linux/ase157sp136x/4270/64-bit/FBO/Fri Aug 21 12:28:19 2015",
+			major:   15, minor: 7, sp: 136,
+			caps: []*capability.Capability{tds.CapWideTables, tds.CapUnsignedTypes, tds.CapScrollableCursors, tds.CapBigDateTime},
+		},
+		"15.0.3": {
+			version: "Adaptive Server Enterprise/15.0.3/EBF 17163 ESD#3/P/Sun_svr4/OS 5.10/ase1503/2726/64-bit/FBO/Fri Oct 16 04:27:48 2009",
+			major:   15, sp: 3, pl: 3,
+			caps: []*capability.Capability{tds.CapWideTables, tds.CapUnsignedTypes, tds.CapScrollableCursors},
+		},
+		"12.5.4": {
+			version: "Adaptive Server Enterprise/12.5.4/EBF 15432 ESD#8/P/Sun_svr4/OS 5.8/ase1254/2105/64-bit/FBO/Sat Mar 22 14:38:37 2008",
+			major:   12, minor: 5, sp: 4, pl: 8,
+			caps: []*capability.Capability{tds.CapWideTables},
+		},
+		"no version": {
+			version: "Adaptive Server Enterprise",
+			err:     true,
+		},
+		"invalid version": {
+			version: "Adaptive Server Enterprise/unknown/P",
+			err:     true,
+		},
+	}
+
+	for title, cas := range cases {
+		t.Run(title, func(t *testing.T) {
+			v, err := tds.ParseServerVersion(cas.version)
+			if cas.err {
+				if err == nil {
+					t.Fatalf("expected error parsing %q", cas.version)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("error parsing server version: %v", err)
+			}
+
+			if v.Major != cas.major || v.Minor != cas.minor || v.SP != cas.sp || v.PL != cas.pl {
+				t.Errorf("expected version %d.%d SP%d PL%d, received %d.%d SP%d PL%d",
+					cas.major, cas.minor, cas.sp, cas.pl, v.Major, v.Minor, v.SP, v.PL)
+			}
+
+			for _, cap := range tds.ServerTarget.Capabilities {
+				expected := false
+				for _, c := range cas.caps {
+					expected = expected || c == cap
+				}
+
+				if v.Has(cap) != expected {
+					t.Errorf("expected %s to be available: %t", cap, expected)
+				}
+			}
+		})
+	}
+}
+
+func TestConn_ServerVersion(t *testing.T) {
+	v1603 := "Adaptive Server Enterprise/16.0 SP03 PL07/EBF 27413 SMP/P/x86_64/SLES 11.1/ase160sp03pl07x/1234/64-bit/FBO/Mon Jul 10 01:55:35 2019"
+
+	cases := map[string]struct {
+		version              string
+		query                bool
+		major, minor, sp, pl int
+		raw                  string
+		err                  bool
+	}{
+		"LoginAck": {
+			version: v1603,
+			major:   16,
+		},
+		"@@version": {
+			version: v1603,
+			query:   true,
+			major:   16, sp: 3, pl: 7,
+			raw: v1603,
+		},
+		"@@version not available": {
+			query: true,
+			major: 16,
+			err:   true,
+		},
+	}
+
+	for title, cas := range cases {
+		t.Run(title, func(t *testing.T) {
+			server, err := tdstest.NewServer("user", "pass")
+			if err != nil {
+				t.Fatalf("error creating server: %v", err)
+			}
+			defer server.Close()
+
+			server.SetVersion(cas.version)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			conn, ch, err := server.Connect(ctx)
+			if err != nil {
+				t.Fatalf("error connecting: %v", err)
+			}
+			defer conn.Close()
+
+			if cas.query {
+				if _, err := ch.QueryServerVersion(ctx); cas.err != (err != nil) {
+					t.Fatalf("expected error: %t, received: %v", cas.err, err)
+				}
+			}
+
+			v := conn.ServerVersion()
+			if v.Major != cas.major || v.Minor != cas.minor || v.SP != cas.sp || v.PL != cas.pl {
+				t.Errorf("expected version %d.%d SP%d PL%d, received %d.%d SP%d PL%d",
+					cas.major, cas.minor, cas.sp, cas.pl, v.Major, v.Minor, v.SP, v.PL)
+			}
+
+			if v.Raw != cas.raw {
+				t.Errorf("expected raw version %q, received %q", cas.raw, v.Raw)
+			}
+
+			if !v.Has(tds.CapBigDateTime) {
+				t.Errorf("expected %s to be available", tds.CapBigDateTime)
+			}
+		})
+	}
+}
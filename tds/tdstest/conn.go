@@ -0,0 +1,852 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tdstest
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SAP/go-dblib/asetypes"
+	"github.com/SAP/go-dblib/tds"
+)
+
+const (
+	// packetSize is the packet size used by the server.
+	packetSize = 512
+	// packetReadTimeout is passed to tds.Packet.ReadFrom.
+	packetReadTimeout = time.Minute
+	// nonceLength is the length of the nonce sent to clients in the
+	// login negotiation.
+	nonceLength = 32
+	// programName is sent to clients in the LoginAck.
+	programName = "tdstest"
+	// version is returned for @@version unless set with
+	// Server.SetVersion.
+	version = "tdstest/16.0/P/tdstest"
+)
+
+var (
+	errLogout = errors.New("client logged out")
+
+	// programVersion is sent to clients in the LoginAck.
+	programVersion = []byte{16, 0, 0, 0}
+)
+
+// serverConn is a single client connection to a Server.
+type serverConn struct {
+	server *Server
+	conn   net.Conn
+	// writeLock serializes writing messages to conn, as event
+	// notifications are sent independently of responses.
+	writeLock *sync.Mutex
+
+	// queues stores the received packets of incomplete messages by
+	// channel.
+	queues map[uint16]*tds.PacketQueue
+	// channels stores the logical channels set up by the client.
+	// Packets on other channels than these and the main channel close
+	// the connection.
+	channels map[uint16]bool
+
+	login        *tds.LoginConfig
+	caps         *tds.CapabilityPackage
+	nonce        []byte
+	loggedIn     bool
+	symmetricKey []byte
+	// restricted is set for sessions whose password has expired,
+	// which only permit changing the password.
+	restricted bool
+
+	// statements maps the IDs of prepared dynamic SQL statements to
+	// their statement.
+	statements map[string]string
+	// bulkTable is the table of the last `insert bulk` command.
+	bulkTable string
+
+	// cursors maps the IDs of declared cursors to their state.
+	cursors      map[int32]*serverCursor
+	lastCursorID int32
+
+	// watches maps the names of watched events to the channel they
+	// were registered on.
+	watches     map[string]uint16
+	watchesLock *sync.Mutex
+}
+
+func newServerConn(server *Server, conn net.Conn) *serverConn {
+	return &serverConn{
+		server:      server,
+		conn:        conn,
+		writeLock:   &sync.Mutex{},
+		queues:      map[uint16]*tds.PacketQueue{},
+		channels:    map[uint16]bool{},
+		statements:  map[string]string{},
+		cursors:     map[int32]*serverCursor{},
+		watches:     map[string]uint16{},
+		watchesLock: &sync.Mutex{},
+	}
+}
+
+func (c *serverConn) serve(ctx context.Context) {
+	defer c.conn.Close()
+
+	for {
+		packet := &tds.Packet{}
+		if _, err := packet.ReadFrom(ctx, c.conn, packetReadTimeout); err != nil {
+			return
+		}
+
+		if err := c.handlePacket(packet); err != nil {
+			return
+		}
+	}
+}
+
+func (c *serverConn) handlePacket(packet *tds.Packet) error {
+	channel := packet.Header.Channel
+
+	switch packet.Header.MsgType {
+	case tds.TDS_BUF_SETUP:
+		c.channels[channel] = true
+		ack := tds.NewPacket(tds.PacketHeaderSize)
+		ack.Header.MsgType = tds.TDS_BUF_PROTACK
+		ack.Header.Channel = channel
+		return c.write(ack)
+	case tds.TDS_BUF_CLOSE:
+		delete(c.queues, channel)
+		delete(c.channels, channel)
+		return nil
+	case tds.TDS_BUF_ATTN:
+		// Requests are answered synchronously, hence only an
+		// incomplete request is discarded before acknowledging the
+		// attention.
+		delete(c.queues, channel)
+		c.server.addAttention()
+		return c.sendAttentionAck(channel)
+	}
+
+	if channel != 0 && !c.channels[channel] {
+		return fmt.Errorf("received packet on channel %d, which was not set up", channel)
+	}
+
+	queue, ok := c.queues[channel]
+	if !ok {
+		queue = tds.NewPacketQueue(func() int { return packetSize })
+		c.queues[channel] = queue
+	}
+
+	queue.AddPacket(packet)
+	if packet.Header.Status&tds.TDS_BUFSTAT_EOM != tds.TDS_BUFSTAT_EOM {
+		return nil
+	}
+	delete(c.queues, channel)
+
+	if packet.Header.MsgType == tds.TDS_BUF_LOGIN {
+		return c.handleLogin(channel, queue)
+	}
+
+	// Bulk rows have no tokens and are read with the columns of the
+	// table of the preceding `insert bulk` command.
+	if packet.Header.MsgType == tds.TDS_BUF_BULK && c.loggedIn {
+		return c.send(channel, c.respondBulk(queue)...)
+	}
+
+	request, err := readPackages(queue)
+	if err != nil {
+		return fmt.Errorf("error reading request: %w", err)
+	}
+
+	if !c.loggedIn {
+		return c.handleLoginNegotiation(channel, request)
+	}
+
+	if c.restricted {
+		if response, ok := c.respondRestricted(request); ok {
+			return c.send(channel, response...)
+		}
+	}
+
+	if packet.Header.MsgType == tds.TDS_BUF_MIGRATE {
+		return c.handleMigration(channel, request)
+	}
+
+	if response, ok := c.respondEventRegistration(channel, request); ok {
+		return c.send(channel, response...)
+	}
+
+	if isCursorRequest(request) {
+		return c.handleCursor(channel, request)
+	}
+
+	for _, pkg := range request {
+		if _, ok := pkg.(*tds.LogoutPackage); ok {
+			if err := c.send(channel, &tds.DonePackage{Status: tds.TDS_DONE_FINAL}); err != nil {
+				return err
+			}
+			return errLogout
+		}
+	}
+
+	return c.send(channel, c.respond(request)...)
+}
+
+// handleLogin handles the login payload and capabilities sent by the
+// client and either completes the login or starts the negotiation of
+// the password encryption.
+func (c *serverConn) handleLogin(channel uint16, queue *tds.PacketQueue) error {
+	login, err := tds.ParseLoginConfig(queue)
+	if err != nil {
+		return err
+	}
+	c.login = login
+
+	request, err := readPackages(queue)
+	if err != nil {
+		return fmt.Errorf("error reading login request: %w", err)
+	}
+
+	for _, pkg := range request {
+		if caps, ok := pkg.(*tds.CapabilityPackage); ok {
+			c.caps = caps
+		}
+	}
+
+	if c.caps == nil {
+		return errors.New("login request did not contain capabilities")
+	}
+
+	if login.Encrypt == 0 {
+		if !c.server.authenticate(login.DSN.Username, login.DSN.Password) {
+			return c.sendLoginFailed(channel)
+		}
+
+		return c.sendLoginSucceeded(channel)
+	}
+
+	if login.Encrypt == tds.TDS_MSG_SEC_OPAQUE {
+		return c.handleSecurityToken(channel, request)
+	}
+
+	ack, err := c.loginAck(tds.TDS_LOG_NEGOTIATE)
+	if err != nil {
+		return err
+	}
+
+	c.nonce = make([]byte, nonceLength)
+	if _, err := rand.Read(c.nonce); err != nil {
+		return fmt.Errorf("error generating nonce: %w", err)
+	}
+
+	pubKey := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PUBLIC KEY",
+		Bytes: x509.MarshalPKCS1PublicKey(&c.server.privateKey.PublicKey),
+	})
+
+	c.server.lock.Lock()
+	encrypt := c.server.loginEncryption
+	c.server.lock.Unlock()
+
+	// The asymmetric encryption type RSA, the public key and the
+	// nonce, which TDS_MSG_SEC_ENCRYPT2 does not use.
+	values := []interface{}{int32(1), pubKey, c.nonce}
+	if encrypt == tds.TDS_MSG_SEC_ENCRYPT2 {
+		values = values[:2]
+	}
+
+	fmts := make([]tds.FieldFmt, len(values))
+	data := make([]tds.FieldData, len(values))
+	for i, dataType := range []asetypes.DataType{asetypes.INT4, asetypes.LONGBINARY, asetypes.LONGBINARY}[:len(values)] {
+		fmts[i], data[i], err = tds.LookupFieldFmtData(dataType)
+		if err != nil {
+			return fmt.Errorf("error looking up field for %s: %w", dataType, err)
+		}
+		data[i].SetValue(values[i])
+	}
+
+	return c.send(channel,
+		ack,
+		tds.NewMsgPackage(tds.TDS_MSG_HASARGS, encrypt),
+		tds.NewParamFmtPackage(false, fmts...),
+		tds.NewParamsPackage(data...),
+		&tds.DonePackage{Status: tds.TDS_DONE_FINAL},
+	)
+}
+
+// handleLoginNegotiation handles the encrypted password, remote server
+// passwords and symmetric key sent by the client.
+func (c *serverConn) handleLoginNegotiation(channel uint16, request []tds.Package) error {
+	if c.login != nil && c.login.Encrypt == tds.TDS_MSG_SEC_OPAQUE {
+		return c.handleSecurityToken(channel, request)
+	}
+
+	if c.login == nil || c.nonce == nil {
+		return errors.New("received request before login")
+	}
+
+	var password string
+	var msgId tds.TDSMsgId
+	for _, pkg := range request {
+		switch typed := pkg.(type) {
+		case *tds.MsgPackage:
+			msgId = typed.MsgId
+		case *tds.ParamsPackage:
+			if len(typed.DataFields) == 0 {
+				return fmt.Errorf("received empty params for %s", msgId)
+			}
+
+			switch msgId {
+			case tds.TDS_MSG_SEC_LOGPWD3:
+				decrypted, err := c.decrypt(typed.DataFields[0])
+				if err != nil {
+					return fmt.Errorf("error decrypting password: %w", err)
+				}
+				password = string(decrypted)
+			case tds.TDS_MSG_SEC_LOGPWD2:
+				decrypted, err := c.decryptPKCS1v15(typed.DataFields[0])
+				if err != nil {
+					return fmt.Errorf("error decrypting password: %w", err)
+				}
+				password = string(decrypted)
+			case tds.TDS_MSG_SEC_SYMKEY:
+				decrypted, err := c.decrypt(typed.DataFields[0])
+				if err != nil {
+					return fmt.Errorf("error decrypting symmetric key: %w", err)
+				}
+				c.symmetricKey = decrypted
+			}
+		}
+	}
+
+	if !c.server.authenticate(c.login.DSN.Username, password) {
+		return c.sendLoginFailed(channel)
+	}
+
+	return c.sendLoginSucceeded(channel, c.caps)
+}
+
+// handleSecurityToken passes the security token of a login
+// authenticated by a tds.Authenticator to the AuthenticationFunc and
+// either continues the negotiation or completes the login.
+func (c *serverConn) handleSecurityToken(channel uint16, request []tds.Package) error {
+	var token []byte
+	var isOpaque bool
+	for _, pkg := range request {
+		switch typed := pkg.(type) {
+		case *tds.MsgPackage:
+			isOpaque = typed.MsgId == tds.TDS_MSG_SEC_OPAQUE
+		case *tds.ParamsPackage:
+			if !isOpaque {
+				continue
+			}
+
+			var err error
+			if token, err = tds.SecurityToken(typed); err != nil {
+				return err
+			}
+		}
+	}
+
+	if token == nil {
+		return errors.New("login request did not contain a security token")
+	}
+
+	reply, done, err := c.server.authenticateToken(c.login.DSN.Username, token)
+	if err != nil {
+		return c.sendLoginFailed(channel)
+	}
+
+	var replyPkgs []tds.Package
+	if reply != nil {
+		if replyPkgs, err = securityToken(reply); err != nil {
+			return err
+		}
+	}
+
+	if done {
+		return c.sendLoginSucceeded(channel, append(replyPkgs, c.caps)...)
+	}
+
+	ack, err := c.loginAck(tds.TDS_LOG_NEGOTIATE)
+	if err != nil {
+		return err
+	}
+
+	response := append([]tds.Package{ack}, replyPkgs...)
+	response = append(response, &tds.DonePackage{Status: tds.TDS_DONE_FINAL})
+	return c.send(channel, response...)
+}
+
+// securityToken returns the packages of a TDS_MSG_SEC_OPAQUE message
+// with token.
+func securityToken(token []byte) ([]tds.Package, error) {
+	typeFmt, typeData, err := tds.LookupFieldFmtData(asetypes.INT4)
+	if err != nil {
+		return nil, fmt.Errorf("error looking up field for %s: %w", asetypes.INT4, err)
+	}
+	typeData.SetValue(int32(tds.TDS_SEC_SECSESS))
+
+	tokenFmt, tokenData, err := tds.LookupFieldFmtData(asetypes.LONGBINARY)
+	if err != nil {
+		return nil, fmt.Errorf("error looking up field for %s: %w", asetypes.LONGBINARY, err)
+	}
+	tokenData.SetValue(token)
+
+	return []tds.Package{
+		tds.NewMsgPackage(tds.TDS_MSG_HASARGS, tds.TDS_MSG_SEC_OPAQUE),
+		tds.NewParamFmtPackage(false, typeFmt, tokenFmt),
+		tds.NewParamsPackage(typeData, tokenData),
+	}, nil
+}
+
+// sendLoginSucceeded completes the login and grants the HA session
+// registered with Server.HandleHAFailover.
+func (c *serverConn) sendLoginSucceeded(channel uint16, pkgs ...tds.Package) error {
+	haSession, ok, err := c.server.haSession(c.login)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return c.sendLoginFailed(channel)
+	}
+
+	ack, err := c.loginAck(tds.TDS_LOG_SUCCEED)
+	if err != nil {
+		return err
+	}
+
+	response := append([]tds.Package{ack}, pkgs...)
+	if c.login.DSN.Username != "" && c.server.isPasswordExpired() {
+		c.restricted = true
+		response = append(response, &tds.EEDPackage{
+			MsgNumber: 4022,
+			State:     1,
+			Class:     10,
+			Status:    tds.TDS_NO_EED,
+			Msg:       "The password has expired, but you are still allowed to log in. You must change your password before you can continue.",
+		})
+	}
+	response = append(response, haSession...)
+	if c.login.HALogin&tds.TDS_HA_LOG_MIGRATE == tds.TDS_HA_LOG_MIGRATE {
+		response = append(response, tds.NewMsgPackage(tds.TDS_MSG_HASNOARGS, tds.TDS_MSG_MIG_RESUME))
+	}
+	response = append(response, &tds.DonePackage{Status: tds.TDS_DONE_FINAL})
+
+	c.loggedIn = true
+	return c.send(channel, response...)
+}
+
+// decrypt decrypts a value encrypted with the public key of the server
+// and strips the nonce.
+func (c *serverConn) decrypt(field tds.FieldData) ([]byte, error) {
+	encrypted, ok := field.Value().([]byte)
+	if !ok {
+		return nil, fmt.Errorf("expected []byte, received %T", field.Value())
+	}
+
+	decrypted, err := rsa.DecryptOAEP(sha1.New(), rand.Reader, c.server.privateKey, encrypted, []byte{})
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.HasPrefix(decrypted, c.nonce) {
+		return nil, errors.New("decrypted value is not prefixed with nonce")
+	}
+
+	return decrypted[len(c.nonce):], nil
+}
+
+// decryptPKCS1v15 decrypts a value encrypted with the public key of the
+// server for TDS_MSG_SEC_ENCRYPT2.
+func (c *serverConn) decryptPKCS1v15(field tds.FieldData) ([]byte, error) {
+	encrypted, ok := field.Value().([]byte)
+	if !ok {
+		return nil, fmt.Errorf("expected []byte, received %T", field.Value())
+	}
+
+	return rsa.DecryptPKCS1v15(rand.Reader, c.server.privateKey, encrypted)
+}
+
+func (c *serverConn) sendLoginFailed(channel uint16) error {
+	ack, err := c.loginAck(tds.TDS_LOG_FAIL)
+	if err != nil {
+		return err
+	}
+
+	return c.send(channel,
+		ack,
+		&tds.EEDPackage{
+			MsgNumber: 4002,
+			State:     1,
+			Class:     14,
+			Status:    tds.TDS_NO_EED,
+			Msg:       "Login failed.",
+		},
+		&tds.DonePackage{Status: tds.TDS_DONE_ERROR},
+	)
+}
+
+// respondRestricted responds to requests in sessions whose password has
+// expired. Only calls of sp_password with the old and new password are
+// accepted.
+//
+// Logouts are not handled.
+func (c *serverConn) respondRestricted(request []tds.Package) ([]tds.Package, bool) {
+	var rpc *tds.RPCPackage
+	var params *tds.ParamsPackage
+	for _, pkg := range request {
+		switch typed := pkg.(type) {
+		case *tds.LogoutPackage:
+			return nil, false
+		case *tds.RPCPackage:
+			rpc = typed
+		case *tds.ParamsPackage:
+			params = typed
+		}
+	}
+
+	if rpc == nil || rpc.Name != "sp_password" || params == nil || len(params.DataFields) != 2 {
+		return ErrorResponse(4022, "tdstest: the password has expired, change it with sp_password"), true
+	}
+
+	oldPassword, _ := params.DataFields[0].Value().(string)
+	newPassword, _ := params.DataFields[1].Value().(string)
+	if !c.server.changePassword(oldPassword, newPassword) {
+		return ErrorResponse(10316, "tdstest: the old password is incorrect"), true
+	}
+
+	c.restricted = false
+	return []tds.Package{
+		&tds.ReturnStatusPackage{ReturnValue: 0},
+		&tds.DonePackage{Status: tds.TDS_DONE_FINAL},
+	}, true
+}
+
+// respond returns the response to a request.
+func (c *serverConn) respond(request []tds.Package) []tds.Package {
+	for _, pkg := range request {
+		switch typed := pkg.(type) {
+		case *tds.LanguagePackage:
+			if response, ok := c.server.languageResponse(typed.Cmd); ok {
+				return response
+			}
+			if response, ok := c.respondBulkLanguage(typed.Cmd); ok {
+				return response
+			}
+			if response, ok := c.respondVersion(typed.Cmd); ok {
+				return response
+			}
+		case *tds.DynamicPackage:
+			if response, ok := c.respondDynamic(typed); ok {
+				return response
+			}
+		case *tds.RPCPackage:
+			if response, ok := c.server.rpcResponse(typed.Name); ok {
+				return response
+			}
+		}
+	}
+
+	if response, ok := c.server.handle(request); ok {
+		return response
+	}
+
+	return ErrorResponse(2812, fmt.Sprintf("tdstest: no response scripted for request %v", request))
+}
+
+func (c *serverConn) respondDynamic(pkg *tds.DynamicPackage) ([]tds.Package, bool) {
+	ack := tds.NewDynamicPackage(true)
+	ack.Type = tds.TDS_DYN_ACK
+	ack.ID = pkg.ID
+
+	switch pkg.Type {
+	case tds.TDS_DYN_PREPARE:
+		if _, ok := c.server.dynamicResponse(pkg.Stmt); !ok {
+			return nil, false
+		}
+		c.statements[pkg.ID] = pkg.Stmt
+		return []tds.Package{ack, &tds.DonePackage{Status: tds.TDS_DONE_FINAL}}, true
+	case tds.TDS_DYN_EXEC:
+		stmt, ok := c.statements[pkg.ID]
+		if !ok {
+			return nil, false
+		}
+		return c.server.dynamicResponse(stmt)
+	case tds.TDS_DYN_EXEC_IMMED:
+		return c.server.dynamicResponse(pkg.Stmt)
+	case tds.TDS_DYN_DEALLOC:
+		if _, ok := c.statements[pkg.ID]; !ok {
+			return nil, false
+		}
+		delete(c.statements, pkg.ID)
+		return []tds.Package{ack, &tds.DonePackage{Status: tds.TDS_DONE_FINAL}}, true
+	}
+
+	return nil, false
+}
+
+// respondVersion responds to the query of @@version issued by
+// tds.Channel.Login.
+func (c *serverConn) respondVersion(cmd string) ([]tds.Package, bool) {
+	if cmd != "select @@version" {
+		return nil, false
+	}
+
+	version, ok := c.server.getVersion()
+	if !ok {
+		return ErrorResponse(2812, "tdstest: @@version is not available"), true
+	}
+
+	response, err := Result([]Column{{DataType: asetypes.VARCHAR}}, []interface{}{version})
+	if err != nil {
+		return ErrorResponse(2812, err.Error()), true
+	}
+
+	return response, true
+}
+
+// respondBulkLanguage responds to the language commands issued by
+// tds.BulkCopy for tables registered with HandleBulk.
+func (c *serverConn) respondBulkLanguage(cmd string) ([]tds.Package, bool) {
+	// tds.BulkCopy encloses the parts of table names in brackets.
+	unquote := strings.NewReplacer("[", "", "]", "").Replace
+
+	if strings.HasPrefix(cmd, "select * from ") && strings.HasSuffix(cmd, " where 1 = 2") {
+		table := unquote(strings.TrimSuffix(strings.TrimPrefix(cmd, "select * from "), " where 1 = 2"))
+		columns, ok := c.server.bulkColumns(table)
+		if !ok {
+			return nil, false
+		}
+
+		response, err := Result(columns)
+		if err != nil {
+			return ErrorResponse(7, err.Error()), true
+		}
+		return response, true
+	}
+
+	if strings.HasPrefix(cmd, "insert bulk ") {
+		table := unquote(strings.TrimPrefix(cmd, "insert bulk "))
+		if _, ok := c.server.bulkColumns(table); !ok {
+			return nil, false
+		}
+
+		c.bulkTable = table
+		return []tds.Package{&tds.DonePackage{Status: tds.TDS_DONE_FINAL}}, true
+	}
+
+	return nil, false
+}
+
+// respondBulk stores the rows of a bulk message in the table of the
+// preceding `insert bulk` command.
+func (c *serverConn) respondBulk(queue *tds.PacketQueue) []tds.Package {
+	table := c.bulkTable
+	c.bulkTable = ""
+	if table == "" {
+		return ErrorResponse(4801, "tdstest: received bulk rows without insert bulk command")
+	}
+
+	columns, ok := c.server.bulkColumns(table)
+	if !ok {
+		return ErrorResponse(208, fmt.Sprintf("tdstest: table %s not found", table))
+	}
+
+	fmts, err := columnFmts(columns)
+	if err != nil {
+		return ErrorResponse(7, err.Error())
+	}
+
+	rows := [][]interface{}{}
+	for !queue.AllPacketsConsumed() {
+		data := make([]tds.FieldData, len(fmts))
+		for i, fieldFmt := range fmts {
+			data[i], err = tds.LookupFieldData(fieldFmt)
+			if err != nil {
+				return ErrorResponse(7, err.Error())
+			}
+		}
+
+		row := tds.NewBulkRowPackage(data...)
+		if err := row.ReadFrom(queue); err != nil {
+			return ErrorResponse(4801, fmt.Sprintf("tdstest: error reading bulk row %d: %v", len(rows), err))
+		}
+
+		values := make([]interface{}, len(row.DataFields))
+		for i, field := range row.DataFields {
+			values[i] = field.Value()
+		}
+		rows = append(rows, values)
+	}
+
+	if !c.server.addBulkRows(table, rows) {
+		return ErrorResponse(208, fmt.Sprintf("tdstest: table %s not found", table))
+	}
+
+	return []tds.Package{&tds.DonePackage{Status: tds.TDS_DONE_COUNT, Count: int32(len(rows))}}
+}
+
+// send writes the passed packages as a single message to the client.
+func (c *serverConn) send(channel uint16, pkgs ...tds.Package) error {
+	return c.sendAfter(channel, nil, pkgs...)
+}
+
+// sendAfter writes the passed packages as a single message to the
+// client. The packages are encoded as if they followed lastPkg.
+func (c *serverConn) sendAfter(channel uint16, lastPkg tds.Package, pkgs ...tds.Package) error {
+	packets, err := c.server.encode(lastPkg, pkgs)
+	if err != nil {
+		return err
+	}
+
+	for i, packet := range packets {
+		packet.Header.MsgType = tds.TDS_BUF_RESPONSE
+		packet.Header.Channel = channel
+		packet.Header.PacketNr = uint8(i)
+		if i == len(packets)-1 {
+			packet.Header.Status |= tds.TDS_BUFSTAT_EOM
+		}
+	}
+
+	return c.write(packets...)
+}
+
+// sendAttentionAck acknowledges an attention sent by the client.
+func (c *serverConn) sendAttentionAck(channel uint16) error {
+	packets, err := c.server.encode(nil, []tds.Package{&tds.DonePackage{Status: tds.TDS_DONE_ATTN}})
+	if err != nil {
+		return err
+	}
+
+	for i, packet := range packets {
+		packet.Header.MsgType = tds.TDS_BUF_RESPONSE
+		packet.Header.Status |= tds.TDS_BUFSTAT_ATTNACK
+		packet.Header.Channel = channel
+		packet.Header.PacketNr = uint8(i)
+		if i == len(packets)-1 {
+			packet.Header.Status |= tds.TDS_BUFSTAT_EOM
+		}
+	}
+
+	return c.write(packets...)
+}
+
+// write writes the passed packets to the client without interleaving
+// them with other messages.
+func (c *serverConn) write(packets ...*tds.Packet) error {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+
+	for _, packet := range packets {
+		if _, err := packet.WriteTo(c.conn); err != nil {
+			return fmt.Errorf("error writing packet: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// encode writes the passed packages into packets.
+//
+// Scripted responses may be sent on multiple connections at the same
+// time, hence encoding is serialized.
+func (server *Server) encode(lastPkg tds.Package, pkgs []tds.Package) ([]*tds.Packet, error) {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	queue := tds.NewPacketQueue(func() int { return packetSize })
+
+	for _, pkg := range pkgs {
+		if acceptor, ok := pkg.(tds.LastPkgAcceptor); ok {
+			if err := acceptor.LastPkg(lastPkg); err != nil {
+				return nil, fmt.Errorf("error in LastPkg of %s: %w", pkg, err)
+			}
+		}
+
+		if err := pkg.WriteTo(queue); err != nil {
+			return nil, fmt.Errorf("error writing package %s: %w", pkg, err)
+		}
+		lastPkg = pkg
+	}
+
+	return queue.Packets(), nil
+}
+
+// readPackages reads all packages from queue.
+func readPackages(queue *tds.PacketQueue) ([]tds.Package, error) {
+	var pkgs []tds.Package
+	var lastPkg tds.Package
+
+	for !queue.AllPacketsConsumed() {
+		token, err := queue.Byte()
+		if err != nil {
+			return nil, fmt.Errorf("error reading token: %w", err)
+		}
+
+		pkg, err := tds.LookupPackage(tds.Token(token))
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := pkg.(*tds.TokenlessPackage); ok {
+			return nil, fmt.Errorf("unhandled token %s", tds.Token(token))
+		}
+
+		if acceptor, ok := pkg.(tds.LastPkgAcceptor); ok {
+			if err := acceptor.LastPkg(lastPkg); err != nil {
+				return nil, fmt.Errorf("error in LastPkg of %T: %w", pkg, err)
+			}
+		}
+
+		if err := pkg.ReadFrom(queue); err != nil {
+			return nil, fmt.Errorf("error reading package %T: %w", pkg, err)
+		}
+
+		pkgs = append(pkgs, pkg)
+		lastPkg = pkg
+	}
+
+	return pkgs, nil
+}
+
+// loginAck returns a LoginAck with the major and minor version of the
+// @@version set with Server.SetVersion, if it can be parsed. As with
+// ASE the service pack and patch level are not reported.
+func (c *serverConn) loginAck(status tds.LoginAckStatus) (*tds.LoginAckPackage, error) {
+	tdsVersion, err := tds.NewVersion([]byte{5, 0, 0, 0})
+	if err != nil {
+		return nil, err
+	}
+
+	pv := programVersion
+	if version, ok := c.server.getVersion(); ok {
+		if v, err := tds.ParseServerVersion(version); err == nil {
+			pv = []byte{byte(v.Major), byte(v.Minor), 0, 0}
+		}
+	}
+
+	programVersion, err := tds.NewVersion(pv)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tds.LoginAckPackage{
+		Status:         status,
+		Version:        tdsVersion,
+		ProgramName:    programName,
+		ProgramVersion: programVersion,
+	}, nil
+}
@@ -0,0 +1,244 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tdstest
+
+import (
+	"fmt"
+
+	"github.com/SAP/go-dblib/tds"
+)
+
+// cursorResult is a result set registered with HandleCursor.
+type cursorResult struct {
+	columns []Column
+	rows    [][]interface{}
+}
+
+// serverCursor is a cursor declared by a client.
+type serverCursor struct {
+	name   string
+	result *cursorResult
+	status tds.CursorIStatus
+
+	rowFmt *tds.RowFmtPackage
+	rows   [][]interface{}
+	// pos is the index of the first row of the last fetch and fetched
+	// is the amount of rows of the last fetch.
+	pos, fetched int
+	rowCount     int
+}
+
+func isCursorRequest(request []tds.Package) bool {
+	if len(request) == 0 {
+		return false
+	}
+
+	switch request[0].(type) {
+	case *tds.CurDeclarePackage, *tds.CurInfoPackage, *tds.CurOpenPackage,
+		*tds.CurFetchPackage, *tds.CurUpdatePackage, *tds.CurDeletePackage,
+		*tds.CurClosePackage:
+		return true
+	}
+
+	return false
+}
+
+// handleCursor responds to cursor requests on the result sets
+// registered with HandleCursor.
+func (c *serverConn) handleCursor(channel uint16, request []tds.Package) error {
+	done := &tds.DonePackage{Status: tds.TDS_DONE_FINAL}
+
+	if declare, ok := request[0].(*tds.CurDeclarePackage); ok {
+		result, ok := c.server.cursorResult(declare.Stmt)
+		if !ok {
+			return c.send(channel, ErrorResponse(2812, fmt.Sprintf("tdstest: no cursor scripted for statement %q", declare.Stmt))...)
+		}
+
+		c.lastCursorID++
+		c.cursors[c.lastCursorID] = &serverCursor{
+			name:     declare.Name,
+			result:   result,
+			status:   tds.TDS_CUR_ISTAT_DECLARED,
+			rowCount: 1,
+		}
+
+		return c.send(channel, c.curInfo(c.lastCursorID), done)
+	}
+
+	id, cursor, ok := c.lookupCursor(request[0])
+	if !ok {
+		return c.send(channel, ErrorResponse(16916, "tdstest: cursor does not exist")...)
+	}
+
+	switch typed := request[0].(type) {
+	case *tds.CurInfoPackage:
+		if typed.Command == tds.TDS_CUR_CMD_SETCURROWS {
+			cursor.rowCount = int(typed.RowCount)
+		}
+		return c.send(channel, c.curInfo(id), done)
+	case *tds.CurOpenPackage:
+		rowFmt, err := Result(cursor.result.columns)
+		if err != nil {
+			return c.send(channel, ErrorResponse(7, err.Error())...)
+		}
+
+		cursor.rowFmt = rowFmt[0].(*tds.RowFmtPackage)
+		cursor.rows = make([][]interface{}, len(cursor.result.rows))
+		copy(cursor.rows, cursor.result.rows)
+		cursor.pos, cursor.fetched = 0, 0
+		cursor.status = tds.TDS_CUR_ISTAT_OPEN
+
+		return c.send(channel, c.curInfo(id), cursor.rowFmt, done)
+	case *tds.CurFetchPackage:
+		if cursor.rowFmt == nil {
+			return c.send(channel, ErrorResponse(557, "tdstest: cursor is not open")...)
+		}
+
+		rows, err := cursor.fetch(typed.Type, int(typed.RowNumber))
+		if err != nil {
+			return c.send(channel, ErrorResponse(7, err.Error())...)
+		}
+
+		return c.sendAfter(channel, cursor.rowFmt, append(rows, done)...)
+	case *tds.CurUpdatePackage:
+		if cursor.current() < 0 {
+			return c.send(channel, ErrorResponse(558, "tdstest: cursor has no current row")...)
+		}
+		return c.send(channel, &tds.DonePackage{Status: tds.TDS_DONE_COUNT, Count: 1})
+	case *tds.CurDeletePackage:
+		current := cursor.current()
+		if current < 0 {
+			return c.send(channel, ErrorResponse(558, "tdstest: cursor has no current row")...)
+		}
+
+		cursor.rows = append(cursor.rows[:current], cursor.rows[current+1:]...)
+		cursor.fetched--
+		return c.send(channel, &tds.DonePackage{Status: tds.TDS_DONE_COUNT, Count: 1})
+	case *tds.CurClosePackage:
+		cursor.rowFmt = nil
+		cursor.status = tds.TDS_CUR_ISTAT_CLOSED
+		if typed.Options&tds.TDS_CUR_COPT_DEALLOC == tds.TDS_CUR_COPT_DEALLOC {
+			cursor.status |= tds.TDS_CUR_ISTAT_DEALLOC
+		}
+
+		response := []tds.Package{c.curInfo(id), done}
+		if cursor.status&tds.TDS_CUR_ISTAT_DEALLOC == tds.TDS_CUR_ISTAT_DEALLOC {
+			delete(c.cursors, id)
+		}
+		return c.send(channel, response...)
+	}
+
+	return c.send(channel, ErrorResponse(16916, "tdstest: unhandled cursor request")...)
+}
+
+// lookupCursor returns the cursor addressed by the passed package
+// either by ID or by name.
+func (c *serverConn) lookupCursor(pkg tds.Package) (int32, *serverCursor, bool) {
+	var id int32
+	var name string
+
+	switch typed := pkg.(type) {
+	case *tds.CurInfoPackage:
+		id, name = typed.CursorID, typed.Name
+	case *tds.CurOpenPackage:
+		id, name = typed.CursorID, typed.Name
+	case *tds.CurFetchPackage:
+		id, name = typed.CursorID, typed.Name
+	case *tds.CurUpdatePackage:
+		id, name = typed.CursorID, typed.Name
+	case *tds.CurDeletePackage:
+		id, name = typed.CursorID, typed.Name
+	case *tds.CurClosePackage:
+		id, name = typed.CursorID, typed.Name
+	}
+
+	if id != 0 {
+		cursor, ok := c.cursors[id]
+		return id, cursor, ok
+	}
+
+	for id, cursor := range c.cursors {
+		if cursor.name == name {
+			return id, cursor, true
+		}
+	}
+
+	return 0, nil, false
+}
+
+func (c *serverConn) curInfo(id int32) *tds.CurInfoPackage {
+	cursor := c.cursors[id]
+
+	pkg := tds.NewCurInfoPackage(c.caps.HasRequestCapability(tds.TDS_REQ_CURINFO3))
+	pkg.CursorID = id
+	pkg.Command = tds.TDS_CUR_CMD_INFORM
+	pkg.Status = cursor.status | tds.TDS_CUR_ISTAT_ROWCNT
+	pkg.RowCount = int32(cursor.rowCount)
+	pkg.TotalRows = int32(len(cursor.rows))
+	return pkg
+}
+
+// fetch moves the cursor and returns the fetched rows.
+func (cursor *serverCursor) fetch(fetchType tds.CursorFetchType, rowNumber int) ([]tds.Package, error) {
+	var start int
+	switch fetchType {
+	case tds.TDS_CUR_NEXT:
+		start = cursor.pos + cursor.fetched
+	case tds.TDS_CUR_PREV:
+		start = cursor.pos - cursor.rowCount
+	case tds.TDS_CUR_FIRST:
+		start = 0
+	case tds.TDS_CUR_LAST:
+		start = len(cursor.rows) - cursor.rowCount
+	case tds.TDS_CUR_ABS:
+		if rowNumber < 0 {
+			start = len(cursor.rows) + rowNumber
+		} else {
+			start = rowNumber - 1
+		}
+	case tds.TDS_CUR_REL:
+		start = cursor.pos + rowNumber
+	default:
+		return nil, fmt.Errorf("tdstest: unknown fetch type %s", fetchType)
+	}
+
+	end := start + cursor.rowCount
+	if start < 0 {
+		start = 0
+	}
+	if end > len(cursor.rows) {
+		end = len(cursor.rows)
+	}
+	if start > end {
+		start = end
+	}
+
+	cursor.pos, cursor.fetched = start, end-start
+
+	pkgs := make([]tds.Package, 0, end-start)
+	for _, row := range cursor.rows[start:end] {
+		data := make([]tds.FieldData, len(row))
+		for i, value := range row {
+			fieldData, err := tds.LookupFieldData(cursor.rowFmt.Fmts[i])
+			if err != nil {
+				return nil, fmt.Errorf("tdstest: error looking up data for column %d: %w", i, err)
+			}
+			fieldData.SetValue(value)
+			data[i] = fieldData
+		}
+		pkgs = append(pkgs, tds.NewRowPackage(data...))
+	}
+
+	return pkgs, nil
+}
+
+// current returns the index of the current row - the last fetched row
+// - or -1 if there is no current row.
+func (cursor serverCursor) current() int {
+	if cursor.rowFmt == nil || cursor.fetched == 0 {
+		return -1
+	}
+	return cursor.pos + cursor.fetched - 1
+}
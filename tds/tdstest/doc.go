@@ -0,0 +1,41 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+/*
+Package tdstest provides an in-process TDS 5.0 server with scripted
+responses to test clients built on the tds package without a running
+ASE.
+
+The server answers the login and capability negotiation expected by
+tds.Channel.Login (with and without TDS_MSG_SEC_ENCRYPT4) and responds
+to requests with the packages registered for them:
+
+	server, err := tdstest.NewServer("user", "pass")
+	if err != nil {
+		return err
+	}
+	defer server.Close()
+
+	response, err := tdstest.Result(
+		[]tdstest.Column{{Name: "a", DataType: asetypes.INT4}},
+		[]interface{}{int32(1)},
+	)
+	if err != nil {
+		return err
+	}
+	server.HandleLanguage("select 1 as a", response...)
+
+	info, err := server.Info()
+	if err != nil {
+		return err
+	}
+
+	conn, err := tds.NewConn(ctx, info)
+	...
+
+Requests without a scripted response are passed to the functions
+registered with HandleFunc. If no function handles the request the
+server responds with an error.
+*/
+package tdstest
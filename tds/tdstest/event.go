@@ -0,0 +1,126 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tdstest
+
+import (
+	"fmt"
+
+	"github.com/SAP/go-dblib/tds"
+)
+
+// Notify sends a notification of the event name with the passed
+// parameters to all clients that registered for the event using
+// sp_regwatch.
+//
+// Notify returns the number of notified clients.
+func (server *Server) Notify(name string, params ...tds.FieldData) (int, error) {
+	server.lock.Lock()
+	conns := make([]*serverConn, 0, len(server.conns))
+	for conn := range server.conns {
+		conns = append(conns, conn)
+	}
+	server.lock.Unlock()
+
+	fmts := make([]tds.FieldFmt, len(params))
+	for i, param := range params {
+		fmts[i] = param.Format()
+	}
+
+	pkgs := []tds.Package{&tds.EventNoticePackage{Name: name}}
+	if len(params) > 0 {
+		pkgs = append(pkgs, tds.NewParamFmtPackage(true, fmts...), tds.NewParamsPackage(params...))
+	}
+	pkgs = append(pkgs, &tds.DonePackage{Status: tds.TDS_DONE_EVENT})
+
+	notified := 0
+	for _, conn := range conns {
+		conn.watchesLock.Lock()
+		channel, ok := conn.watches[name]
+		conn.watchesLock.Unlock()
+		if !ok {
+			continue
+		}
+
+		if err := conn.sendEvent(channel, pkgs...); err != nil {
+			return notified, fmt.Errorf("tdstest: error sending notification: %w", err)
+		}
+		notified++
+	}
+
+	return notified, nil
+}
+
+// EventRegistrations returns the number of calls to sp_regwatch the
+// server received.
+func (server *Server) EventRegistrations() int {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	return server.registrations
+}
+
+// respondEventRegistration handles calls to sp_regwatch and
+// sp_regnowatch.
+func (c *serverConn) respondEventRegistration(channel uint16, request []tds.Package) ([]tds.Package, bool) {
+	if len(request) != 3 {
+		return nil, false
+	}
+
+	rpc, ok := request[0].(*tds.RPCPackage)
+	if !ok || (rpc.Name != "sp_regwatch" && rpc.Name != "sp_regnowatch") {
+		return nil, false
+	}
+
+	params, ok := request[2].(*tds.ParamsPackage)
+	if !ok || len(params.DataFields) == 0 {
+		return nil, false
+	}
+
+	name, ok := params.DataFields[0].Value().(string)
+	if !ok {
+		return nil, false
+	}
+
+	if rpc.Name == "sp_regwatch" {
+		c.server.lock.Lock()
+		c.server.registrations++
+		c.server.lock.Unlock()
+	}
+
+	c.watchesLock.Lock()
+	defer c.watchesLock.Unlock()
+
+	if rpc.Name == "sp_regwatch" {
+		c.watches[name] = channel
+	} else {
+		delete(c.watches, name)
+	}
+
+	return []tds.Package{
+		&tds.ReturnStatusPackage{ReturnValue: 0},
+		&tds.DonePackage{Status: tds.TDS_DONE_FINAL},
+	}, true
+}
+
+// sendEvent writes the packages of an event notification as a single
+// message to the client.
+func (c *serverConn) sendEvent(channel uint16, pkgs ...tds.Package) error {
+	packets, err := c.server.encode(nil, pkgs)
+	if err != nil {
+		return err
+	}
+
+	for i, packet := range packets {
+		packet.Header.MsgType = tds.TDS_BUF_RESPONSE
+		packet.Header.Status |= tds.TDS_BUFSTAT_EVENT
+		packet.Header.Channel = channel
+		packet.Header.PacketNr = uint8(i)
+		if i == len(packets)-1 {
+			packet.Header.Status |= tds.TDS_BUFSTAT_EOM
+		}
+	}
+
+	return c.write(packets...)
+}
@@ -0,0 +1,75 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tdstest
+
+import (
+	"fmt"
+
+	"github.com/SAP/go-dblib/asetypes"
+	"github.com/SAP/go-dblib/tds"
+)
+
+// Migrate requests all connected clients to migrate to the servers at
+// the passed addresses in the form host:port.
+//
+// The server continues the migration once a client is ready to
+// migrate. Migrate returns the number of clients the request was sent
+// to.
+func (server *Server) Migrate(addresses ...string) (int, error) {
+	fmts := make([]tds.FieldFmt, len(addresses))
+	data := make([]tds.FieldData, len(addresses))
+	for i, address := range addresses {
+		var err error
+		fmts[i], data[i], err = tds.LookupFieldFmtData(asetypes.VARCHAR)
+		if err != nil {
+			return 0, fmt.Errorf("tdstest: error looking up field for %s: %w", asetypes.VARCHAR, err)
+		}
+		data[i].SetValue(address)
+	}
+
+	pkgs := []tds.Package{
+		tds.NewMsgPackage(tds.TDS_MSG_HASARGS, tds.TDS_MSG_MIG_REQ),
+		tds.NewParamFmtPackage(false, fmts...),
+		tds.NewParamsPackage(data...),
+	}
+
+	server.lock.Lock()
+	conns := make([]*serverConn, 0, len(server.conns))
+	for conn := range server.conns {
+		conns = append(conns, conn)
+	}
+	server.lock.Unlock()
+
+	requested := 0
+	for _, conn := range conns {
+		if err := conn.send(0, pkgs...); err != nil {
+			return requested, fmt.Errorf("tdstest: error sending migration request: %w", err)
+		}
+		requested++
+	}
+
+	return requested, nil
+}
+
+// handleMigration answers the messages of a client taking part in
+// a migration.
+func (c *serverConn) handleMigration(channel uint16, request []tds.Package) error {
+	for _, pkg := range request {
+		msg, ok := pkg.(*tds.MsgPackage)
+		if !ok {
+			continue
+		}
+
+		switch msg.MsgId {
+		case tds.TDS_MSG_MIG_SYNC:
+			return c.send(channel, tds.NewMsgPackage(tds.TDS_MSG_HASNOARGS, tds.TDS_MSG_MIG_CONT))
+		case tds.TDS_MSG_MIG_FAIL:
+			// The client stays on this server.
+			return nil
+		}
+	}
+
+	return fmt.Errorf("received unexpected migration message: %v", request)
+}
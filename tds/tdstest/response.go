@@ -0,0 +1,87 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tdstest
+
+import (
+	"fmt"
+
+	"github.com/SAP/go-dblib/asetypes"
+	"github.com/SAP/go-dblib/tds"
+)
+
+// Column describes a column of a result set.
+type Column struct {
+	Name     string
+	DataType asetypes.DataType
+	// Nullable marks the column as allowing NULL.
+	Nullable bool
+}
+
+// columnFmts returns the formats of columns.
+func columnFmts(columns []Column) ([]tds.FieldFmt, error) {
+	fmts := make([]tds.FieldFmt, len(columns))
+	for i, column := range columns {
+		fieldFmt, err := tds.LookupFieldFmt(column.DataType)
+		if err != nil {
+			return nil, fmt.Errorf("tdstest: error looking up format for column %q: %w", column.Name, err)
+		}
+		fieldFmt.SetName(column.Name)
+		if column.Nullable {
+			fieldFmt.SetStatus(uint(tds.TDS_ROW_NULLALLOWED))
+		}
+		fmts[i] = fieldFmt
+	}
+	return fmts, nil
+}
+
+// Result returns the packages of a result set with the passed columns
+// and rows, terminated by a DonePackage with the row count.
+//
+// The values of a row must be in the order of the columns and of the Go
+// type corresponding to the data type of their column.
+func Result(columns []Column, rows ...[]interface{}) ([]tds.Package, error) {
+	fmts, err := columnFmts(columns)
+	if err != nil {
+		return nil, err
+	}
+
+	pkgs := []tds.Package{tds.NewRowFmtPackage(true, fmts...)}
+
+	for i, row := range rows {
+		if len(row) != len(columns) {
+			return nil, fmt.Errorf("tdstest: row %d has %d values, expected %d", i, len(row), len(columns))
+		}
+
+		data := make([]tds.FieldData, len(row))
+		for j, value := range row {
+			fieldData, err := tds.LookupFieldData(fmts[j])
+			if err != nil {
+				return nil, fmt.Errorf("tdstest: error looking up data for column %q: %w", columns[j].Name, err)
+			}
+			fieldData.SetValue(value)
+			data[j] = fieldData
+		}
+
+		pkgs = append(pkgs, tds.NewRowPackage(data...))
+	}
+
+	pkgs = append(pkgs, &tds.DonePackage{Status: tds.TDS_DONE_COUNT, Count: int32(len(rows))})
+	return pkgs, nil
+}
+
+// ErrorResponse returns the packages of an error response with the
+// passed message number and message.
+func ErrorResponse(msgNumber uint32, msg string) []tds.Package {
+	return []tds.Package{
+		&tds.EEDPackage{
+			MsgNumber: msgNumber,
+			State:     1,
+			Class:     16,
+			Status:    tds.TDS_NO_EED,
+			Msg:       msg,
+		},
+		&tds.DonePackage{Status: tds.TDS_DONE_ERROR},
+	}
+}
@@ -0,0 +1,543 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tdstest
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/SAP/go-dblib/asetypes"
+	"github.com/SAP/go-dblib/tds"
+)
+
+// HandlerFunc is called with the packages of a request which has no
+// scripted response.
+//
+// If ok is true the returned packages are sent as the response,
+// otherwise the next HandlerFunc is consulted.
+type HandlerFunc func(request []tds.Package) (response []tds.Package, ok bool)
+
+// AuthenticationFunc is called with the security tokens of logins
+// authenticated by a tds.Authenticator.
+//
+// If done is false reply is sent to the client to continue the
+// negotiation, otherwise the login is accepted and reply is sent with
+// the acceptance if not nil. If err is not nil the login is rejected.
+type AuthenticationFunc func(username string, token []byte) (reply []byte, done bool, err error)
+
+// Server is a TDS server listening on the loopback interface which
+// responds to requests with scripted responses.
+type Server struct {
+	username, password string
+
+	listener   net.Listener
+	privateKey *rsa.PrivateKey
+
+	ctx       context.Context
+	ctxCancel context.CancelFunc
+	wg        *sync.WaitGroup
+
+	lock              *sync.Mutex
+	conns             map[*serverConn]struct{}
+	languageResponses map[string][]tds.Package
+	dynamicResponses  map[string][]tds.Package
+	rpcResponses      map[string][]tds.Package
+	bulkTables        map[string]*bulkTable
+	cursorResults     map[string]*cursorResult
+	handlers          []HandlerFunc
+	authentication    AuthenticationFunc
+	loginEncryption   tds.TDSMsgId
+	passwordExpired   bool
+	version           string
+	attentions        int
+	registrations     int
+
+	haSessionID  []byte
+	haAlternates []string
+}
+
+// bulkTable is a table registered with HandleBulk.
+type bulkTable struct {
+	columns []Column
+	rows    [][]interface{}
+}
+
+// NewServer returns a started Server accepting logins with the passed
+// credentials.
+//
+// If username is empty any credentials are accepted.
+func NewServer(username, password string) (*Server, error) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, fmt.Errorf("tdstest: error generating login key: %w", err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("tdstest: error opening listener: %w", err)
+	}
+
+	server := &Server{
+		username:          username,
+		password:          password,
+		listener:          listener,
+		privateKey:        privateKey,
+		wg:                &sync.WaitGroup{},
+		lock:              &sync.Mutex{},
+		conns:             map[*serverConn]struct{}{},
+		languageResponses: map[string][]tds.Package{},
+		dynamicResponses:  map[string][]tds.Package{},
+		rpcResponses:      map[string][]tds.Package{},
+		bulkTables:        map[string]*bulkTable{},
+		cursorResults:     map[string]*cursorResult{},
+		loginEncryption:   tds.TDS_MSG_SEC_ENCRYPT4,
+		version:           version,
+	}
+	server.ctx, server.ctxCancel = context.WithCancel(context.Background())
+
+	server.wg.Add(1)
+	go server.serve()
+
+	return server, nil
+}
+
+// Addr returns the address the server is listening on.
+func (server *Server) Addr() net.Addr {
+	return server.listener.Addr()
+}
+
+// Info returns a tds.Info with defaults set to connect to the server.
+func (server *Server) Info() (*tds.Info, error) {
+	info := &tds.Info{}
+	if err := tds.SetInfo(info); err != nil {
+		return nil, fmt.Errorf("tdstest: error setting info defaults: %w", err)
+	}
+
+	host, port, err := net.SplitHostPort(server.Addr().String())
+	if err != nil {
+		return nil, fmt.Errorf("tdstest: error splitting listener address: %w", err)
+	}
+
+	info.Host = host
+	info.Port = port
+	info.Username = server.username
+	info.Password = server.password
+
+	return info, nil
+}
+
+// Connect opens a connection to the server and logs in on the main
+// channel.
+func (server *Server) Connect(ctx context.Context) (*tds.Conn, *tds.Channel, error) {
+	info, err := server.Info()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return server.ConnectInfo(ctx, info)
+}
+
+// ConnectInfo opens a connection with the passed info, e.g. the Info of
+// the server with modified options, and logs in on the main channel.
+func (server *Server) ConnectInfo(ctx context.Context, info *tds.Info) (*tds.Conn, *tds.Channel, error) {
+	conn, err := tds.NewConn(ctx, info)
+	if err != nil {
+		return nil, nil, fmt.Errorf("tdstest: error opening connection: %w", err)
+	}
+
+	ch, err := conn.NewChannel()
+	if err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("tdstest: error opening channel: %w", err)
+	}
+
+	config, err := tds.NewLoginConfig(info)
+	if err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("tdstest: error creating login config: %w", err)
+	}
+
+	if err := ch.Login(ctx, config); err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("tdstest: error logging in: %w", err)
+	}
+
+	return conn, ch, nil
+}
+
+// Close stops the server and closes all client connections.
+func (server *Server) Close() error {
+	server.ctxCancel()
+	err := server.listener.Close()
+
+	server.lock.Lock()
+	for conn := range server.conns {
+		conn.conn.Close()
+	}
+	server.lock.Unlock()
+
+	server.wg.Wait()
+
+	if err != nil && !errors.Is(err, net.ErrClosed) {
+		return fmt.Errorf("tdstest: error closing listener: %w", err)
+	}
+	return nil
+}
+
+// HandleLanguage registers the packages sent in response to
+// a LanguagePackage with the passed command.
+//
+// If no packages are passed the command is not answered, which
+// emulates a long-running request until the client sends an attention.
+func (server *Server) HandleLanguage(cmd string, response ...tds.Package) {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	server.languageResponses[cmd] = response
+}
+
+// HandleDynamic registers the packages sent in response to executing
+// the passed statement as a dynamic SQL statement.
+//
+// Preparing and deallocating a registered statement is acknowledged
+// by the server, executing it either after preparing or immediately
+// responds with the passed packages.
+func (server *Server) HandleDynamic(stmt string, response ...tds.Package) {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	server.dynamicResponses[stmt] = response
+}
+
+// HandleRPC registers the packages sent in response to calling the
+// stored procedure name.
+func (server *Server) HandleRPC(name string, response ...tds.Package) {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	server.rpcResponses[name] = response
+}
+
+// HandleBulk registers a table with the passed columns as target for
+// bulk copies.
+//
+// The server responds to the query for the column formats of the table
+// issued by tds.BulkCopy, acknowledges `insert bulk` commands for the
+// table and stores the rows of the following bulk messages. table is
+// matched without the brackets tds.BulkCopy encloses its parts in.
+func (server *Server) HandleBulk(table string, columns ...Column) {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	server.bulkTables[table] = &bulkTable{columns: columns}
+}
+
+// BulkRows returns the values of the rows copied into table.
+func (server *Server) BulkRows(table string) [][]interface{} {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	bulk, ok := server.bulkTables[table]
+	if !ok {
+		return nil
+	}
+
+	rows := make([][]interface{}, len(bulk.rows))
+	copy(rows, bulk.rows)
+	return rows
+}
+
+// HandleCursor registers the result set of cursors declared for stmt.
+//
+// Each opened cursor operates on its own copy of the rows. Positioned
+// deletes remove the current row from the copy, positioned updates are
+// only acknowledged.
+func (server *Server) HandleCursor(stmt string, columns []Column, rows ...[]interface{}) {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	server.cursorResults[stmt] = &cursorResult{columns: columns, rows: rows}
+}
+
+// HandleFunc registers a function to respond to requests without
+// a scripted response.
+//
+// Functions are consulted in the order they were registered.
+func (server *Server) HandleFunc(fn HandlerFunc) {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	server.handlers = append(server.handlers, fn)
+}
+
+// OfferLoginEncryption sets the password encryption offered to clients,
+// one of TDS_MSG_SEC_ENCRYPT4 (default), TDS_MSG_SEC_ENCRYPT3 or
+// TDS_MSG_SEC_ENCRYPT2.
+func (server *Server) OfferLoginEncryption(msgId tds.TDSMsgId) {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	server.loginEncryption = msgId
+}
+
+// ExpirePassword expires the password of the server's login. Sessions
+// of the login only permit changing the password with sp_password.
+func (server *Server) ExpirePassword() {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	server.passwordExpired = true
+}
+
+// SetVersion sets the @@version returned by the server. If version is
+// empty querying @@version fails.
+func (server *Server) SetVersion(version string) {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	server.version = version
+}
+
+// HandleAuthentication registers a function to authenticate logins with
+// security tokens, which are rejected otherwise.
+func (server *Server) HandleAuthentication(fn AuthenticationFunc) {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	server.authentication = fn
+}
+
+// HandleHAFailover grants an HA session with the passed ID and
+// companion servers to clients logging in.
+//
+// Logins resuming an HA session are only accepted for the passed
+// session ID.
+func (server *Server) HandleHAFailover(sessionID []byte, alternates ...string) {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	server.haSessionID = sessionID
+	server.haAlternates = alternates
+}
+
+func (server *Server) serve() {
+	defer server.wg.Done()
+
+	for {
+		conn, err := server.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		c := newServerConn(server, conn)
+
+		server.lock.Lock()
+		server.conns[c] = struct{}{}
+		server.lock.Unlock()
+
+		server.wg.Add(1)
+		go func() {
+			defer server.wg.Done()
+
+			c.serve(server.ctx)
+
+			server.lock.Lock()
+			delete(server.conns, c)
+			server.lock.Unlock()
+		}()
+	}
+}
+
+// authenticate returns true if the passed credentials are accepted.
+func (server *Server) authenticate(username, password string) bool {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	if server.username == "" {
+		return true
+	}
+
+	return username == server.username && password == server.password
+}
+
+// authenticateToken passes a security token to the AuthenticationFunc
+// registered with HandleAuthentication.
+func (server *Server) authenticateToken(username string, token []byte) ([]byte, bool, error) {
+	server.lock.Lock()
+	fn := server.authentication
+	server.lock.Unlock()
+
+	if fn == nil {
+		return nil, false, errors.New("tdstest: no authentication handler registered")
+	}
+
+	return fn(username, token)
+}
+
+// isPasswordExpired returns true if the password was expired with
+// ExpirePassword.
+func (server *Server) isPasswordExpired() bool {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	return server.passwordExpired
+}
+
+// changePassword changes the expired password if oldPassword matches.
+func (server *Server) changePassword(oldPassword, newPassword string) bool {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	if oldPassword != server.password {
+		return false
+	}
+
+	server.password = newPassword
+	server.passwordExpired = false
+	return true
+}
+
+// getVersion returns the @@version set with SetVersion.
+func (server *Server) getVersion() (string, bool) {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	return server.version, server.version != ""
+}
+
+// Attentions returns the number of attentions the server received.
+func (server *Server) Attentions() int {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	return server.attentions
+}
+
+// addAttention counts an attention received by the server.
+func (server *Server) addAttention() {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	server.attentions++
+}
+
+// haSession returns the packages granting the HA session and whether
+// a login resuming the passed session is accepted.
+func (server *Server) haSession(login *tds.LoginConfig) ([]tds.Package, bool, error) {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	if login.HALogin&tds.TDS_HA_LOG_RESUME == tds.TDS_HA_LOG_RESUME &&
+		(server.haSessionID == nil || !bytes.Equal(login.HASessionID, server.haSessionID)) {
+		return nil, false, nil
+	}
+
+	if server.haSessionID == nil {
+		return nil, true, nil
+	}
+
+	values := []interface{}{server.haSessionID}
+	for _, alternate := range server.haAlternates {
+		values = append(values, alternate)
+	}
+
+	fmts := make([]tds.FieldFmt, len(values))
+	data := make([]tds.FieldData, len(values))
+	for i, value := range values {
+		dataType := asetypes.VARCHAR
+		if i == 0 {
+			dataType = asetypes.LONGBINARY
+		}
+
+		var err error
+		fmts[i], data[i], err = tds.LookupFieldFmtData(dataType)
+		if err != nil {
+			return nil, false, fmt.Errorf("error looking up field for %s: %w", dataType, err)
+		}
+		data[i].SetValue(value)
+	}
+
+	return []tds.Package{
+		tds.NewMsgPackage(tds.TDS_MSG_HASARGS, tds.TDS_MSG_HAFAILOVER),
+		tds.NewParamFmtPackage(false, fmts...),
+		tds.NewParamsPackage(data...),
+	}, true, nil
+}
+
+func (server *Server) languageResponse(cmd string) ([]tds.Package, bool) {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	response, ok := server.languageResponses[cmd]
+	return response, ok
+}
+
+func (server *Server) dynamicResponse(stmt string) ([]tds.Package, bool) {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	response, ok := server.dynamicResponses[stmt]
+	return response, ok
+}
+
+func (server *Server) rpcResponse(name string) ([]tds.Package, bool) {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	response, ok := server.rpcResponses[name]
+	return response, ok
+}
+
+func (server *Server) bulkColumns(table string) ([]Column, bool) {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	bulk, ok := server.bulkTables[table]
+	if !ok {
+		return nil, false
+	}
+	return bulk.columns, true
+}
+
+func (server *Server) addBulkRows(table string, rows [][]interface{}) bool {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	bulk, ok := server.bulkTables[table]
+	if !ok {
+		return false
+	}
+	bulk.rows = append(bulk.rows, rows...)
+	return true
+}
+
+func (server *Server) cursorResult(stmt string) (*cursorResult, bool) {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	result, ok := server.cursorResults[stmt]
+	return result, ok
+}
+
+func (server *Server) handle(request []tds.Package) ([]tds.Package, bool) {
+	server.lock.Lock()
+	handlers := make([]HandlerFunc, len(server.handlers))
+	copy(handlers, server.handlers)
+	server.lock.Unlock()
+
+	for _, fn := range handlers {
+		if response, ok := fn(request); ok {
+			return response, true
+		}
+	}
+
+	return nil, false
+}
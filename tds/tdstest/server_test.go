@@ -0,0 +1,289 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tdstest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/SAP/go-dblib/asetypes"
+	"github.com/SAP/go-dblib/tds"
+)
+
+func login(ctx context.Context, info *tds.Info, encrypt tds.TDSMsgId) (*tds.Conn, *tds.Channel, error) {
+	conn, err := tds.NewConn(ctx, info)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ch, err := conn.NewChannel()
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+
+	config, err := tds.NewLoginConfig(info)
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	config.Encrypt = encrypt
+
+	if err := ch.Login(ctx, config); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+
+	return conn, ch, nil
+}
+
+// rows reads the values of all rows until the final DonePackage.
+func rows(ctx context.Context, ch *tds.Channel) ([][]interface{}, error) {
+	values := [][]interface{}{}
+
+	_, err := ch.NextPackageUntil(ctx, true, func(pkg tds.Package) (bool, error) {
+		switch typed := pkg.(type) {
+		case *tds.RowPackage:
+			row := make([]interface{}, len(typed.DataFields))
+			for i, field := range typed.DataFields {
+				row[i] = field.Value()
+			}
+			values = append(values, row)
+		case *tds.DonePackage:
+			if typed.Status&tds.TDS_DONE_ERROR == tds.TDS_DONE_ERROR {
+				return false, errors.New("received done with error status")
+			}
+			return typed.Status == tds.TDS_DONE_FINAL, nil
+		}
+		return false, nil
+	})
+
+	return values, err
+}
+
+func TestServer_Login(t *testing.T) {
+	server, err := NewServer("user", "pass")
+	if err != nil {
+		t.Fatalf("error creating server: %v", err)
+	}
+	defer server.Close()
+
+	cases := map[string]struct {
+		password string
+		encrypt  tds.TDSMsgId
+		err      bool
+	}{
+		"encrypted": {
+			password: "pass",
+			encrypt:  tds.TDS_MSG_SEC_ENCRYPT4,
+		},
+		"plain": {
+			password: "pass",
+		},
+		"encrypted wrong password": {
+			password: "wrong",
+			encrypt:  tds.TDS_MSG_SEC_ENCRYPT4,
+			err:      true,
+		},
+		"plain wrong password": {
+			password: "wrong",
+			err:      true,
+		},
+	}
+
+	for title, cas := range cases {
+		t.Run(title, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			info, err := server.Info()
+			if err != nil {
+				t.Fatalf("error getting info: %v", err)
+			}
+			info.Password = cas.password
+
+			conn, _, err := login(ctx, info, cas.encrypt)
+			if err != nil {
+				if !cas.err {
+					t.Errorf("received unexpected error: %v", err)
+				}
+				return
+			}
+			defer conn.Close()
+
+			if cas.err {
+				t.Errorf("expected error, login succeeded")
+			}
+		})
+	}
+}
+
+func TestServer_Query(t *testing.T) {
+	server, err := NewServer("user", "pass")
+	if err != nil {
+		t.Fatalf("error creating server: %v", err)
+	}
+	defer server.Close()
+
+	columns := []Column{
+		{Name: "id", DataType: asetypes.INT4},
+		{Name: "name", DataType: asetypes.VARCHAR},
+	}
+	expected := [][]interface{}{
+		{int32(1), "a"},
+		{int32(2), "b"},
+	}
+
+	response, err := Result(columns, expected...)
+	if err != nil {
+		t.Fatalf("error creating result: %v", err)
+	}
+
+	server.HandleLanguage("select id, name from t", response...)
+	server.HandleDynamic("create proc p as select id, name from t", response...)
+
+	cases := map[string]struct {
+		request  func() []tds.Package
+		expected [][]interface{}
+		err      bool
+	}{
+		"language": {
+			request: func() []tds.Package {
+				return []tds.Package{&tds.LanguagePackage{Cmd: "select id, name from t"}}
+			},
+			expected: expected,
+		},
+		"dynamic prepare": {
+			request: func() []tds.Package {
+				pkg := tds.NewDynamicPackage(true)
+				pkg.Type = tds.TDS_DYN_PREPARE
+				pkg.ID = "p"
+				pkg.Stmt = "create proc p as select id, name from t"
+				return []tds.Package{pkg}
+			},
+			expected: [][]interface{}{},
+		},
+		"dynamic exec": {
+			request: func() []tds.Package {
+				pkg := tds.NewDynamicPackage(true)
+				pkg.Type = tds.TDS_DYN_EXEC
+				pkg.ID = "p"
+				return []tds.Package{pkg}
+			},
+			expected: expected,
+		},
+		"not scripted": {
+			request: func() []tds.Package {
+				return []tds.Package{&tds.LanguagePackage{Cmd: "select 1"}}
+			},
+			err: true,
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	info, err := server.Info()
+	if err != nil {
+		t.Fatalf("error getting info: %v", err)
+	}
+
+	conn, ch, err := login(ctx, info, tds.TDS_MSG_SEC_ENCRYPT4)
+	if err != nil {
+		t.Fatalf("error logging in: %v", err)
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			t.Errorf("error closing connection: %v", err)
+		}
+	}()
+
+	// The cases depend on each other and are run in order.
+	for _, title := range []string{"language", "dynamic prepare", "dynamic exec", "not scripted"} {
+		cas := cases[title]
+		t.Run(title, func(t *testing.T) {
+			for _, pkg := range cas.request() {
+				if err := ch.QueuePackage(ctx, pkg); err != nil {
+					t.Fatalf("error queueing package: %v", err)
+				}
+			}
+
+			if err := ch.SendRemainingPackets(ctx); err != nil {
+				t.Fatalf("error sending request: %v", err)
+			}
+
+			values, err := rows(ctx, ch)
+			if err != nil {
+				var eedError *tds.EEDError
+				if !cas.err || !errors.As(err, &eedError) {
+					t.Errorf("received unexpected error: %v", err)
+				}
+				return
+			}
+
+			if cas.err {
+				t.Errorf("expected error, received rows: %v", values)
+				return
+			}
+
+			if !reflect.DeepEqual(values, cas.expected) {
+				t.Errorf("received unexpected rows:\nexpected: %v\nreceived: %v", cas.expected, values)
+			}
+		})
+	}
+}
+
+func TestServer_LogicalChannel(t *testing.T) {
+	server, err := NewServer("user", "pass")
+	if err != nil {
+		t.Fatalf("error creating server: %v", err)
+	}
+	defer server.Close()
+
+	response, err := Result([]Column{{Name: "a", DataType: asetypes.INT4}}, []interface{}{int32(1)})
+	if err != nil {
+		t.Fatalf("error creating result: %v", err)
+	}
+	server.HandleLanguage("select 1 as a", response...)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	info, err := server.Info()
+	if err != nil {
+		t.Fatalf("error getting info: %v", err)
+	}
+
+	conn, _, err := login(ctx, info, tds.TDS_MSG_SEC_ENCRYPT4)
+	if err != nil {
+		t.Fatalf("error logging in: %v", err)
+	}
+	defer conn.Close()
+
+	ch, err := conn.NewChannel()
+	if err != nil {
+		t.Fatalf("error opening logical channel: %v", err)
+	}
+
+	if err := ch.SendPackage(ctx, &tds.LanguagePackage{Cmd: "select 1 as a"}); err != nil {
+		t.Fatalf("error sending request: %v", err)
+	}
+
+	values, err := rows(ctx, ch)
+	if err != nil {
+		t.Fatalf("error reading rows: %v", err)
+	}
+
+	if expected := [][]interface{}{{int32(1)}}; !reflect.DeepEqual(values, expected) {
+		t.Errorf("received unexpected rows:\nexpected: %v\nreceived: %v", expected, values)
+	}
+
+	if err := ch.Close(); err != nil {
+		t.Errorf("error closing logical channel: %v", err)
+	}
+}
@@ -0,0 +1,242 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tds
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// TLS modes of Info.TLSMode.
+const (
+	// TLSModeDisable connects without TLS.
+	TLSModeDisable = "disable"
+	// TLSModeRequire connects with TLS without validating the
+	// certificate of the server. Pinned fingerprints are still
+	// verified.
+	TLSModeRequire = "require"
+	// TLSModeVerifyFull connects with TLS and validates the certificate
+	// chain and hostname of the server.
+	TLSModeVerifyFull = "verify-full"
+)
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// tlsMode returns the TLS mode of info.
+//
+// If Info.TLSMode is not set the mode is derived from Info.TLSEnable
+// and Info.TLSSkipValidation.
+func (info *Info) tlsMode() (string, error) {
+	switch info.TLSMode {
+	case TLSModeDisable, TLSModeRequire, TLSModeVerifyFull:
+		return info.TLSMode, nil
+	case "":
+	default:
+		return "", fmt.Errorf("invalid tls-mode %q, expected one of %q, %q or %q",
+			info.TLSMode, TLSModeDisable, TLSModeRequire, TLSModeVerifyFull)
+	}
+
+	if !info.TLSEnable {
+		return TLSModeDisable, nil
+	}
+
+	if info.TLSSkipValidation {
+		return TLSModeRequire, nil
+	}
+
+	return TLSModeVerifyFull, nil
+}
+
+// tlsConfig returns the TLS configuration to connect to host. The
+// returned configuration is nil if TLS is disabled.
+func (info *Info) tlsConfig(host string) (*tls.Config, error) {
+	mode, err := info.tlsMode()
+	if err != nil {
+		return nil, err
+	}
+
+	if mode == TLSModeDisable {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{}
+	tlsConfig.ServerName = host
+	tlsConfig.InsecureSkipVerify = mode == TLSModeRequire
+
+	if info.TLSHostname != "" {
+		hostname := strings.TrimPrefix(info.TLSHostname, "CN=")
+
+		tlsConfig.ServerName = hostname
+	}
+
+	if info.TLSCAFile != "" || info.TLSSystemRoots {
+		if tlsConfig.RootCAs, err = info.tlsRootCAs(); err != nil {
+			return nil, err
+		}
+	}
+
+	if info.TLSCertFile != "" || info.TLSKeyFile != "" {
+		if info.TLSCertFile == "" || info.TLSKeyFile == "" {
+			return nil, errors.New("tls-cert-file and tls-key-file must be set together")
+		}
+
+		cert, err := tls.LoadX509KeyPair(info.TLSCertFile, info.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error loading client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if info.TLSMinVersion != "" {
+		version, ok := tlsVersions[info.TLSMinVersion]
+		if !ok {
+			return nil, fmt.Errorf("invalid tls-min-version %q", info.TLSMinVersion)
+		}
+		tlsConfig.MinVersion = version
+	}
+
+	if info.TLSMaxVersion != "" {
+		version, ok := tlsVersions[info.TLSMaxVersion]
+		if !ok {
+			return nil, fmt.Errorf("invalid tls-max-version %q", info.TLSMaxVersion)
+		}
+		tlsConfig.MaxVersion = version
+	}
+
+	if info.TLSCipherSuites != "" {
+		if tlsConfig.CipherSuites, err = parseCipherSuites(info.TLSCipherSuites); err != nil {
+			return nil, err
+		}
+	}
+
+	if info.TLSFingerprints != "" {
+		fingerprints, err := parseFingerprints(info.TLSFingerprints)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConfig.VerifyConnection = func(state tls.ConnectionState) error {
+			if len(state.PeerCertificates) == 0 {
+				return errors.New("server did not present a certificate")
+			}
+
+			fingerprint := sha256.Sum256(state.PeerCertificates[0].Raw)
+			for _, pinned := range fingerprints {
+				if bytes.Equal(fingerprint[:], pinned) {
+					return nil
+				}
+			}
+
+			return fmt.Errorf("server certificate fingerprint %s is not pinned",
+				hex.EncodeToString(fingerprint[:]))
+		}
+	}
+
+	return tlsConfig, nil
+}
+
+// tlsRootCAs returns the pool of the certificates in Info.TLSCAFile,
+// merged into the system root pool if Info.TLSSystemRoots is set.
+func (info *Info) tlsRootCAs() (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	if info.TLSSystemRoots {
+		var err error
+		if pool, err = x509.SystemCertPool(); err != nil {
+			return nil, fmt.Errorf("error loading system root pool: %w", err)
+		}
+	}
+
+	if info.TLSCAFile == "" {
+		return pool, nil
+	}
+
+	bs, err := os.ReadFile(info.TLSCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file at ssl-ca path '%s': %w",
+			info.TLSCAFile, err)
+	}
+
+	withCaCert := false
+
+	for {
+		var block *pem.Block
+		block, bs = pem.Decode(bs)
+		if block == nil {
+			break
+		}
+
+		caCert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing CA PEM at ssl-ca path '%s': %w",
+				info.TLSCAFile, err)
+		}
+
+		pool.AddCert(caCert)
+		withCaCert = true
+		if len(bs) == 0 {
+			break
+		}
+	}
+
+	if !withCaCert {
+		return nil, fmt.Errorf("could not parse any valid CA certificate from file '%s'", info.TLSCAFile)
+	}
+
+	return pool, nil
+}
+
+// parseCipherSuites returns the IDs of the comma-separated cipher suite
+// names in s.
+func parseCipherSuites(s string) ([]uint16, error) {
+	suites := map[string]uint16{}
+	for _, suite := range tls.CipherSuites() {
+		suites[suite.Name] = suite.ID
+	}
+	for _, suite := range tls.InsecureCipherSuites() {
+		suites[suite.Name] = suite.ID
+	}
+
+	ids := []uint16{}
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		id, ok := suites[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown cipher suite %q", name)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// parseFingerprints returns the comma-separated SHA-256 fingerprints in
+// s. The bytes of a fingerprint may be separated by colons.
+func parseFingerprints(s string) ([][]byte, error) {
+	fingerprints := [][]byte{}
+	for _, fingerprint := range strings.Split(s, ",") {
+		fingerprint = strings.ReplaceAll(strings.TrimSpace(fingerprint), ":", "")
+
+		bs, err := hex.DecodeString(fingerprint)
+		if err != nil || len(bs) != sha256.Size {
+			return nil, fmt.Errorf("invalid SHA-256 fingerprint %q", fingerprint)
+		}
+		fingerprints = append(fingerprints, bs)
+	}
+
+	return fingerprints, nil
+}
@@ -0,0 +1,251 @@
+// SPDX-FileCopyrightText: 2020 - 2025 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tds_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SAP/go-dblib/tds"
+	"github.com/SAP/go-dblib/tds/tdstest"
+)
+
+// testCert is a certificate and key signed by a test CA.
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	// certFile and keyFile are the paths of the PEM encoded
+	// certificate and key.
+	certFile, keyFile string
+}
+
+func newTestCert(t *testing.T, name string, template *x509.Certificate, parent *testCert) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("error generating key: %v", err)
+	}
+
+	template.Subject = pkix.Name{CommonName: name}
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+
+	parentCert, parentKey := template, key
+	if parent != nil {
+		parentCert, parentKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parentCert, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatalf("error creating certificate: %v", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("error parsing certificate: %v", err)
+	}
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("error marshalling key: %v", err)
+	}
+
+	dir := t.TempDir()
+	tc := &testCert{
+		cert:     cert,
+		key:      key,
+		certFile: filepath.Join(dir, name+".crt"),
+		keyFile:  filepath.Join(dir, name+".key"),
+	}
+
+	if err := os.WriteFile(tc.certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("error writing certificate: %v", err)
+	}
+	if err := os.WriteFile(tc.keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600); err != nil {
+		t.Fatalf("error writing key: %v", err)
+	}
+
+	return tc
+}
+
+func TestNewConn_TLS(t *testing.T) {
+	server, err := tdstest.NewServer("user", "pass")
+	if err != nil {
+		t.Fatalf("error creating server: %v", err)
+	}
+	defer server.Close()
+
+	ca := newTestCert(t, "ca", &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil)
+
+	serverCert := newTestCert(t, "server", &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, ca)
+
+	clientCert := newTestCert(t, "client", &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{serverCert.cert.Raw}, PrivateKey: serverCert.key}},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+	})
+	if err != nil {
+		t.Fatalf("error opening TLS listener: %v", err)
+	}
+	proxy(t, listener, server.Addr().String())
+
+	host, port, err := net.SplitHostPort(listener.Addr().String())
+	if err != nil {
+		t.Fatalf("error splitting listener address: %v", err)
+	}
+
+	fingerprint := sha256.Sum256(serverCert.cert.Raw)
+	mtls := func(info *tds.Info) {
+		info.TLSCertFile = clientCert.certFile
+		info.TLSKeyFile = clientCert.keyFile
+	}
+
+	cases := map[string]struct {
+		setup func(*tds.Info)
+		fails bool
+	}{
+		"verify-full": {
+			setup: func(info *tds.Info) {
+				mtls(info)
+				info.TLSMode = tds.TLSModeVerifyFull
+				info.TLSCAFile = ca.certFile
+			},
+		},
+		"verify-full with system roots": {
+			setup: func(info *tds.Info) {
+				mtls(info)
+				info.TLSMode = tds.TLSModeVerifyFull
+				info.TLSCAFile = ca.certFile
+				info.TLSSystemRoots = true
+			},
+		},
+		"verify-full with unknown CA": {
+			setup: func(info *tds.Info) {
+				mtls(info)
+				info.TLSMode = tds.TLSModeVerifyFull
+			},
+			fails: true,
+		},
+		"without client certificate": {
+			setup: func(info *tds.Info) {
+				info.TLSMode = tds.TLSModeVerifyFull
+				info.TLSCAFile = ca.certFile
+			},
+			fails: true,
+		},
+		"require": {
+			setup: func(info *tds.Info) {
+				mtls(info)
+				info.TLSMode = tds.TLSModeRequire
+			},
+		},
+		"pinned fingerprint": {
+			setup: func(info *tds.Info) {
+				mtls(info)
+				info.TLSMode = tds.TLSModeRequire
+				info.TLSFingerprints = hex.EncodeToString(fingerprint[:])
+			},
+		},
+		"other fingerprint": {
+			setup: func(info *tds.Info) {
+				mtls(info)
+				info.TLSMode = tds.TLSModeRequire
+				info.TLSFingerprints = hex.EncodeToString(make([]byte, sha256.Size))
+			},
+			fails: true,
+		},
+		"tls versions": {
+			setup: func(info *tds.Info) {
+				mtls(info)
+				info.TLSMode = tds.TLSModeRequire
+				info.TLSMinVersion = "1.2"
+				info.TLSMaxVersion = "1.2"
+				info.TLSCipherSuites = "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"
+			},
+		},
+		"disable": {
+			setup: func(info *tds.Info) {
+				info.TLSMode = tds.TLSModeDisable
+			},
+			fails: true,
+		},
+	}
+
+	for title, cas := range cases {
+		t.Run(title, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			info, err := server.Info()
+			if err != nil {
+				t.Fatalf("error getting info: %v", err)
+			}
+			info.Host = host
+			info.Port = port
+			info.PacketReadTimeout = time.Second
+			cas.setup(info)
+
+			// With TLS 1.3 a rejected client certificate is only
+			// reported after the handshake, hence the login must
+			// be attempted as well.
+			err = func() error {
+				conn, err := tds.NewConn(ctx, info)
+				if err != nil {
+					return err
+				}
+				defer conn.Close()
+
+				ch, err := conn.NewChannel()
+				if err != nil {
+					return err
+				}
+
+				config, err := tds.NewLoginConfig(info)
+				if err != nil {
+					return err
+				}
+
+				return ch.Login(ctx, config)
+			}()
+
+			if cas.fails && err == nil {
+				t.Fatalf("expected connection to fail")
+			}
+			if !cas.fails && err != nil {
+				t.Fatalf("error connecting: %v", err)
+			}
+		})
+	}
+}